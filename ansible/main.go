@@ -0,0 +1,361 @@
+package ansible
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Inventory is the hosts of an ansible inventory with their groups.
+type Inventory struct {
+	Hosts  map[string]*Host
+	groups map[string]*group
+}
+
+type Host struct {
+	Name string
+	Vars map[string]string
+}
+
+type group struct {
+	hosts    []string
+	children []string
+}
+
+func newInventory() *Inventory {
+	return &Inventory{
+		Hosts:  map[string]*Host{},
+		groups: map[string]*group{},
+	}
+}
+
+// Profiles generates an ssh profile per host of the inventories. Executable
+// inventories are run as dynamic inventory scripts.
+func Profiles(inventories []string) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, path := range inventories {
+		inventory, err := Load(path)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"path": path,
+				"err":  err,
+			}).Warn("Cannot load ansible inventory")
+			continue
+		}
+
+		ret = append(ret, inventory.Profiles()...)
+	}
+
+	return ret
+}
+
+// Load parses a static INI or YAML inventory, or runs a dynamic inventory
+// script with --list.
+func Load(path string) (*Inventory, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Mode()&0111 != 0 {
+		out, err := exec.Command(path, "--list").Output()
+		if err != nil {
+			return nil, errors.Wrap(err, "dynamic inventory failed")
+		}
+
+		return ParseJSON(out)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(path, ".yml") || strings.HasSuffix(path, ".yaml") {
+		return ParseYAML(data)
+	}
+
+	return ParseINI(data)
+}
+
+var hostRange = regexp.MustCompile(`^(.*)\[(\d+):(\d+)\](.*)$`)
+
+// expand expands the numeric host ranges, ie web[01:03].
+func expand(pattern string) []string {
+	match := hostRange.FindStringSubmatch(pattern)
+	if match == nil {
+		return []string{pattern}
+	}
+
+	start, _ := strconv.Atoi(match[2])
+	end, _ := strconv.Atoi(match[3])
+	format := fmt.Sprintf("%%s%%0%dd%%s", len(match[2]))
+
+	var ret []string
+	for i := start; i <= end; i++ {
+		ret = append(ret, fmt.Sprintf(format, match[1], i, match[4]))
+	}
+
+	return ret
+}
+
+func (i *Inventory) addHost(name, groupName string, vars map[string]string) {
+	for _, host := range expand(name) {
+		if _, found := i.Hosts[host]; !found {
+			i.Hosts[host] = &Host{Name: host, Vars: map[string]string{}}
+		}
+
+		for k, v := range vars {
+			i.Hosts[host].Vars[k] = v
+		}
+
+		i.group(groupName).hosts = append(i.group(groupName).hosts, host)
+	}
+}
+
+func (i *Inventory) group(name string) *group {
+	if _, found := i.groups[name]; !found {
+		i.groups[name] = &group{}
+	}
+
+	return i.groups[name]
+}
+
+// ParseINI parses an INI inventory. Group variables are ignored.
+func ParseINI(data []byte) (*Inventory, error) {
+	inventory := newInventory()
+	section := "ungrouped"
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+
+		fields := strings.Fields(line)
+
+		switch {
+		case strings.HasSuffix(section, ":vars"):
+			continue
+		case strings.HasSuffix(section, ":children"):
+			parent := strings.TrimSuffix(section, ":children")
+			inventory.group(parent).children = append(inventory.group(parent).children, fields[0])
+			inventory.group(fields[0])
+		default:
+			vars := map[string]string{}
+			for _, field := range fields[1:] {
+				parts := strings.SplitN(field, "=", 2)
+				if len(parts) == 2 {
+					vars[parts[0]] = strings.Trim(parts[1], `"'`)
+				}
+			}
+
+			inventory.addHost(fields[0], section, vars)
+		}
+	}
+
+	return inventory, scanner.Err()
+}
+
+type yamlGroup struct {
+	Hosts    map[string]map[string]interface{} `yaml:"hosts"`
+	Children map[string]yamlGroup              `yaml:"children"`
+}
+
+// ParseYAML parses a YAML inventory.
+func ParseYAML(data []byte) (*Inventory, error) {
+	var groups map[string]yamlGroup
+
+	err := yaml.Unmarshal(data, &groups)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse yaml inventory")
+	}
+
+	inventory := newInventory()
+	for name, g := range groups {
+		inventory.addYAMLGroup(name, g)
+	}
+
+	return inventory, nil
+}
+
+func (i *Inventory) addYAMLGroup(name string, g yamlGroup) {
+	i.group(name)
+
+	for host, vars := range g.Hosts {
+		i.addHost(host, name, stringVars(vars))
+	}
+
+	for child, c := range g.Children {
+		i.group(name).children = append(i.group(name).children, child)
+		i.addYAMLGroup(child, c)
+	}
+}
+
+// ParseJSON parses the output of a dynamic inventory script.
+func ParseJSON(data []byte) (*Inventory, error) {
+	var raw map[string]json.RawMessage
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse dynamic inventory")
+	}
+
+	var meta struct {
+		HostVars map[string]map[string]interface{} `json:"hostvars"`
+	}
+	if m, found := raw["_meta"]; found {
+		if err := json.Unmarshal(m, &meta); err != nil {
+			return nil, errors.Wrap(err, "cannot parse the _meta of the dynamic inventory")
+		}
+	}
+
+	inventory := newInventory()
+	for name, value := range raw {
+		if name == "_meta" {
+			continue
+		}
+
+		var g struct {
+			Hosts    []string `json:"hosts"`
+			Children []string `json:"children"`
+		}
+
+		// groups can also be a plain list of hosts.
+		if err := json.Unmarshal(value, &g.Hosts); err != nil {
+			if err := json.Unmarshal(value, &g); err != nil {
+				return nil, errors.Wrapf(err, "cannot parse group %s", name)
+			}
+		}
+
+		inventory.group(name).children = append(inventory.group(name).children, g.Children...)
+		for _, host := range g.Hosts {
+			inventory.addHost(host, name, stringVars(meta.HostVars[host]))
+		}
+	}
+
+	return inventory, nil
+}
+
+func stringVars(vars map[string]interface{}) map[string]string {
+	var ret = map[string]string{}
+
+	for k, v := range vars {
+		ret[k] = fmt.Sprintf("%v", v)
+	}
+
+	return ret
+}
+
+// Groups returns the sorted groups of the host, including the parents of its
+// groups, without the implicit all and ungrouped.
+func (i *Inventory) Groups(host string) []string {
+	var ret []string
+
+	for name := range i.groups {
+		if name == "all" || name == "ungrouped" {
+			continue
+		}
+
+		if i.contains(name, host, map[string]bool{}) {
+			ret = append(ret, name)
+		}
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+func (i *Inventory) contains(name, host string, seen map[string]bool) bool {
+	if seen[name] {
+		return false
+	}
+	seen[name] = true
+
+	g, found := i.groups[name]
+	if !found {
+		return false
+	}
+
+	for _, h := range g.hosts {
+		if h == host {
+			return true
+		}
+	}
+
+	for _, child := range g.children {
+		if i.contains(child, host, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Profiles returns an ssh profile per host, tagged with its groups.
+func (i *Inventory) Profiles() []iterm.Profile {
+	var ret []iterm.Profile
+
+	var hosts []string
+	for name := range i.Hosts {
+		hosts = append(hosts, name)
+	}
+	sort.Strings(hosts)
+
+	for _, name := range hosts {
+		tags := append([]string{"ansible"}, i.Groups(name)...)
+
+		ret = append(ret, *iterm.NewProfile(fmt.Sprintf("ansible-%s", name), map[string]string{
+			"Command": i.Hosts[name].SSHCommand(),
+			"Tags":    strings.Join(tags, ","),
+		}))
+	}
+
+	return ret
+}
+
+// SSHCommand returns the ssh command of the host, honouring the ansible_host,
+// ansible_user and ansible_port variables.
+func (h *Host) SSHCommand() string {
+	target := h.Name
+	if host, found := h.Vars["ansible_host"]; found {
+		target = host
+	}
+
+	if user, found := h.Vars["ansible_user"]; found {
+		target = fmt.Sprintf("%s@%s", user, target)
+	}
+
+	if port, found := h.Vars["ansible_port"]; found {
+		return fmt.Sprintf("ssh -p %s %s", port, target)
+	}
+
+	return fmt.Sprintf("ssh %s", target)
+}
@@ -0,0 +1,102 @@
+package ansible
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseINI(t *testing.T) {
+	inventory, err := ParseINI([]byte(heredoc.Doc(`
+		bastion ansible_host=1.2.3.4 ansible_user=admin
+
+		[web]
+		web[01:02].example.com
+
+		[db]
+		db.example.com ansible_port=2222
+
+		[prod:children]
+		web
+		db
+
+		[prod:vars]
+		env=prod
+	`)))
+	assert.Nil(t, err)
+
+	assert.Equal(t, 4, len(inventory.Hosts))
+	assert.Equal(t, []string{"prod", "web"}, inventory.Groups("web02.example.com"))
+	assert.Equal(t, []string{"db", "prod"}, inventory.Groups("db.example.com"))
+	assert.Nil(t, inventory.Groups("bastion"))
+
+	assert.Equal(t, "ssh admin@1.2.3.4", inventory.Hosts["bastion"].SSHCommand())
+	assert.Equal(t, "ssh -p 2222 db.example.com", inventory.Hosts["db.example.com"].SSHCommand())
+}
+
+func TestParseYAML(t *testing.T) {
+	inventory, err := ParseYAML([]byte(heredoc.Doc(`
+		all:
+		  hosts:
+		    bastion:
+		      ansible_host: 1.2.3.4
+		  children:
+		    prod:
+		      children:
+		        web:
+		          hosts:
+		            web01:
+		              ansible_port: 2222
+	`)))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"prod", "web"}, inventory.Groups("web01"))
+	assert.Equal(t, "ssh -p 2222 web01", inventory.Hosts["web01"].SSHCommand())
+	assert.Equal(t, "ssh 1.2.3.4", inventory.Hosts["bastion"].SSHCommand())
+}
+
+func TestParseJSON(t *testing.T) {
+	inventory, err := ParseJSON([]byte(heredoc.Doc(`
+		{
+		  "web": {"hosts": ["web01"], "vars": {"env": "prod"}},
+		  "db": ["db01"],
+		  "prod": {"children": ["web", "db"]},
+		  "_meta": {"hostvars": {"web01": {"ansible_host": "10.0.0.1"}}}
+		}
+	`)))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"prod", "web"}, inventory.Groups("web01"))
+	assert.Equal(t, []string{"db", "prod"}, inventory.Groups("db01"))
+	assert.Equal(t, "ssh 10.0.0.1", inventory.Hosts["web01"].SSHCommand())
+}
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ansible")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(path, []byte("[web]\nweb01\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := filepath.Join(dir, "dynamic.sh")
+	if err := ioutil.WriteFile(script, []byte("#!/bin/sh\necho '{\"db\": [\"db01\"]}'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := Profiles([]string{path, script, filepath.Join(dir, "missing")})
+
+	assert.Equal(t, 2, len(profiles))
+	assert.Equal(t, "ansible-web01", profiles[0].GUID)
+	assert.Equal(t, "ssh web01", profiles[0].Command)
+	assert.Equal(t, []string{"ansible", "web"}, profiles[0].Tags)
+	assert.Equal(t, "ansible-db01", profiles[1].GUID)
+}
@@ -0,0 +1,86 @@
+package auditlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Event is a line of the audit log.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Profile string    `json:"profile"`
+	// Event is either start or stop.
+	Event    string        `json:"event"`
+	Command  []string      `json:"command,omitempty"`
+	ExitCode *int          `json:"exit_code,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Log appends the event to the JSONL audit file.
+func Log(path string, event Event) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return errors.Wrap(err, "cannot create audit directory")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "cannot open audit file")
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(event)
+}
+
+// Read returns the events of the audit file, skipping invalid lines.
+func Read(path string) ([]Event, error) {
+	var ret []Event
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ret, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		ret = append(ret, event)
+	}
+
+	return ret, scanner.Err()
+}
+
+// LastUsed returns when each profile was last started.
+func LastUsed(path string) (map[string]time.Time, error) {
+	var ret = map[string]time.Time{}
+
+	events, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		if event.Event != "start" {
+			continue
+		}
+
+		if event.Time.After(ret[event.Profile]) {
+			ret[event.Profile] = event.Time
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,49 @@
+package auditlog
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastUsed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state", "audit.jsonl")
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+	code := 1
+
+	events := []Event{
+		{Time: now.Add(-time.Hour), Profile: "prod", Event: "start", Command: []string{"aws", "s3", "ls"}},
+		{Time: now.Add(-time.Minute), Profile: "prod", Event: "stop", ExitCode: &code},
+		{Time: now, Profile: "dev", Event: "start"},
+		{Time: now.Add(-2 * time.Hour), Profile: "dev", Event: "start"},
+	}
+
+	for _, event := range events {
+		assert.Nil(t, Log(path, event))
+	}
+
+	read, err := Read(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, *read[1].ExitCode)
+
+	used, err := LastUsed(path)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]time.Time{
+		"prod": now.Add(-time.Hour),
+		"dev":  now,
+	}, used)
+
+	used, err = LastUsed(filepath.Join(dir, "missing"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(used))
+}
@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/metrics"
+	"github.com/zieckey/goini"
+)
+
+// Accounts returns the account ID of the profiles of the config and
+// credentials files, from their sso_account_id or role_arn. The profiles
+// without either are left out.
+func Accounts(paths ...string) map[string]string {
+	var ret = map[string]string{}
+
+	for _, path := range paths {
+		ini := goini.New()
+		err := ini.ParseFile(path)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"path": path,
+				"err":  err,
+			}).Debug("Cannot parse file")
+			continue
+		}
+
+		for name, section := range ini.GetAll() {
+			if id := sectionAccount(section); id != "" {
+				ret[strings.TrimPrefix(name, "profile ")] = id
+			}
+		}
+	}
+
+	return ret
+}
+
+func sectionAccount(section map[string]string) string {
+	if id, found := section["sso_account_id"]; found {
+		return id
+	}
+
+	if arn, found := section["role_arn"]; found {
+		if parts := strings.Split(arn, ":"); len(parts) > 4 {
+			return parts[4]
+		}
+	}
+
+	return ""
+}
+
+type flight struct {
+	done chan struct{}
+	out  []byte
+	err  error
+}
+
+// Dedupe shares the output of the aws cli calls of the profiles into the
+// same account, so that many role profiles into one account describe its
+// resources once per generation. Calls of the same account that are in
+// flight wait for the first one. Only the successful outputs are shared, a
+// failed call, ie of a role without access, is retried by the waiting calls
+// with their own profile. The calls of profiles with an unknown account are
+// not deduped.
+func Dedupe(accounts map[string]string) {
+	next := runAWS
+
+	var lock sync.Mutex
+	var flights = map[string]*flight{}
+
+	runAWS = func(args ...string) ([]byte, error) {
+		key, found := accountKey(args, accounts)
+		if !found {
+			return next(args...)
+		}
+
+		lock.Lock()
+		f, found := flights[key]
+		if !found {
+			f = &flight{done: make(chan struct{})}
+			flights[key] = f
+		}
+		lock.Unlock()
+
+		metrics.CacheHit("aws-accounts", found)
+
+		if found {
+			<-f.done
+			if f.err != nil {
+				return next(args...)
+			}
+
+			return f.out, nil
+		}
+
+		f.out, f.err = next(args...)
+		if f.err != nil {
+			lock.Lock()
+			delete(flights, key)
+			lock.Unlock()
+		}
+		close(f.done)
+
+		return f.out, f.err
+	}
+}
+
+// accountKey returns the arguments of the call with the profile replaced by
+// its account ID.
+func accountKey(args []string, accounts map[string]string) (string, bool) {
+	var key []string
+	var found bool
+
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--profile" && i+1 < len(args) {
+			id, ok := accounts[args[i+1]]
+			if !ok {
+				return "", false
+			}
+
+			key = append(key, fmt.Sprintf("--account=%s", id))
+			found = true
+			i++
+			continue
+		}
+
+		key = append(key, args[i])
+	}
+
+	return strings.Join(key, " "), found
+}
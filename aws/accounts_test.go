@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccounts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "accounts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(heredoc.Doc(`
+		[profile sso]
+		sso_account_id = 111111111111
+
+		[profile admin]
+		role_arn = arn:aws:iam::222222222222:role/Admin
+		source_profile = sso
+
+		[profile keys]
+		region = eu-west-1
+	`)), 0644))
+
+	assert.Equal(t, map[string]string{"sso": "111111111111", "admin": "222222222222"}, Accounts(path, filepath.Join(dir, "missing")))
+}
+
+func TestDedupe(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	var lock sync.Mutex
+	var calls [][]string
+	runAWS = func(args ...string) ([]byte, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		calls = append(calls, args)
+		return []byte(`[]`), nil
+	}
+
+	Dedupe(map[string]string{"admin": "222222222222", "readonly": "222222222222", "other": "333333333333"})
+
+	var wg sync.WaitGroup
+	for _, profile := range []string{"admin", "readonly", "admin", "other", "unknown", "unknown"} {
+		wg.Add(1)
+		go func(profile string) {
+			defer wg.Done()
+			_, err := InstanceStates(profile, "eu-west-1", []string{"i-0123"})
+			assert.Nil(t, err)
+		}(profile)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 4, len(calls), "one call per account and per unknown profile")
+
+	_, err := InstanceStates("admin", "us-east-1", []string{"i-0123"})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, len(calls), "other regions are separate calls")
+}
+
+func TestAccountKey(t *testing.T) {
+	accounts := map[string]string{"admin": "222222222222"}
+
+	key, found := accountKey([]string{"s3api", "list-buckets", "--profile", "admin"}, accounts)
+	assert.True(t, found)
+	assert.Equal(t, "s3api list-buckets --account=222222222222", key)
+
+	_, found = accountKey([]string{"s3api", "list-buckets", "--profile", "dev"}, accounts)
+	assert.False(t, found)
+
+	_, found = accountKey([]string{"s3api", "list-buckets"}, accounts)
+	assert.False(t, found)
+}
+
+func TestDedupeError(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	var lock sync.Mutex
+	var profiles []string
+	runAWS = func(args ...string) ([]byte, error) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		profile := args[len(args)-3]
+		profiles = append(profiles, profile)
+		if profile == "denied" {
+			return nil, errors.New("AccessDenied")
+		}
+
+		return []byte(`[]`), nil
+	}
+
+	Dedupe(map[string]string{"denied": "222222222222", "admin": "222222222222"})
+
+	_, err := InstanceStates("denied", "eu-west-1", []string{"i-0123"})
+	assert.NotNil(t, err)
+
+	_, err = InstanceStates("admin", "eu-west-1", []string{"i-0123"})
+	assert.Nil(t, err, "the error of another profile is not shared")
+
+	_, err = InstanceStates("denied", "eu-west-1", []string{"i-0123"})
+	assert.Nil(t, err, "the output of another profile is shared")
+
+	assert.Equal(t, []string{"denied", "admin"}, profiles)
+}
@@ -0,0 +1,37 @@
+package aws
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// HealthyInstances returns the ids of the in service and healthy instances
+// of the autoscaling group.
+func HealthyInstances(profile, region, group string) ([]string, error) {
+	args := []string{
+		"autoscaling", "describe-auto-scaling-groups",
+		"--auto-scaling-group-names", group,
+		"--query", "AutoScalingGroups[0].Instances[?LifecycleState=='InService' && HealthStatus=='Healthy'].InstanceId",
+		"--output", "json",
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	out, err := runAWS(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	err = json.Unmarshal(out, &ret)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse autoscaling group instances")
+	}
+
+	return ret, nil
+}
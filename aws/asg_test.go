@@ -0,0 +1,22 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthyInstances(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	var calls [][]string
+	runAWS = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte(`["i-0123", "i-4567"]`), nil
+	}
+
+	ids, err := HealthyInstances("", "eu-west-1", "web")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"i-0123", "i-4567"}, ids)
+	assert.Equal(t, []string{"--region", "eu-west-1"}, calls[0][8:])
+}
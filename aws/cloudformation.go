@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/metrics"
+	"github.com/pkg/errors"
+)
+
+// runAWS runs the aws cli and returns its output, counting the calls by
+// profile. Tests, Fixtures and Record replace it.
+var runAWS = func(args ...string) ([]byte, error) {
+	metrics.Call("aws", flagValue(args, "--profile"))
+
+	out, err := exec.Command("aws", args...).Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "aws %v failed", args)
+	}
+
+	return out, nil
+}
+
+// flagValue returns the value of the flag of the aws cli call, if any.
+func flagValue(args []string, flag string) string {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+
+	return ""
+}
+
+type stackOutput struct {
+	OutputKey   string `json:"OutputKey"`
+	OutputValue string `json:"OutputValue"`
+}
+
+// StackOutputs returns the outputs of the CloudFormation stack.
+func StackOutputs(profile, region, stack string) (map[string]string, error) {
+	args := []string{
+		"cloudformation", "describe-stacks",
+		"--stack-name", stack,
+		"--profile", profile,
+		"--query", "Stacks[0].Outputs",
+		"--output", "json",
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	out, err := runAWS(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var outputs []stackOutput
+	err = json.Unmarshal(out, &outputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse stack outputs")
+	}
+
+	var ret = map[string]string{}
+	for _, output := range outputs {
+		ret[output.OutputKey] = output.OutputValue
+	}
+
+	return ret, nil
+}
+
+// StackProfiles generates an SSM session profile to the bastion of every
+// configured stack and a port forwarding profile per forwarded output.
+func StackProfiles(stacks []config.Stack) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, stack := range stacks {
+		outputs, err := StackOutputs(stack.Profile, stack.Region, stack.Stack)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile": stack.Profile,
+				"stack":   stack.Stack,
+				"err":     err,
+			}).Warn("Cannot read stack outputs")
+			continue
+		}
+
+		bastion, found := outputs[stack.Bastion]
+		if !found {
+			log.WithFields(log.Fields{
+				"stack":   stack.Stack,
+				"bastion": stack.Bastion,
+			}).Warn("Bastion output not found")
+			continue
+		}
+
+		ret = append(ret, *stackProfile(stack, "bastion", ssmCmd(stack, bastion)))
+
+		var forwards []string
+		for output := range stack.Forwards {
+			forwards = append(forwards, output)
+		}
+		sort.Strings(forwards)
+
+		for _, output := range forwards {
+			host, found := outputs[output]
+			if !found {
+				log.WithFields(log.Fields{
+					"stack":  stack.Stack,
+					"output": output,
+				}).Warn("Output not found")
+				continue
+			}
+
+			port := stack.Forwards[output]
+			args := fmt.Sprintf(
+				"--document-name AWS-StartPortForwardingSessionToRemoteHost --parameters host=%s,portNumber=%d,localPortNumber=%d",
+				host, port, port,
+			)
+			ret = append(ret, *stackProfile(stack, output, fmt.Sprintf("%s %s", ssmCmd(stack, bastion), args)))
+		}
+	}
+
+	return ret
+}
+
+func ssmCmd(stack config.Stack, target string) string {
+	cmd := fmt.Sprintf("/usr/bin/env AWS_PROFILE=%s aws ssm start-session --target %s", stack.Profile, target)
+	if stack.Region != "" {
+		cmd = fmt.Sprintf("%s --region %s", cmd, stack.Region)
+	}
+
+	return cmd
+}
+
+func stackProfile(stack config.Stack, name, command string) *iterm.Profile {
+	return iterm.NewProfile(fmt.Sprintf("cfn-%s-%s", stack.Stack, name), map[string]string{
+		"Command": command,
+		"Tags":    fmt.Sprintf("cloudformation,stack=%s,aws-profile=%s", stack.Stack, stack.Profile),
+	})
+}
@@ -0,0 +1,61 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackProfiles(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	var calls [][]string
+	runAWS = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte(`[
+			{"OutputKey": "BastionInstanceId", "OutputValue": "i-0123"},
+			{"OutputKey": "DbEndpoint", "OutputValue": "db.example.com"}
+		]`), nil
+	}
+
+	profiles := StackProfiles([]config.Stack{
+		{
+			Profile: "prod",
+			Region:  "eu-west-1",
+			Stack:   "bastion",
+			Bastion: "BastionInstanceId",
+			Forwards: map[string]int{
+				"DbEndpoint": 5432,
+				"Missing":    80,
+			},
+		},
+		{
+			Profile: "prod",
+			Stack:   "other",
+			Bastion: "Missing",
+		},
+	})
+
+	assert.Equal(t, []string{
+		"cloudformation", "describe-stacks", "--stack-name", "bastion", "--profile", "prod",
+		"--query", "Stacks[0].Outputs", "--output", "json", "--region", "eu-west-1",
+	}, calls[0])
+
+	assert.Equal(t, 2, len(profiles))
+	assert.Equal(t, "cfn-bastion-bastion", profiles[0].GUID)
+	assert.Equal(t, "/usr/bin/env AWS_PROFILE=prod aws ssm start-session --target i-0123 --region eu-west-1", profiles[0].Command)
+	assert.Equal(t, "cfn-bastion-DbEndpoint", profiles[1].GUID)
+	assert.Equal(t, ""+
+		"/usr/bin/env AWS_PROFILE=prod aws ssm start-session --target i-0123 --region eu-west-1 "+
+		"--document-name AWS-StartPortForwardingSessionToRemoteHost "+
+		"--parameters host=db.example.com,portNumber=5432,localPortNumber=5432", profiles[1].Command)
+}
+
+func TestFlagValue(t *testing.T) {
+	args := []string{"ec2", "describe-instances", "--profile", "prod", "--region"}
+
+	assert.Equal(t, "prod", flagValue(args, "--profile"))
+	assert.Equal(t, "", flagValue(args, "--region"))
+	assert.Equal(t, "", flagValue(args, "--output"))
+}
@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mhristof/germ/log"
+	"github.com/zieckey/goini"
+)
+
+// CredsTTLVariable is the iTerm user variable that holds the output of
+// `germ creds-ttl` for the current session.
+const CredsTTLVariable = "germCredsTTL"
+
+// expirationKeys are the keys that login tools write next to the
+// temporary credentials in ~/.aws/credentials.
+var expirationKeys = []string{
+	"aws_expiration",
+	"x_security_token_expires",
+	"aws_session_expiration",
+}
+
+// Expiry returns the time the credentials of the given profile expire. The
+// source_profile chain is followed until a profile with either SSO settings
+// or an expiration entry in the credentials file is found.
+func Expiry(profile, config, credentials, ssoCache string) (time.Time, bool) {
+	cfg := goini.New()
+	err := cfg.ParseFile(config)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"config": config,
+			"err":    err,
+		}).Debug("Cannot parse config file")
+	}
+
+	creds := goini.New()
+	err = creds.ParseFile(credentials)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"credentials": credentials,
+			"err":         err,
+		}).Debug("Cannot parse credentials file")
+	}
+
+	seen := map[string]bool{}
+	for profile != "" && !seen[profile] {
+		seen[profile] = true
+
+		section := fmt.Sprintf("profile %s", profile)
+		if profile == "default" {
+			section = profile
+		}
+
+		if startURL, found := cfg.SectionGet(section, "sso_start_url"); found {
+			return ssoExpiry(ssoCache, startURL)
+		}
+
+		for _, key := range expirationKeys {
+			if v, found := creds.SectionGet(profile, key); found {
+				return parseTime(v)
+			}
+		}
+
+		profile, _ = cfg.SectionGet(section, "source_profile")
+	}
+
+	return time.Time{}, false
+}
+
+func ssoExpiry(cache, startURL string) (time.Time, bool) {
+	files, err := filepath.Glob(filepath.Join(cache, "*.json"))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var ret time.Time
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+
+		var token struct {
+			StartURL  string `json:"startUrl"`
+			ExpiresAt string `json:"expiresAt"`
+		}
+
+		if json.Unmarshal(data, &token) != nil || token.StartURL != startURL {
+			continue
+		}
+
+		expires, ok := parseTime(token.ExpiresAt)
+		if ok && expires.After(ret) {
+			ret = expires
+		}
+	}
+
+	return ret, !ret.IsZero()
+}
+
+func parseTime(value string) (time.Time, bool) {
+	value = strings.Trim(value, `"'`)
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05UTC"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// TTL formats the remaining time until expires, ie "expires 42m".
+func TTL(expires, now time.Time) string {
+	left := expires.Sub(now)
+	if left <= 0 {
+		return "expired"
+	}
+
+	left = left.Round(time.Minute)
+	if left < time.Minute {
+		return "expires <1m"
+	}
+
+	return fmt.Sprintf("expires %s", strings.TrimSuffix(left.String(), "0s"))
+}
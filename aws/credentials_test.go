@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "creds-ttl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := filepath.Join(dir, "config")
+	credentials := filepath.Join(dir, "credentials")
+	cache := filepath.Join(dir, "sso")
+
+	files := map[string]string{
+		config: heredoc.Doc(`
+			[profile azure]
+			azure_tenant_id = tenant
+
+			[profile child]
+			source_profile = azure
+
+			[profile sso]
+			sso_start_url = https://example.awsapps.com/start
+		`),
+		credentials: heredoc.Doc(`
+			[azure]
+			aws_expiration = 2021-08-01T12:00:00.000Z
+		`),
+		filepath.Join(cache, "token.json"): `{"startUrl": "https://example.awsapps.com/start", "expiresAt": "2021-08-01T13:00:00UTC"}`,
+	}
+
+	for file, contents := range files {
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cases = []struct {
+		name    string
+		profile string
+		expires time.Time
+		found   bool
+	}{
+		{
+			name:    "credentials file expiration",
+			profile: "azure",
+			expires: time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC),
+			found:   true,
+		},
+		{
+			name:    "expiration from the source profile",
+			profile: "child",
+			expires: time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC),
+			found:   true,
+		},
+		{
+			name:    "sso cache token",
+			profile: "sso",
+			expires: time.Date(2021, 8, 1, 13, 0, 0, 0, time.UTC),
+			found:   true,
+		},
+		{
+			name:    "unknown profile",
+			profile: "missing",
+		},
+	}
+
+	for _, test := range cases {
+		expires, found := Expiry(test.profile, config, credentials, cache)
+		assert.Equal(t, test.found, found, test.name)
+		assert.True(t, test.expires.Equal(expires), test.name)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	now := time.Date(2021, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	var cases = []struct {
+		name    string
+		expires time.Time
+		out     string
+	}{
+		{
+			name:    "minutes left",
+			expires: now.Add(42 * time.Minute),
+			out:     "expires 42m",
+		},
+		{
+			name:    "hours left",
+			expires: now.Add(90 * time.Minute),
+			out:     "expires 1h30m",
+		},
+		{
+			name:    "expired",
+			expires: now.Add(-time.Minute),
+			out:     "expired",
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, TTL(test.expires, now), test.name)
+	}
+}
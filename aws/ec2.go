@@ -0,0 +1,45 @@
+package aws
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// InstanceStates returns the EC2 state of the instances, ie running or
+// stopped, by instance id. Instances that no longer exist are missing.
+func InstanceStates(profile, region string, ids []string) (map[string]string, error) {
+	args := []string{
+		"ec2", "describe-instances",
+		"--filters", "Name=instance-id,Values=" + strings.Join(ids, ","),
+		"--query", "Reservations[].Instances[].[InstanceId, State.Name]",
+		"--output", "json",
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	out, err := runAWS(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances [][]string
+	err = json.Unmarshal(out, &instances)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse instances")
+	}
+
+	var ret = map[string]string{}
+	for _, instance := range instances {
+		if len(instance) == 2 {
+			ret[instance[0]] = instance[1]
+		}
+	}
+
+	return ret, nil
+}
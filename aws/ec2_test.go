@@ -0,0 +1,23 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstanceStates(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	var calls [][]string
+	runAWS = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte(`[["i-0123", "running"], ["i-4567", "stopped"]]`), nil
+	}
+
+	states, err := InstanceStates("prod", "eu-west-1", []string{"i-0123", "i-4567", "i-gone"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"i-0123": "running", "i-4567": "stopped"}, states)
+	assert.Equal(t, "Name=instance-id,Values=i-0123,i-4567,i-gone", calls[0][3])
+	assert.Equal(t, []string{"--profile", "prod", "--region", "eu-west-1"}, calls[0][8:])
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// Fixtures replaces the aws cli with the JSON files of the directory, to
+// generate the profiles without credentials, ie for tests and demos. The
+// output of `aws ec2 describe-instances --instance-ids i-0123 --profile prod
+// --region eu-west-1` is read from the first of
+//
+//	ec2-describe-instances-prod-eu-west-1-<hash>.json
+//	ec2-describe-instances-prod-eu-west-1.json
+//	ec2-describe-instances-prod.json
+//	ec2-describe-instances.json
+//
+// where <hash> is of the other arguments, ie the instance ids.
+func Fixtures(dir string) {
+	runAWS = func(args ...string) ([]byte, error) {
+		for _, name := range fixtureNames(args) {
+			out, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			log.WithFields(log.Fields{
+				"args":    args,
+				"fixture": name,
+			}).Debug("Using fixture")
+
+			return out, err
+		}
+
+		return nil, errors.Errorf("no fixture for aws %s in %s", strings.Join(args, " "), dir)
+	}
+}
+
+// Record passes the output of every aws cli call to save, with the name of
+// its most specific fixture, so calls with different arguments are kept
+// apart.
+func Record(save func(name string, data []byte)) {
+	next := runAWS
+	runAWS = func(args ...string) ([]byte, error) {
+		out, err := next(args...)
+		if err == nil {
+			save(fixtureNames(args)[0], out)
+		}
+
+		return out, err
+	}
+}
+
+// fixtureNames returns the fixtures of the aws cli call, from the most to
+// the least specific.
+func fixtureNames(args []string) []string {
+	var command, other []string
+	var profile, region string
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--profile" && i+1 < len(args):
+			profile = args[i+1]
+			i++
+		case args[i] == "--region" && i+1 < len(args):
+			region = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], "--"):
+			other = append(other, args[i])
+			if i+1 < len(args) {
+				other = append(other, args[i+1])
+			}
+			i++
+		case len(command) < 2:
+			command = append(command, args[i])
+		default:
+			other = append(other, args[i])
+		}
+	}
+
+	name := strings.Join(command, "-")
+
+	var ret []string
+	if len(other) > 0 {
+		specific := []string{name}
+		for _, value := range []string{profile, region} {
+			if value != "" {
+				specific = append(specific, value)
+			}
+		}
+		specific = append(specific, fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(other, " "))))[:8])
+
+		ret = append(ret, fmt.Sprintf("%s.json", strings.Join(specific, "-")))
+	}
+	if profile != "" && region != "" {
+		ret = append(ret, fmt.Sprintf("%s-%s-%s.json", name, profile, region))
+	}
+	if profile != "" {
+		ret = append(ret, fmt.Sprintf("%s-%s.json", name, profile))
+	}
+
+	return append(ret, fmt.Sprintf("%s.json", name))
+}
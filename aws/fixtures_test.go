@@ -0,0 +1,115 @@
+package aws
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtureNames(t *testing.T) {
+	var cases = []struct {
+		name string
+		args []string
+		exp  []string
+	}{
+		{
+			name: "profile and region",
+			args: []string{"ec2", "describe-instances", "--filters", "Name=instance-id,Values=i-0123", "--profile", "prod", "--region", "eu-west-1"},
+			exp: []string{
+				"ec2-describe-instances-prod-eu-west-1-766f7494.json",
+				"ec2-describe-instances-prod-eu-west-1.json",
+				"ec2-describe-instances-prod.json",
+				"ec2-describe-instances.json",
+			},
+		},
+		{
+			name: "profile only",
+			args: []string{"s3api", "list-buckets", "--query", "Buckets[].Name", "--output", "json", "--profile", "dev"},
+			exp:  []string{"s3api-list-buckets-dev-586b6a2b.json", "s3api-list-buckets-dev.json", "s3api-list-buckets.json"},
+		},
+		{
+			name: "no profile",
+			args: []string{"cloudformation", "describe-stacks", "--stack-name", "network"},
+			exp:  []string{"cloudformation-describe-stacks-cc2fb908.json", "cloudformation-describe-stacks.json"},
+		},
+		{
+			name: "no other arguments",
+			args: []string{"sts", "get-caller-identity", "--profile", "dev"},
+			exp:  []string{"sts-get-caller-identity-dev.json", "sts-get-caller-identity.json"},
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, fixtureNames(test.args), test.name)
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "ec2-describe-instances.json"), []byte(`[["i-0123", "running"]]`), 0644))
+
+	Fixtures(dir)
+
+	states, err := InstanceStates("prod", "eu-west-1", []string{"i-0123"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"i-0123": "running"}, states)
+
+	_, err = StackOutputs("prod", "eu-west-1", "network")
+	assert.NotNil(t, err)
+}
+
+func TestRecord(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	runAWS = func(args ...string) ([]byte, error) {
+		return []byte(`[["i-0123", "running"]]`), nil
+	}
+
+	var saved = map[string]string{}
+	Record(func(name string, data []byte) { saved[name] = string(data) })
+
+	_, err := InstanceStates("prod", "eu-west-1", []string{"i-0123"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"ec2-describe-instances-prod-eu-west-1-fc031bc1.json": `[["i-0123", "running"]]`}, saved)
+}
+
+func TestRecordReplay(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	dir, err := ioutil.TempDir("", "recordings")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runAWS = func(args ...string) ([]byte, error) {
+		return []byte(fmt.Sprintf(`[{"OutputKey": "Stack", "OutputValue": "%s"}]`, args[3])), nil
+	}
+	Record(func(name string, data []byte) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+	})
+
+	for _, stack := range []string{"network", "app"} {
+		_, err = StackOutputs("prod", "eu-west-1", stack)
+		assert.Nil(t, err)
+	}
+
+	Fixtures(dir)
+
+	for _, stack := range []string{"network", "app"} {
+		outputs, err := StackOutputs("prod", "eu-west-1", stack)
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"Stack": stack}, outputs, stack)
+	}
+}
@@ -0,0 +1,186 @@
+package aws
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"text/template"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// LoginProvider is a tool that can refresh the credentials of a profile.
+type LoginProvider struct {
+	Name string
+	// Tool is the executable that has to be in the PATH.
+	Tool string
+	// Keys are the profile keys that select this provider.
+	Keys []string
+	// Command is a template rendered with the profile name.
+	Command string
+	// Env are environment variables passed through to the tool.
+	Env []string
+}
+
+// LoginProviders is the registry of the known login tools, in order of
+// precedence.
+var LoginProviders = []LoginProvider{
+	{
+		Name:    "aws-azure-login",
+		Tool:    "aws-azure-login",
+		Keys:    []string{"azure_tenant_id"},
+		Command: "aws-azure-login --no-prompt",
+		Env:     []string{"NODE_EXTRA_CA_CERTS"},
+	},
+	{
+		Name:    "aws-sso",
+		Tool:    "aws",
+		Keys:    []string{"sso_start_url"},
+		Command: "aws sso login",
+	},
+	{
+		Name:    "saml2aws",
+		Tool:    "saml2aws",
+		Keys:    []string{"x_principal_arn"},
+		Command: "saml2aws login --skip-prompt --profile {{ .Profile }}{{ if .Account }} -a {{ .Account }}{{ end }}{{ if .Role }} --role {{ .Role }}{{ end }}",
+	},
+	{
+		Name:    "gimme-aws-creds",
+		Tool:    "gimme-aws-creds",
+		Keys:    []string{"okta_org_url"},
+		Command: "gimme-aws-creds --profile {{ .Profile }}",
+	},
+	{
+		Name:    "onelogin-aws",
+		Tool:    "onelogin-aws-login",
+		Keys:    []string{"onelogin_subdomain"},
+		Command: "onelogin-aws-login --profile {{ .Profile }}",
+	},
+}
+
+// lookPath finds the login tools in the PATH, replaced in the tests.
+var lookPath = exec.LookPath
+
+// loginProviders returns the registry with the command templates overridden
+// from the germ config.
+func loginProviders(cfg *config.Config) []LoginProvider {
+	providers := make([]LoginProvider, len(LoginProviders))
+	copy(providers, LoginProviders)
+
+	for i := range providers {
+		if command, found := cfg.LoginCommands[providers[i].Name]; found {
+			providers[i].Command = command
+		}
+	}
+
+	return providers
+}
+
+// FindLoginProvider returns the provider with the given name.
+func FindLoginProvider(name string, cfg *config.Config) (LoginProvider, bool) {
+	for _, provider := range loginProviders(cfg) {
+		if provider.Name == name {
+			return provider, true
+		}
+	}
+
+	return LoginProvider{}, false
+}
+
+// installedLoginProviders returns the providers whose tool is in the PATH.
+func installedLoginProviders(cfg *config.Config) []LoginProvider {
+	var installed []LoginProvider
+	for _, provider := range loginProviders(cfg) {
+		if _, err := lookPath(provider.Tool); err != nil {
+			continue
+		}
+
+		installed = append(installed, provider)
+	}
+
+	return installed
+}
+
+// loginProvider selects the provider for the profile, either from the germ
+// config or from the first installed provider whose keys are present in the
+// profile.
+func loginProvider(name string, section map[string]string, cfg *config.Config) (LoginProvider, bool) {
+	if override, found := config.Lookup(cfg.Login, name); found {
+		provider, found := FindLoginProvider(override, cfg)
+		if !found {
+			log.WithFields(log.Fields{
+				"name":     name,
+				"provider": override,
+			}).Fatal("Unknown login provider")
+		}
+
+		return provider, true
+	}
+
+	for _, provider := range installedLoginProviders(cfg) {
+		for _, key := range provider.Keys {
+			if _, found := section[key]; found {
+				return provider, true
+			}
+		}
+	}
+
+	return LoginProvider{}, false
+}
+
+func loginCmd(name string, section map[string]string, cfg *config.Config) string {
+	provider, found := loginProvider(name, section, cfg)
+	if !found {
+		return ""
+	}
+
+	return provider.Cmd(LoginVars{Profile: name})
+}
+
+// LoginVars are the variables available in the provider command templates.
+type LoginVars struct {
+	Profile string
+	Account string
+	Role    string
+}
+
+// Cmd renders the login command for the provider. An empty string is
+// returned if the tool cannot be found in the PATH.
+func (l LoginProvider) Cmd(vars LoginVars) string {
+	bin, err := lookPath(l.Tool)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"tool":    l.Tool,
+			"profile": vars.Profile,
+			"err":     err,
+		}).Warn("Cannot find executable, skipping login command")
+		return ""
+	}
+
+	tmpl, err := template.New(l.Name).Parse(l.Command)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"provider": l.Name,
+			"err":      err,
+		}).Fatal("Cannot parse login command template")
+	}
+
+	var toolCmd bytes.Buffer
+	err = tmpl.Execute(&toolCmd, vars)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"provider": l.Name,
+			"err":      err,
+		}).Fatal("Cannot render login command template")
+	}
+
+	env := fmt.Sprintf("AWS_PROFILE=%s PATH=%s", vars.Profile, filepath.Dir(bin))
+	for _, key := range l.Env {
+		env = fmt.Sprintf("%s %s=%s", env, key, os.Getenv(key))
+	}
+
+	return fmt.Sprintf("bash -c '%s %s'", env, toolCmd.String())
+}
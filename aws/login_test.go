@@ -0,0 +1,126 @@
+package aws
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginProvider(t *testing.T) {
+	var cases = []struct {
+		name     string
+		profile  string
+		section  map[string]string
+		cfg      *config.Config
+		tools    []string
+		provider string
+		found    bool
+		command  string
+	}{
+		{
+			name:    "azure profile",
+			profile: "azure",
+			section: map[string]string{
+				"azure_tenant_id": "tenant",
+			},
+			cfg:      &config.Config{},
+			tools:    []string{"aws-azure-login"},
+			provider: "aws-azure-login",
+			found:    true,
+			command:  "aws-azure-login --no-prompt",
+		},
+		{
+			name:    "sso profile",
+			profile: "sso",
+			section: map[string]string{
+				"sso_start_url": "https://example.awsapps.com/start",
+			},
+			cfg:      &config.Config{},
+			tools:    []string{"aws"},
+			provider: "aws-sso",
+			found:    true,
+			command:  "aws sso login",
+		},
+		{
+			name:    "first installed provider",
+			profile: "mixed",
+			section: map[string]string{
+				"azure_tenant_id": "tenant",
+				"sso_start_url":   "https://example.awsapps.com/start",
+			},
+			cfg:      &config.Config{},
+			tools:    []string{"aws"},
+			provider: "aws-sso",
+			found:    true,
+			command:  "aws sso login",
+		},
+		{
+			name:    "provider not installed",
+			profile: "azure",
+			section: map[string]string{
+				"azure_tenant_id": "tenant",
+			},
+			cfg: &config.Config{},
+		},
+		{
+			name:    "command overridden from the config",
+			profile: "okta",
+			section: map[string]string{
+				"okta_org_url": "https://example.okta.com",
+			},
+			cfg: &config.Config{
+				LoginCommands: map[string]string{
+					"gimme-aws-creds": "gimme-aws-creds --profile {{ .Profile }} --remember-device",
+				},
+			},
+			tools:    []string{"gimme-aws-creds"},
+			provider: "gimme-aws-creds",
+			found:    true,
+			command:  "gimme-aws-creds --profile {{ .Profile }} --remember-device",
+		},
+		{
+			name:    "provider overridden from the config",
+			profile: "okta-prod",
+			section: map[string]string{
+				"azure_tenant_id": "tenant",
+			},
+			cfg: &config.Config{
+				Login: map[string]string{
+					"okta-*": "gimme-aws-creds",
+				},
+			},
+			provider: "gimme-aws-creds",
+			found:    true,
+			command:  "gimme-aws-creds --profile {{ .Profile }}",
+		},
+		{
+			name:    "no provider",
+			profile: "static",
+			section: map[string]string{
+				"region": "eu-west-1",
+			},
+			cfg: &config.Config{},
+		},
+	}
+
+	defer func(f func(string) (string, error)) { lookPath = f }(lookPath)
+
+	for _, test := range cases {
+		lookPath = func(file string) (string, error) {
+			for _, tool := range test.tools {
+				if tool == file {
+					return "/usr/local/bin/" + file, nil
+				}
+			}
+
+			return "", exec.ErrNotFound
+		}
+
+		provider, found := loginProvider(test.profile, test.section, test.cfg)
+		assert.Equal(t, test.found, found, test.name)
+		assert.Equal(t, test.provider, provider.Name, test.name)
+		assert.Equal(t, test.command, provider.Command, test.name)
+	}
+}
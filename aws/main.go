@@ -2,41 +2,112 @@ package aws
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"os/user"
-	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/iterm"
 	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/metrics"
 	"github.com/zieckey/goini"
 )
 
-func Profiles(prefix, config string) []iterm.Profile {
+func Profiles(prefix, path string, cfg *config.Config) []iterm.Profile {
+	return profiles(prefix, path, cfg, map[string]bool{})
+}
+
+// CredentialProfiles generates the profiles for the sections of the
+// credentials file that are not already defined in the config file.
+func CredentialProfiles(prefix, credentials, configFile string, cfg *config.Config) []iterm.Profile {
+	return profiles(prefix, credentials, cfg, sectionNames(configFile))
+}
+
+// ProfileNames returns the sorted names of the profiles defined in the given
+// config and credentials files.
+func ProfileNames(paths ...string) []string {
+	var ret []string
+	var seen = map[string]bool{}
+
+	for _, path := range paths {
+		for name := range sectionNames(path) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			ret = append(ret, name)
+		}
+	}
+
+	sort.Strings(ret)
+
+	return ret
+}
+
+func sectionNames(path string) map[string]bool {
+	var ret = map[string]bool{}
+
 	ini := goini.New()
-	err := ini.ParseFile(config)
+	err := ini.ParseFile(path)
 	if err != nil {
 		log.WithFields(log.Fields{
-			"config": config,
+			"path": path,
+			"err":  err,
+		}).Debug("Cannot parse file")
+		return ret
+	}
+
+	for name := range ini.GetAll() {
+		if name == "" {
+			continue
+		}
+		ret[strings.TrimPrefix(name, "profile ")] = true
+	}
+
+	return ret
+}
+
+func profiles(prefix, path string, cfg *config.Config, skip map[string]bool) []iterm.Profile {
+	ini := goini.New()
+	err := ini.ParseFile(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"config": path,
 			"err":    err.Error(),
 		}).Error("paarseINI file failed.")
 		return nil
 	}
 
 	var prof iterm.Profiles
+	sections := map[string]map[string]string{}
 	for name, section := range ini.GetAll() {
 		if name == "" {
 			continue
 		}
 		tName := strings.TrimPrefix(name, "profile ")
-		add(&prof, prefix, fmt.Sprintf("%s", tName), section)
+		if skip[tName] {
+			log.WithFields(log.Fields{
+				"name": tName,
+				"path": path,
+			}).Debug("Skipping duplicate profile")
+			continue
+		}
+		sections[tName] = section
+
+		start := time.Now()
+		add(&prof, prefix, fmt.Sprintf("%s", tName), section, cfg)
+		metrics.Since("aws-profile", tName, start)
 	}
 
+	addRefreshTriggers(&prof, prefix, sections)
+
 	return prof.Profiles
 }
 
-func add(p *iterm.Profiles, prefix, name string, config map[string]string) {
+// sessionCmd returns the command of a login shell with the given AWS
+// profile and any extra environment variables exported.
+func sessionCmd(profile string, env ...string) string {
 	user, err := user.Current()
 	if err != nil {
 		log.WithFields(log.Fields{
@@ -44,45 +115,105 @@ func add(p *iterm.Profiles, prefix, name string, config map[string]string) {
 		}).Fatal("Cannot find current user")
 	}
 
-	config["Command"] = fmt.Sprintf("/usr/bin/env AWS_PROFILE=%s /usr/bin/login -fp %s", name, user.Username)
+	vars := append([]string{fmt.Sprintf("AWS_PROFILE=%s", profile)}, env...)
+
+	return fmt.Sprintf("/usr/bin/env %s /usr/bin/login -fp %s", strings.Join(vars, " "), user.Username)
+}
+
+// addRefreshTriggers adds a trigger to every profile that is (or is sourced
+// from) an azure login profile, to re-run aws-azure-login once the session
+// credentials expire.
+func addRefreshTriggers(p *iterm.Profiles, prefix string, sections map[string]map[string]string) {
+	for name := range sections {
+		root := azureRoot(name, sections)
+		if root == "" {
+			continue
+		}
+
+		pName := name
+		if prefix != "" {
+			pName = fmt.Sprintf("%s-%s", prefix, name)
+		}
+
+		for i := range p.Profiles {
+			if p.Profiles[i].GUID != pName {
+				continue
+			}
+
+			p.Profiles[i].Triggers = append(p.Profiles[i].Triggers, iterm.Trigger{
+				Action:    "SendTextTrigger",
+				Parameter: fmt.Sprintf("aws-azure-login --no-prompt --profile %s", root),
+				Regex:     `(ExpiredToken|credentials have expired)`,
+			})
+		}
+	}
+}
+
+// azureRoot follows the source_profile chain of the given profile and
+// returns the name of the azure login profile at the root of it.
+func azureRoot(name string, sections map[string]map[string]string) string {
+	seen := map[string]bool{}
+
+	for !seen[name] {
+		seen[name] = true
+
+		section, found := sections[name]
+		if !found {
+			return ""
+		}
+
+		if _, azure := section["azure_tenant_id"]; azure {
+			return name
+		}
+
+		name = section["source_profile"]
+	}
+
+	return ""
+}
+
+func add(p *iterm.Profiles, prefix, name string, section map[string]string, cfg *config.Config) {
+	section["Command"] = sessionCmd(name)
 	pName := name
 	if prefix != "" {
 		pName = fmt.Sprintf("%s-%s", prefix, name)
 	}
-	profile := iterm.NewProfile(pName, config)
+	section["BadgeText"] = fmt.Sprintf("%s\n\\(user.%s)", pName, CredsTTLVariable)
+	profile := iterm.NewProfile(pName, section)
+	profile.Triggers = append(profile.Triggers, iterm.SessionManagerTrigger())
 	p.Add(*profile)
 
-	if _, found := config["source_profile"]; !found {
-		config["Command"] = loginCmd(name, config)
-		loginProfile := iterm.NewProfile(fmt.Sprintf("login-%s", name), config)
+	regions, _ := config.LookupList(cfg.Regions, name)
+	addRegions(p, pName, name, section, regions)
+
+	if _, found := section["source_profile"]; !found {
+		delete(section, "BadgeText")
+		section["Command"] = loginCmd(name, section, cfg)
+		section["Close"] = iterm.CloseOnFailure
+		loginProfile := iterm.NewProfile(fmt.Sprintf("login-%s", name), section)
 		p.Add(*loginProfile)
 	}
 }
 
-func loginCmd(name string, config map[string]string) string {
-	var tool, toolCmd string
-	_, azure := config["azure_tenant_id"]
+// addRegions adds a profile per region for the given AWS profile, with
+// AWS_REGION exported as well.
+func addRegions(p *iterm.Profiles, pName, name string, section map[string]string, regions []string) {
+	for _, region := range regions {
+		rName := fmt.Sprintf("%s-%s", pName, region)
 
-	if azure {
-		tool = "aws-azure-login"
-		toolCmd = fmt.Sprintf("%s --no-prompt", tool)
-	} else {
-		return ""
-	}
-
-	bin, err := exec.LookPath(tool)
-	if err != nil {
-		log.WithFields(log.Fields{
-			"tool": tool,
-			"err":  err,
-		}).Fatal("Cannot find executable")
-	}
+		rSection := map[string]string{}
+		for k, v := range section {
+			rSection[k] = v
+		}
 
-	return fmt.Sprintf(
-		"bash -c 'AWS_PROFILE=%s PATH=%s NODE_EXTRA_CA_CERTS=%s %s || sleep 60'",
-		name, filepath.Dir(bin), os.Getenv("NODE_EXTRA_CA_CERTS"), toolCmd,
-	)
+		rSection["Command"] = sessionCmd(name, fmt.Sprintf("AWS_REGION=%s", region))
+		rSection["BadgeText"] = fmt.Sprintf("%s\n\\(user.%s)", rName, CredsTTLVariable)
+		rSection["Tags"] = fmt.Sprintf("region=%s", region)
 
+		profile := iterm.NewProfile(rName, rSection)
+		profile.Triggers = append(profile.Triggers, iterm.SessionManagerTrigger())
+		p.Add(*profile)
+	}
 }
 
 // Regions retrieve all AWS regions. This list is generated from
@@ -2,8 +2,13 @@ package aws
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/iterm"
 	"github.com/stretchr/testify/assert"
 )
@@ -48,7 +53,7 @@ func TestAdd(t *testing.T) {
 	for _, test := range cases {
 		var prof iterm.Profiles
 		for i, cfg := range test.config {
-			add(&prof, "", fmt.Sprintf("%d", i), cfg)
+			add(&prof, "", fmt.Sprintf("%d", i), cfg, &config.Config{})
 		}
 
 		assert.Equal(t, len(test.expected), len(prof.Profiles))
@@ -58,5 +63,133 @@ func TestAdd(t *testing.T) {
 			assert.True(t, found)
 		}
 
+		session, _ := prof.FindGUID("0")
+		assert.Contains(t, session.Triggers, iterm.SessionManagerTrigger(), test.name)
+
+	}
+}
+
+func TestAddRefreshTriggers(t *testing.T) {
+	sections := map[string]map[string]string{
+		"azure": {
+			"azure_tenant_id": "tenant",
+		},
+		"child": {
+			"source_profile": "azure",
+		},
+		"grandchild": {
+			"source_profile": "child",
+		},
+		"other": {
+			"sso_start_url": "https://example.awsapps.com/start",
+		},
+	}
+
+	var cases = []struct {
+		name     string
+		guid     string
+		triggers int
+	}{
+		{
+			name:     "azure profile",
+			guid:     "config-azure",
+			triggers: 1,
+		},
+		{
+			name:     "profile sourced from azure",
+			guid:     "config-child",
+			triggers: 1,
+		},
+		{
+			name:     "nested azure source",
+			guid:     "config-grandchild",
+			triggers: 1,
+		},
+		{
+			name:     "non azure profile",
+			guid:     "config-other",
+			triggers: 0,
+		},
+	}
+
+	var prof iterm.Profiles
+	for name := range sections {
+		prof.Add(iterm.Profile{GUID: "config-" + name})
 	}
+
+	addRefreshTriggers(&prof, "config", sections)
+
+	for _, test := range cases {
+		profile, found := prof.FindGUID(test.guid)
+		assert.True(t, found, test.name)
+		assert.Equal(t, test.triggers, len(profile.Triggers), test.name)
+	}
+
+	profile, _ := prof.FindGUID("config-grandchild")
+	assert.Equal(t, "aws-azure-login --no-prompt --profile azure", profile.Triggers[0].Parameter)
+}
+
+func TestCredentialProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config")
+	credentials := filepath.Join(dir, "credentials")
+
+	files := map[string]string{
+		configFile: heredoc.Doc(`
+			[profile shared]
+			source_profile = keys
+		`),
+		credentials: heredoc.Doc(`
+			[shared]
+			aws_access_key_id = AKIA
+
+			[keys]
+			aws_access_key_id = AKIA
+		`),
+	}
+
+	for file, contents := range files {
+		if err := ioutil.WriteFile(file, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prof := iterm.Profiles{
+		Profiles: CredentialProfiles("credentials", credentials, configFile, &config.Config{}),
+	}
+
+	_, found := prof.FindGUID("credentials-keys")
+	assert.True(t, found)
+
+	_, found = prof.FindGUID("credentials-shared")
+	assert.False(t, found)
+
+	assert.Equal(t, []string{"keys", "shared"}, ProfileNames(configFile, credentials))
+}
+
+func TestAddRegions(t *testing.T) {
+	cfg := &config.Config{
+		Regions: map[string][]string{
+			"prod-*": {"eu-west-1", "us-east-1"},
+		},
+	}
+
+	var prof iterm.Profiles
+	add(&prof, "config", "prod-app", map[string]string{"source_profile": "root"}, cfg)
+	add(&prof, "config", "dev-app", map[string]string{"source_profile": "root"}, cfg)
+
+	assert.Equal(t, 4, len(prof.Profiles))
+
+	profile, found := prof.FindGUID("config-prod-app-eu-west-1")
+	assert.True(t, found)
+	assert.Contains(t, profile.Command, "AWS_PROFILE=prod-app AWS_REGION=eu-west-1 ")
+	assert.True(t, profile.HasTag("region=eu-west-1"))
+
+	_, found = prof.FindGUID("config-dev-app-eu-west-1")
+	assert.False(t, found)
 }
@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+type bucketTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+// BucketProfiles generates a shell profile per bucket, with the AWS profile
+// exported and the bucket listed.
+func BucketProfiles(buckets []config.Buckets) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, b := range buckets {
+		names, err := bucketNames(b)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile": b.Profile,
+				"err":     err,
+			}).Warn("Cannot list buckets")
+		}
+
+		for _, name := range names {
+			ret = append(ret, *bucketProfile(b, name))
+		}
+	}
+
+	return ret
+}
+
+// bucketNames returns the configured buckets and the ones with the tag, if
+// any.
+func bucketNames(b config.Buckets) ([]string, error) {
+	var seen = map[string]bool{}
+	var ret []string
+
+	add := func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		ret = append(ret, name)
+	}
+
+	for _, name := range b.Buckets {
+		add(name)
+	}
+
+	if b.Tag == "" {
+		return ret, nil
+	}
+
+	out, err := runAWS(awsArgs(b, "s3api", "list-buckets", "--query", "Buckets[].Name", "--output", "json")...)
+	if err != nil {
+		return ret, err
+	}
+
+	var all []string
+	err = json.Unmarshal(out, &all)
+	if err != nil {
+		return ret, errors.Wrap(err, "cannot parse buckets")
+	}
+	sort.Strings(all)
+
+	key := strings.SplitN(b.Tag, "=", 2)
+	for _, name := range all {
+		tags, err := bucketTags(b, name)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"bucket": name,
+				"err":    err,
+			}).Debug("Cannot read bucket tags")
+			continue
+		}
+
+		value, found := tags[key[0]]
+		if found && (len(key) == 1 || value == key[1]) {
+			add(name)
+		}
+	}
+
+	return ret, nil
+}
+
+func bucketTags(b config.Buckets, bucket string) (map[string]string, error) {
+	out, err := runAWS(awsArgs(b, "s3api", "get-bucket-tagging", "--bucket", bucket, "--query", "TagSet", "--output", "json")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []bucketTag
+	err = json.Unmarshal(out, &tags)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse bucket tags")
+	}
+
+	var ret = map[string]string{}
+	for _, tag := range tags {
+		ret[tag.Key] = tag.Value
+	}
+
+	return ret, nil
+}
+
+func awsArgs(b config.Buckets, args ...string) []string {
+	args = append(args, "--profile", b.Profile)
+	if b.Region != "" {
+		args = append(args, "--region", b.Region)
+	}
+
+	return args
+}
+
+func bucketProfile(b config.Buckets, bucket string) *iterm.Profile {
+	env := []string{fmt.Sprintf("GERM_BUCKET=s3://%s", bucket)}
+	tags := fmt.Sprintf("s3,bucket=%s,aws-profile=%s", bucket, b.Profile)
+	if b.Region != "" {
+		env = append(env, fmt.Sprintf("AWS_REGION=%s", b.Region))
+		tags = fmt.Sprintf("%s,region=%s", tags, b.Region)
+	}
+
+	return iterm.NewProfile(fmt.Sprintf("s3-%s-%s", b.Profile, bucket), map[string]string{
+		"Command":     sessionCmd(b.Profile, env...),
+		"InitialText": fmt.Sprintf("aws s3 ls s3://%s", bucket),
+		"Tags":        tags,
+	})
+}
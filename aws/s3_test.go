@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketProfiles(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	runAWS = func(args ...string) ([]byte, error) {
+		switch {
+		case args[1] == "list-buckets":
+			return []byte(`["logs", "data-raw", "data-curated"]`), nil
+		case args[3] == "data-raw":
+			return []byte(`[{"Key": "team", "Value": "data"}]`), nil
+		case args[3] == "data-curated":
+			return []byte(`[{"Key": "team", "Value": "analytics"}]`), nil
+		}
+
+		return nil, errors.New("NoSuchTagSet")
+	}
+
+	var cases = []struct {
+		name    string
+		buckets config.Buckets
+		exp     []string
+	}{
+		{
+			name:    "configured buckets",
+			buckets: config.Buckets{Profile: "data", Buckets: []string{"logs"}},
+			exp:     []string{"s3-data-logs"},
+		},
+		{
+			name:    "tag with value",
+			buckets: config.Buckets{Profile: "data", Buckets: []string{"logs"}, Tag: "team=data"},
+			exp:     []string{"s3-data-logs", "s3-data-data-raw"},
+		},
+		{
+			name:    "tag key",
+			buckets: config.Buckets{Profile: "data", Tag: "team"},
+			exp:     []string{"s3-data-data-curated", "s3-data-data-raw"},
+		},
+	}
+
+	for _, test := range cases {
+		var names []string
+		for _, profile := range BucketProfiles([]config.Buckets{test.buckets}) {
+			names = append(names, profile.GUID)
+		}
+
+		assert.Equal(t, test.exp, names, test.name)
+	}
+
+	profile := BucketProfiles([]config.Buckets{{Profile: "data", Region: "eu-west-1", Buckets: []string{"logs"}}})[0]
+	assert.Equal(t, "aws s3 ls s3://logs", profile.InitialText)
+	assert.True(t, strings.Contains(profile.Command, "AWS_PROFILE=data GERM_BUCKET=s3://logs AWS_REGION=eu-west-1"))
+	assert.True(t, profile.HasTag("region=eu-west-1"))
+}
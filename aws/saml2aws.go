@@ -0,0 +1,101 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/zieckey/goini"
+)
+
+// Saml2AWSProfiles generates a login and a session profile for every idp
+// account in the saml2aws configuration file. Roles listed in the germ
+// config for an account get their own pair of profiles.
+func Saml2AWSProfiles(path string, cfg *config.Config) []iterm.Profile {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	ini := goini.New()
+	err := ini.ParseFile(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Error("Cannot parse saml2aws config")
+		return nil
+	}
+
+	provider, _ := FindLoginProvider("saml2aws", cfg)
+
+	var accounts []string
+	for account := range ini.GetAll() {
+		if account == "" {
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	var prof iterm.Profiles
+	for _, account := range accounts {
+		section, _ := ini.GetKvmap(account)
+
+		awsProfile := section["aws_profile"]
+		if awsProfile == "" {
+			awsProfile = "saml"
+		}
+
+		addSaml2AWS(&prof, provider, fmt.Sprintf("saml2aws-%s", account), LoginVars{
+			Profile: awsProfile,
+			Account: account,
+			Role:    section["role_arn"],
+		})
+
+		for _, role := range cfg.Saml2AWS[account] {
+			name := roleName(role)
+
+			addSaml2AWS(&prof, provider, fmt.Sprintf("saml2aws-%s-%s", account, name), LoginVars{
+				Profile: fmt.Sprintf("%s-%s", awsProfile, name),
+				Account: account,
+				Role:    role,
+			})
+		}
+	}
+
+	return prof.Profiles
+}
+
+func addSaml2AWS(p *iterm.Profiles, provider LoginProvider, name string, vars LoginVars) {
+	config := map[string]string{
+		"Command": sessionCmd(vars.Profile),
+	}
+
+	if vars.Role != "" {
+		config["role_arn"] = vars.Role
+	}
+
+	login := fmt.Sprintf("login-%s", name)
+
+	profile := iterm.NewProfile(name, config)
+	profile.KeyboardMap["0x61-0x80000"] = iterm.KeyboardMap{
+		Action: 28,
+		Text:   login,
+	}
+	p.Add(*profile)
+
+	config["Command"] = provider.Cmd(vars)
+	p.Add(*iterm.NewProfile(login, config))
+}
+
+// roleName returns the name of the role from its ARN, ie Admin for
+// arn:aws:iam::123456789012:role/path/Admin.
+func roleName(arn string) string {
+	parts := strings.Split(arn, "/")
+
+	return parts[len(parts)-1]
+}
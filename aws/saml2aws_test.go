@@ -0,0 +1,84 @@
+package aws
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaml2AWSProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "saml2aws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "saml2aws")
+	err = ioutil.WriteFile(path, []byte(heredoc.Doc(`
+		[work]
+		url         = https://id.example.com
+		aws_profile = work
+
+		[personal]
+		url = https://id.example.org
+	`)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Saml2AWS: map[string][]string{
+			"work": {
+				"arn:aws:iam::123456789012:role/Admin",
+			},
+		},
+	}
+
+	var cases = []struct {
+		name    string
+		guid    string
+		command string
+		login   string
+	}{
+		{
+			name:    "idp account session profile",
+			guid:    "saml2aws-work",
+			command: "AWS_PROFILE=work ",
+			login:   "login-saml2aws-work",
+		},
+		{
+			name:    "default aws profile",
+			guid:    "saml2aws-personal",
+			command: "AWS_PROFILE=saml ",
+			login:   "login-saml2aws-personal",
+		},
+		{
+			name:    "role from the germ config",
+			guid:    "saml2aws-work-Admin",
+			command: "AWS_PROFILE=work-Admin ",
+			login:   "login-saml2aws-work-Admin",
+		},
+	}
+
+	prof := iterm.Profiles{
+		Profiles: Saml2AWSProfiles(path, cfg),
+	}
+
+	assert.Equal(t, 6, len(prof.Profiles))
+
+	for _, test := range cases {
+		profile, found := prof.FindGUID(test.guid)
+		assert.True(t, found, test.name)
+		assert.Contains(t, profile.Command, test.command, test.name)
+		assert.Equal(t, test.login, profile.KeyboardMap["0x61-0x80000"].Text, test.name)
+
+		_, found = prof.FindGUID(test.login)
+		assert.True(t, found, test.name)
+	}
+}
@@ -0,0 +1,158 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Ping is the SSM agent status of an instance.
+type Ping struct {
+	Status string
+	Last   time.Time
+}
+
+// PingStatuses returns the SSM agent status of the instances, by instance
+// id. Instances that are not registered with SSM are missing.
+func PingStatuses(profile, region string, ids []string) (map[string]Ping, error) {
+	args := []string{
+		"ssm", "describe-instance-information",
+		"--filters", "Key=InstanceIds,Values=" + strings.Join(ids, ","),
+		"--query", "InstanceInformationList[].[InstanceId, PingStatus, LastPingDateTime]",
+		"--output", "json",
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	out, err := runAWS(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var instances [][]interface{}
+	err = json.Unmarshal(out, &instances)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse instance information")
+	}
+
+	var ret = map[string]Ping{}
+	for _, instance := range instances {
+		if len(instance) != 3 {
+			continue
+		}
+
+		id, _ := instance[0].(string)
+		status, _ := instance[1].(string)
+		ret[id] = Ping{Status: status, Last: pingTime(instance[2])}
+	}
+
+	return ret, nil
+}
+
+// pingTime parses the LastPingDateTime, an ISO date with the aws cli v2 and
+// seconds since the epoch with v1.
+func pingTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Unix(int64(seconds), 0)
+		}
+	case float64:
+		return time.Unix(int64(v), 0)
+	}
+
+	return time.Time{}
+}
+
+// authorizeKey is the script that appends the public key to the
+// authorized_keys of the user, once. It fails for unknown users.
+const authorizeKey = `set -e
+home=$(getent passwd %[1]s | cut -d: -f6)
+[ -n "$home" ] || exit 1
+mkdir -p "$home/.ssh"
+grep -qxF '%[2]s' "$home/.ssh/authorized_keys" 2>/dev/null || echo '%[2]s' >> "$home/.ssh/authorized_keys"
+chown -R %[1]s: "$home/.ssh"
+chmod 700 "$home/.ssh"
+chmod 600 "$home/.ssh/authorized_keys"`
+
+// validUser is a POSIX user name, safe to use in the authorizeKey script.
+var validUser = regexp.MustCompile(`^[a-z_][a-z0-9_.-]*$`)
+
+// PushKey appends the public key to the authorized_keys of the user of the
+// instance with SSM SendCommand, and waits for the command to finish.
+func PushKey(profile, region, id, user, key string) error {
+	key = strings.TrimSpace(key)
+	if strings.ContainsAny(key, "'\n") || !validUser.MatchString(user) {
+		return errors.New("invalid public key or user")
+	}
+
+	parameters, err := json.Marshal(map[string][]string{
+		"commands": {fmt.Sprintf(authorizeKey, user, key)},
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal the command")
+	}
+
+	var common []string
+	if profile != "" {
+		common = append(common, "--profile", profile)
+	}
+	if region != "" {
+		common = append(common, "--region", region)
+	}
+
+	out, err := runAWS(append([]string{
+		"ssm", "send-command",
+		"--instance-ids", id,
+		"--document-name", "AWS-RunShellScript",
+		"--comment", "germ push-key",
+		"--parameters", string(parameters),
+		"--query", "Command.CommandId",
+		"--output", "text",
+	}, common...)...)
+	if err != nil {
+		return err
+	}
+
+	_, err = runAWS(append([]string{
+		"ssm", "wait", "command-executed",
+		"--command-id", strings.TrimSpace(string(out)),
+		"--instance-id", id,
+	}, common...)...)
+
+	return err
+}
+
+// SendSSHPublicKey pushes the public key to the instance with EC2 Instance
+// Connect, for the next 60 seconds.
+func SendSSHPublicKey(profile, region, id, user, key string) error {
+	args := []string{
+		"ec2-instance-connect", "send-ssh-public-key",
+		"--instance-id", id,
+		"--instance-os-user", user,
+		"--ssh-public-key", strings.TrimSpace(key),
+	}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	_, err := runAWS(args...)
+
+	return err
+}
@@ -0,0 +1,49 @@
+package aws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPingStatuses(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	runAWS = func(args ...string) ([]byte, error) {
+		return []byte(`[
+			["i-0123", "Online", "2024-03-01T10:00:00.123000+00:00"],
+			["i-4567", "ConnectionLost", 1709200800.5]
+		]`), nil
+	}
+
+	pings, err := PingStatuses("prod", "eu-west-1", []string{"i-0123", "i-4567"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Online", pings["i-0123"].Status)
+	assert.True(t, pings["i-0123"].Last.Equal(time.Date(2024, 3, 1, 10, 0, 0, 123000000, time.UTC)))
+	assert.Equal(t, "ConnectionLost", pings["i-4567"].Status)
+	assert.Equal(t, int64(1709200800), pings["i-4567"].Last.Unix())
+}
+
+func TestPushKey(t *testing.T) {
+	defer func(run func(...string) ([]byte, error)) { runAWS = run }(runAWS)
+
+	var calls [][]string
+	runAWS = func(args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		return []byte("cmd-1\n"), nil
+	}
+
+	assert.Nil(t, PushKey("prod", "", "i-0123", "ec2-user", "ssh-ed25519 AAAA user@host\n"))
+	assert.Equal(t, 2, len(calls))
+	assert.Contains(t, calls[0][9], `grep -qxF 'ssh-ed25519 AAAA user@host'`)
+	assert.Equal(t, []string{"ssm", "wait", "command-executed", "--command-id", "cmd-1", "--instance-id", "i-0123", "--profile", "prod"}, calls[1])
+
+	assert.NotNil(t, PushKey("prod", "", "i-0123", "ec2-user", "ssh-ed25519 AAAA 'quoted'"))
+	assert.Contains(t, calls[0][9], `[ -n \"$home\" ] || exit 1`)
+
+	for _, user := range []string{"root; rm", "a|reboot", "a`id`", "$(id)", "-root", ""} {
+		assert.NotNil(t, PushKey("prod", "", "i-0123", user, "ssh-ed25519 AAAA"), user)
+	}
+	assert.Equal(t, 2, len(calls))
+}
@@ -0,0 +1,277 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/plugin"
+	"github.com/pkg/errors"
+)
+
+var client = &http.Client{
+	Timeout: time.Minute,
+}
+
+// Name returns the name of the bundle, defaulting to its file name without
+// the extension.
+func Name(b config.Bundle) string {
+	if b.Name != "" {
+		return b.Name
+	}
+
+	file := path.Base(b.URL)
+	if b.URL == "" {
+		file = path.Base(bundlePath(b))
+	}
+
+	return strings.TrimSuffix(file, path.Ext(file))
+}
+
+// Profiles fetches the bundle and returns its profiles. Unreachable bundles
+// use their cached copy. Bundles with an invalid signature, or unsigned
+// ones, are refused unless allowUnsigned is set, since they can run any
+// command in the terminals.
+func Profiles(b config.Bundle, allowUnsigned bool) []iterm.Profile {
+	data, signature, err := Fetch(b)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": Name(b),
+			"err":    err,
+		}).Warn("Cannot fetch bundle")
+		return nil
+	}
+
+	switch {
+	case b.Signature != nil:
+		err = Verify(b.Signature, data, signature)
+	case !allowUnsigned:
+		err = errors.New("bundle is not signed")
+	}
+
+	if err != nil && !allowUnsigned {
+		log.WithFields(log.Fields{
+			"bundle": Name(b),
+			"err":    err,
+		}).Warn("Refusing bundle, see --allow-unsigned")
+		return nil
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": Name(b),
+			"err":    err,
+		}).Warn("Merging bundle with an invalid signature")
+	}
+
+	profiles, err := plugin.Parse(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"bundle": Name(b),
+			"err":    err,
+		}).Warn("Invalid bundle")
+		return nil
+	}
+
+	return profiles
+}
+
+// Fetch returns the contents of the bundle, and of its signature for signed
+// bundles.
+func Fetch(b config.Bundle) ([]byte, []byte, error) {
+	var files []string
+	switch {
+	case b.URL != "":
+		files = []string{b.URL}
+	case b.Git != "":
+		files = []string{bundlePath(b)}
+	default:
+		return nil, nil, errors.New("bundle has neither url nor git")
+	}
+
+	if b.Signature != nil {
+		files = append(files, files[0]+signatureSuffix(b.Signature))
+	}
+
+	var contents [][]byte
+	var err error
+	if b.URL != "" {
+		contents, err = fetchURLs(files)
+	} else {
+		contents, err = fetchGit(b, files)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(contents) == 1 {
+		return contents[0], nil, nil
+	}
+
+	return contents[0], contents[1], nil
+}
+
+func fetchURLs(urls []string) ([][]byte, error) {
+	var ret [][]byte
+
+	for _, url := range urls {
+		data, err := fetchURL(url)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, data)
+	}
+
+	return ret, nil
+}
+
+// urlCache is the last response of a bundle URL.
+type urlCache struct {
+	ETag string `json:"etag"`
+	Data []byte `json:"data"`
+}
+
+// fetchURL downloads the bundle, unless the ETag of the cached copy is
+// still valid.
+func fetchURL(url string) ([]byte, error) {
+	name := fmt.Sprintf("bundle-%x.json", sha256.Sum256([]byte(url)))
+
+	var cached urlCache
+	if data, _, err := cache.Read(name); err == nil {
+		if err := json.Unmarshal(data, &cached); err != nil {
+			cached = urlCache{}
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fallback(cached.Data, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if cached.Data != nil {
+			return cached.Data, nil
+		}
+	case http.StatusOK:
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return fallback(cached.Data, err)
+		}
+
+		out, err := json.Marshal(urlCache{ETag: resp.Header.Get("ETag"), Data: data})
+		if err == nil {
+			err = cache.Write(name, out)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"url": url,
+				"err": err,
+			}).Warn("Cannot cache bundle")
+		}
+
+		return data, nil
+	}
+
+	return fallback(cached.Data, errors.Errorf("unexpected status %s from %s", resp.Status, url))
+}
+
+// fetchGit clones the repository in the cache, or pulls it if it is already
+// there, and reads the files. A failed update reads the previous checkout.
+func fetchGit(b config.Bundle, files []string) ([][]byte, error) {
+	dir := filepath.Join(cache.Dir(), "bundles", fmt.Sprintf("%x", sha256.Sum256([]byte(b.Git+"#"+b.Ref))))
+
+	var err error
+	if _, statErr := os.Stat(dir); os.IsNotExist(statErr) {
+		args := []string{"clone", "--quiet", "--depth", "1"}
+		if b.Ref != "" {
+			args = append(args, "--branch", b.Ref)
+		}
+
+		err = git(append(args, b.Git, dir)...)
+	} else {
+		err = git("-C", dir, "pull", "--quiet", "--ff-only")
+	}
+
+	var ret [][]byte
+	for _, file := range files {
+		data, readErr := ioutil.ReadFile(filepath.Join(dir, file))
+		if readErr != nil {
+			if err != nil {
+				return nil, err
+			}
+
+			return nil, readErr
+		}
+
+		ret = append(ret, data)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"git": b.Git,
+			"err": err,
+		}).Warn("Cannot update bundle, using the cached copy")
+	}
+
+	return ret, nil
+}
+
+func bundlePath(b config.Bundle) string {
+	if b.Path == "" {
+		return "profiles.json"
+	}
+
+	return b.Path
+}
+
+func git(args ...string) error {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "git %s: %s", args[0], strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// fallback returns the cached bundle when the update failed, or the error if
+// there is no cached copy.
+func fallback(cached []byte, err error) ([]byte, error) {
+	if err == nil {
+		return cached, nil
+	}
+
+	if cached == nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{
+		"err": err,
+	}).Warn("Cannot update bundle, using the cached copy")
+
+	return cached, nil
+}
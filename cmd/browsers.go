@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/pkg/errors"
+)
+
+// account is an AWS account of the generated profiles.
+type account struct {
+	ID    string
+	Alias string
+	// AWSProfiles are the AWS profiles into the account.
+	AWSProfiles []string
+	Regions     []string
+}
+
+// Name is the alias of the account, or its ID.
+func (a *account) Name() string {
+	if a.Alias != "" {
+		return a.Alias
+	}
+
+	return a.ID
+}
+
+// accounts returns the AWS accounts of the profiles, sorted by ID.
+func accounts(profiles []iterm.Profile, aliases map[string]string) []account {
+	var byID = map[string]*account{}
+
+	for i := range profiles {
+		profile := &profiles[i]
+
+		id := profile.AccountID()
+		if id == "" {
+			continue
+		}
+
+		a, found := byID[id]
+		if !found {
+			a = &account{ID: id, Alias: aliases[id]}
+			byID[id] = a
+		}
+
+		vars := profile.TriggerVariables()
+		if vars.AWSProfile != "" && !contains(a.AWSProfiles, vars.AWSProfile) {
+			a.AWSProfiles = append(a.AWSProfiles, vars.AWSProfile)
+		}
+
+		if vars.Region != "" && !contains(a.Regions, vars.Region) {
+			a.Regions = append(a.Regions, vars.Region)
+		}
+	}
+
+	var ret []account
+	for _, a := range byID {
+		sort.Strings(a.AWSProfiles)
+		sort.Strings(a.Regions)
+		ret = append(ret, *a)
+	}
+
+	sort.Slice(ret, func(i, j int) bool { return ret[i].ID < ret[j].ID })
+
+	return ret
+}
+
+// browser returns the browser of the account, matched by ID and then by
+// alias.
+func browser(browsers *config.Browsers, a account) (config.Browser, bool) {
+	patterns := config.Keys(browsers.Accounts)
+
+	if pattern, found := config.MatchKey(patterns, a.ID); found {
+		return browsers.Accounts[pattern], true
+	}
+
+	if pattern, found := config.MatchKey(patterns, a.Alias); found && a.Alias != "" {
+		return browsers.Accounts[pattern], true
+	}
+
+	return config.Browser{}, false
+}
+
+// writeFinicky writes a Finicky config that opens the AWS console URLs of
+// every account in its browser profile.
+func writeFinicky(w io.Writer, accounts []account, browsers *config.Browsers) error {
+	if browsers == nil {
+		return errors.New("no browsers in the config")
+	}
+
+	defaultBrowser := browsers.Default
+	if defaultBrowser == "" {
+		defaultBrowser = "Safari"
+	}
+
+	fmt.Fprintln(w, "// Generated by 'germ export --format finicky', do not edit.")
+	fmt.Fprintln(w, "module.exports = {")
+	fmt.Fprintf(w, "  defaultBrowser: %q,\n", defaultBrowser)
+	fmt.Fprintln(w, "  handlers: [")
+
+	for _, a := range accounts {
+		b, found := browser(browsers, a)
+		if !found {
+			continue
+		}
+
+		fmt.Fprintln(w, "    {")
+		fmt.Fprintf(w, "      // %s\n", a.Name())
+		fmt.Fprintf(w, "      match: /account_id=%[1]s|%[1]s\\.signin\\.aws\\.amazon\\.com/,\n", a.ID)
+		if b.Profile == "" {
+			fmt.Fprintf(w, "      browser: %q,\n", b.Name)
+		} else {
+			fmt.Fprintf(w, "      browser: { name: %q, profile: %q },\n", b.Name, b.Profile)
+		}
+		fmt.Fprintln(w, "    },")
+	}
+
+	fmt.Fprintln(w, "  ],")
+	_, err := fmt.Fprintln(w, "};")
+
+	return err
+}
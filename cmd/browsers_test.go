@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccounts(t *testing.T) {
+	profiles := []iterm.Profile{
+		*iterm.NewProfile("prod-eu-west-1", map[string]string{"Tags": "account=123456789012,aws-profile=prod,region=eu-west-1"}),
+		*iterm.NewProfile("prod-admin", map[string]string{"Tags": "account=123456789012,aws-profile=prod-admin,region=eu-west-1"}),
+		*iterm.NewProfile("dev", map[string]string{"Tags": "account=210987654321,aws-profile=dev"}),
+		*iterm.NewProfile("local", map[string]string{}),
+	}
+
+	assert.Equal(t, []account{
+		{ID: "123456789012", Alias: "prod", AWSProfiles: []string{"prod", "prod-admin"}, Regions: []string{"eu-west-1"}},
+		{ID: "210987654321", AWSProfiles: []string{"dev"}},
+	}, accounts(profiles, map[string]string{"123456789012": "prod"}))
+}
+
+func TestWriteFinicky(t *testing.T) {
+	all := []account{
+		{ID: "123456789012", Alias: "customer-a-prod"},
+		{ID: "210987654321"},
+		{ID: "999999999999"},
+	}
+
+	var out bytes.Buffer
+	assert.Nil(t, writeFinicky(&out, all, &config.Browsers{
+		Accounts: map[string]config.Browser{
+			"customer-a-*": {Name: "Google Chrome", Profile: "Customer A"},
+			"210987654321": {Name: "Firefox"},
+		},
+	}))
+
+	assert.Equal(t, heredoc.Doc(`
+		// Generated by 'germ export --format finicky', do not edit.
+		module.exports = {
+		  defaultBrowser: "Safari",
+		  handlers: [
+		    {
+		      // customer-a-prod
+		      match: /account_id=123456789012|123456789012\.signin\.aws\.amazon\.com/,
+		      browser: { name: "Google Chrome", profile: "Customer A" },
+		    },
+		    {
+		      // 210987654321
+		      match: /account_id=210987654321|210987654321\.signin\.aws\.amazon\.com/,
+		      browser: "Firefox",
+		    },
+		  ],
+		};
+	`), out.String())
+
+	assert.NotNil(t, writeFinicky(&out, all, nil))
+}
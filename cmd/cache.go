@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clear the germ caches",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the caches with their size and age",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		entries, err := cache.List()
+		if err != nil {
+			log.WithFields(log.Fields{
+				"dir": cache.Dir(),
+				"err": err,
+			}).Fatal("Cannot list caches")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, entry := range entries {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", entry.Name, entry.Size, time.Since(entry.ModTime).Round(time.Second))
+		}
+		w.Flush()
+	},
+}
+
+var cacheShowCmd = &cobra.Command{
+	Use:               "show NAME",
+	Short:             "Print the contents of a cache",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCaches,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		data, age, err := cache.Read(args[0])
+		if err != nil {
+			log.WithFields(log.Fields{
+				"name": args[0],
+				"err":  err,
+			}).Fatal("Cannot read cache")
+		}
+
+		fmt.Fprintf(os.Stderr, "%s, updated %s ago\n", cache.Path(args[0]), age.Round(time.Second))
+		fmt.Println(string(data))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:               "clear [NAME...]",
+	Short:             "Remove the given caches, or all of them",
+	ValidArgsFunction: completeCaches,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		if dryRun {
+			fmt.Println("Would clear", cache.Dir(), args)
+			return
+		}
+
+		err := cache.Clear(args...)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"names": args,
+				"err":   err,
+			}).Fatal("Cannot clear cache")
+		}
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheShowCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
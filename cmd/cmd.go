@@ -4,16 +4,24 @@ import (
 	"bytes"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
-var command string
+var (
+	command      string
+	commandVars  map[string]string
+	outputFormat string
+)
 
 var cmdCmd = &cobra.Command{
 	Use:   "cmd",
@@ -21,82 +29,228 @@ var cmdCmd = &cobra.Command{
 	Long: heredoc.Doc(
 		`Command variables are:
 		    {{ .Profile }} will be replaced with the current profile
-			{{ .Region }} If this is present, the command will be executed in all AWS regions. Warning, this is whitespace sensitive
+		    {{ .Region }} If this is present, the command will be executed in all AWS regions. Warning, this is whitespace sensitive
+		    {{ .AccountID }} the AWS account ID of the profile, if known
+		    {{ .Alias }} the account alias from the 'accountAliases' config
+		    {{ .Partition }} the AWS partition, ie aws or aws-cn
+		    {{ .Vars.key }} values given with --var key=value
+
+		The helpers upper, lower, title, trim, trimPrefix, trimSuffix, replace,
+		contains, default, split, join and quote are also available, for example
+
+		    {{ .Alias | default .Profile | upper }}
 		`,
 	),
 	Run: func(cmd *cobra.Command, args []string) {
 		Verbose(cmd)
 
-		var prof = iterm.Profiles{
-			Profiles: aws.Profiles("prefix", AWSConfig),
+		// only the login settings are relevant, the region profiles are
+		// not real AWS profiles.
+		loginCfg := &config.Config{
+			Login: cfg.Login,
+		}
+
+		var prof iterm.Profiles
+		prof.Profiles = append(prof.Profiles, aws.Profiles("", AWSConfig, loginCfg)...)
+		prof.Profiles = append(prof.Profiles, aws.CredentialProfiles("", AWSCredentials, AWSConfig, loginCfg)...)
+
+		for _, orphan := range prof.Orphans() {
+			log.WithFields(log.Fields{
+				"profile": orphan,
+			}).Warn("Source profile not found")
+		}
+
+		sources, err := sourceCommands(prof, command, commandVars)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"command": command,
+				"err":     err,
+			}).Fatal("Cannot generate commands")
 		}
 
-		fmt.Println(strings.Join(generateCommands(prof, command), "\n"))
+		out, err := renderCommands(outputFormat, sources)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"output-format": outputFormat,
+				"err":           err,
+			}).Fatal("Cannot render commands")
+		}
+
+		fmt.Print(out)
 	},
 }
 
-func generateCommands(prof iterm.Profiles, command string) []string {
-	var ret []string
+func generateCommands(prof iterm.Profiles, command string, vars map[string]string) ([]string, error) {
+	sources, err := sourceCommands(prof, command, vars)
+	if err != nil {
+		return nil, err
+	}
 
-	for source, profiles := range prof.ProfileTree() {
-		login := false
-		for _, profile := range profiles {
-			if !login {
-				loginGUID := fmt.Sprintf("login-%s", source)
-				iProfile, found := prof.FindGUID(loginGUID)
-				if !found {
-					panic(loginGUID)
-				}
-
-				ret = append(ret, strings.Replace(iProfile.Command, " || sleep 60'", "'", -1))
-				login = true
-			}
+	return flatten(sources), nil
+}
+
+// sourceCmds are the commands of the profiles sharing a source profile.
+type sourceCmds struct {
+	Source   string
+	Login    string
+	Profiles []profileCmds
+}
+
+type profileCmds struct {
+	Profile  string
+	Commands []string
+}
+
+func sourceCommands(prof iterm.Profiles, command string, vars map[string]string) ([]sourceCmds, error) {
+	var ret []sourceCmds
+
+	tree := prof.ProfileTree()
+
+	var sources []string
+	for source := range tree {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	for _, source := range sources {
+		this := sourceCmds{
+			Source: source,
+		}
+
+		login, err := prof.LoginProfile(source)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"source": source,
+				"err":    err,
+			}).Debug("Skipping login command")
+		} else {
+			this.Login = login.Launch().Command
+		}
+
+		for _, guid := range tree[source] {
+			profile, _ := prof.FindGUID(guid)
+
+			id := profileAccountID(profile)
+			alias, _ := config.Lookup(cfg.AccountAliases, id)
 
 			tCommand := fmt.Sprintf("AWS_PROFILE={{ .Profile }} %s", command)
-			str := generateTemplate(tCommand, profile)
-			ret = append(ret, str...)
+			str, err := generateTemplate(tCommand, cmdVars{
+				Profile:   guid,
+				AccountID: id,
+				Alias:     alias,
+				Vars:      vars,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			this.Profiles = append(this.Profiles, profileCmds{
+				Profile:  guid,
+				Commands: str,
+			})
 		}
+
+		ret = append(ret, this)
 	}
-	return ret
+
+	return ret, nil
+}
+
+// profileAccountID returns the AWS account ID from the tags of the profile.
+func profileAccountID(profile iterm.Profile) string {
+	return profile.AccountID()
+}
+
+// partition returns the AWS partition of the region.
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+
+	return "aws"
+}
+
+type cmdVars struct {
+	Profile   string
+	Region    string
+	AccountID string
+	Alias     string
+	Partition string
+	Vars      map[string]string
+}
+
+var cmdFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"title": strings.Title,
+	"trim":  strings.TrimSpace,
+	"trimPrefix": func(prefix, s string) string {
+		return strings.TrimPrefix(s, prefix)
+	},
+	"trimSuffix": func(suffix, s string) string {
+		return strings.TrimSuffix(s, suffix)
+	},
+	"replace": func(old, new, s string) string {
+		return strings.Replace(s, old, new, -1)
+	},
+	"contains": func(substr, s string) bool {
+		return strings.Contains(s, substr)
+	},
+	"default": func(def, s string) string {
+		if s == "" {
+			return def
+		}
+		return s
+	},
+	"split": func(sep, s string) []string {
+		return strings.Split(s, sep)
+	},
+	"join": func(sep string, s []string) string {
+		return strings.Join(s, sep)
+	},
+	"quote": func(s string) string {
+		return fmt.Sprintf("%q", s)
+	},
 }
 
-func generateTemplate(command, profile string) []string {
+func generateTemplate(command string, vars cmdVars) ([]string, error) {
 	var ret []string
 
-	t, err := template.New(profile).Parse(command)
+	t, err := template.New(vars.Profile).Funcs(cmdFuncs).Option("missingkey=zero").Parse(command)
 	if err != nil {
-		panic(err)
+		return nil, errors.Wrap(err, "cannot parse command template")
 	}
 
+	regions := []string{vars.Region}
+
 	regexRegion := regexp.MustCompile(`{{\s*\.Region\s*}}`)
 	if regexRegion.MatchString(command) {
-		for _, region := range aws.Regions() {
-			var tpl bytes.Buffer
-			err = t.Execute(&tpl, struct {
-				Profile string
-				Region  string
-			}{
-				Profile: profile,
-				Region:  region,
-			})
+		regions = aws.Regions()
+	}
+
+	for _, region := range regions {
+		vars.Region = region
+		vars.Partition = partition(region)
 
-			ret = append(ret, tpl.String())
-		}
-	} else {
 		var tpl bytes.Buffer
-		err = t.Execute(&tpl, struct {
-			Profile string
-		}{
-			Profile: profile,
-		})
+		err = t.Execute(&tpl, vars)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot render command template")
+		}
+
 		ret = append(ret, tpl.String())
 	}
 
-	return ret
+	return ret, nil
 }
 
 func init() {
 	cmdCmd.Flags().StringVarP(&command, "cmd", "", "aws s3 ls", "command to run")
+	cmdCmd.Flags().StringVarP(&outputFormat, "output-format", "f", "plain", "Output format, one of plain, script, makefile or matrix")
+	cmdCmd.Flags().StringToStringVarP(&commandVars, "var", "", map[string]string{}, "Extra variables for the command template, as key=value")
 
 	rootCmd.AddCommand(cmdCmd)
 }
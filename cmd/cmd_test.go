@@ -59,8 +59,13 @@ func TestGenerateTemplate(t *testing.T) {
 	}
 
 	for _, test := range cases {
-		assert.Equal(t, generateTemplate(test.command, test.profile), test.out, test.name)
+		out, err := generateTemplate(test.command, cmdVars{Profile: test.profile})
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, out, test.out, test.name)
 	}
+
+	_, err := generateTemplate("{{ .Missing", cmdVars{Profile: "foo"})
+	assert.NotNil(t, err)
 }
 
 func TestGenerateCommands(t *testing.T) {
@@ -128,7 +133,46 @@ func TestGenerateCommands(t *testing.T) {
 			},
 		},
 		{
-			name:    "login command with sleep at the end",
+			name:    "missing login profile",
+			command: "aws s3 ls",
+			profiles: iterm.Profiles{
+				Profiles: []iterm.Profile{
+					iterm.Profile{
+						GUID: "parent",
+					},
+					iterm.Profile{
+						GUID: "child",
+						Tags: []string{
+							"source-profile=parent",
+						},
+					},
+				},
+			},
+			out: []string{
+				"AWS_PROFILE=child aws s3 ls",
+			},
+		},
+		{
+			name:    "sso profiles without source profile",
+			command: "aws s3 ls",
+			profiles: iterm.Profiles{
+				Profiles: []iterm.Profile{
+					iterm.Profile{
+						GUID: "sso",
+					},
+					iterm.Profile{
+						GUID:    "login-sso",
+						Command: "sso-login",
+					},
+				},
+			},
+			out: []string{
+				"sso-login",
+				"AWS_PROFILE=sso aws s3 ls",
+			},
+		},
+		{
+			name:    "login command with a shell on failure",
 			command: "aws s3 ls",
 			profiles: iterm.Profiles{
 				Profiles: []iterm.Profile{
@@ -137,7 +181,7 @@ func TestGenerateCommands(t *testing.T) {
 					},
 					iterm.Profile{
 						GUID:    "login-parent",
-						Command: "bash -c 'login-command || sleep 60'",
+						Command: `bash -c 'bash -c '\''login-command'\'' || exec "$SHELL" -l'`,
 					},
 					iterm.Profile{
 						GUID: "child",
@@ -155,7 +199,84 @@ func TestGenerateCommands(t *testing.T) {
 	}
 
 	for _, test := range cases {
-		assert.Equal(t, generateCommands(test.profiles, test.command), test.out, test.name)
+		out, err := generateCommands(test.profiles, test.command, map[string]string{})
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, out, test.out, test.name)
+	}
+}
+
+func TestGenerateTemplateVars(t *testing.T) {
+	var cases = []struct {
+		name    string
+		command string
+		vars    cmdVars
+		out     []string
+	}{
+		{
+			name:    "account variables",
+			command: "echo {{ .AccountID }} {{ .Alias }} {{ .Partition }}",
+			vars: cmdVars{
+				Profile:   "foo",
+				AccountID: "123456789012",
+				Alias:     "prod",
+			},
+			out: []string{"echo 123456789012 prod aws"},
+		},
+		{
+			name:    "user variables",
+			command: "echo {{ .Vars.bucket }}",
+			vars: cmdVars{
+				Profile: "foo",
+				Vars: map[string]string{
+					"bucket": "logs",
+				},
+			},
+			out: []string{"echo logs"},
+		},
+		{
+			name:    "helpers",
+			command: `echo {{ .Alias | default .Profile | upper }} {{ .Profile | replace "-" "_" }}`,
+			vars: cmdVars{
+				Profile: "foo-bar",
+			},
+			out: []string{"echo FOO-BAR foo_bar"},
+		},
+	}
+
+	for _, test := range cases {
+		out, err := generateTemplate(test.command, test.vars)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
 
+func TestProfileAccountID(t *testing.T) {
+	var cases = []struct {
+		name    string
+		profile iterm.Profile
+		out     string
+	}{
+		{
+			name: "sso account tag",
+			profile: iterm.Profile{
+				Tags: []string{"account=123456789012"},
+			},
+			out: "123456789012",
+		},
+		{
+			name: "account from the role arn",
+			profile: iterm.Profile{
+				Tags: []string{"source-profile=root", "root", "210987654321"},
+			},
+			out: "210987654321",
+		},
+		{
+			name:    "unknown account",
+			profile: iterm.Profile{},
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.out, profileAccountID(test.profile), test.name)
 	}
 }
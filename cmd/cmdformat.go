@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// renderCommands renders the commands in the given output format.
+func renderCommands(format string, sources []sourceCmds) (string, error) {
+	switch format {
+	case "plain":
+		return renderPlain(sources), nil
+	case "script":
+		return renderScript(sources), nil
+	case "makefile":
+		return renderMakefile(sources), nil
+	case "matrix":
+		return renderMatrix(sources)
+	}
+
+	return "", fmt.Errorf("unknown output format %s", format)
+}
+
+func renderPlain(sources []sourceCmds) string {
+	return strings.Join(flatten(sources), "\n") + "\n"
+}
+
+// flatten returns the login command of every source followed by the
+// commands of its profiles.
+func flatten(sources []sourceCmds) []string {
+	var ret []string
+
+	for _, source := range sources {
+		if source.Login != "" {
+			ret = append(ret, source.Login)
+		}
+
+		for _, profile := range source.Profiles {
+			ret = append(ret, profile.Commands...)
+		}
+	}
+
+	return ret
+}
+
+// renderScript renders a bash script that keeps going when a command fails
+// and exits with an error if any of them did.
+func renderScript(sources []sourceCmds) string {
+	var b strings.Builder
+
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString("# generated by germ cmd\n")
+	b.WriteString("set -uo pipefail\n\n")
+	b.WriteString("failed=0\n")
+
+	for _, source := range sources {
+		indent := ""
+
+		fmt.Fprintf(&b, "\n# %s\n", source.Source)
+		if source.Login != "" {
+			fmt.Fprintf(&b, "if %s; then\n", source.Login)
+			indent = "    "
+		}
+
+		for _, profile := range source.Profiles {
+			for _, command := range profile.Commands {
+				fmt.Fprintf(&b, "%s%s || { echo \"germ: command failed for %s\" >&2; failed=1; }\n", indent, command, profile.Profile)
+			}
+		}
+
+		if source.Login != "" {
+			b.WriteString("else\n")
+			fmt.Fprintf(&b, "    echo \"germ: login failed for %s\" >&2\n", source.Source)
+			b.WriteString("    failed=1\n")
+			b.WriteString("fi\n")
+		}
+	}
+
+	b.WriteString("\nexit $failed\n")
+
+	return b.String()
+}
+
+// renderMakefile renders a Makefile with a target per profile, depending on
+// the login target of its source profile.
+func renderMakefile(sources []sourceCmds) string {
+	var b strings.Builder
+	var targets []string
+
+	for _, source := range sources {
+		for _, profile := range source.Profiles {
+			targets = append(targets, profile.Profile)
+		}
+	}
+
+	b.WriteString("# generated by germ cmd\n\n")
+	fmt.Fprintf(&b, "all: %s\n", strings.Join(targets, " "))
+	b.WriteString(".PHONY: all\n")
+
+	for _, source := range sources {
+		login := ""
+
+		if source.Login != "" {
+			login = fmt.Sprintf("login-%s", source.Source)
+
+			fmt.Fprintf(&b, "\n%s:\n", login)
+			fmt.Fprintf(&b, "\t%s\n", makeEscape(source.Login))
+			fmt.Fprintf(&b, ".PHONY: %s\n", login)
+		}
+
+		for _, profile := range source.Profiles {
+			fmt.Fprintf(&b, "\n%s: %s\n", profile.Profile, login)
+			for _, command := range profile.Commands {
+				fmt.Fprintf(&b, "\t%s\n", makeEscape(command))
+			}
+			fmt.Fprintf(&b, ".PHONY: %s\n", profile.Profile)
+		}
+	}
+
+	return b.String()
+}
+
+func makeEscape(command string) string {
+	return strings.Replace(command, "$", "$$", -1)
+}
+
+type matrixEntry struct {
+	Source  string `json:"source"`
+	Profile string `json:"profile"`
+	Login   string `json:"login,omitempty"`
+	Command string `json:"command"`
+}
+
+// renderMatrix renders a CI matrix, ie for GitHub actions
+// `strategy.matrix: ${{ fromJson(...) }}`, with an entry per command.
+func renderMatrix(sources []sourceCmds) (string, error) {
+	var matrix = struct {
+		Include []matrixEntry `json:"include"`
+	}{
+		Include: []matrixEntry{},
+	}
+
+	for _, source := range sources {
+		for _, profile := range source.Profiles {
+			for _, command := range profile.Commands {
+				matrix.Include = append(matrix.Include, matrixEntry{
+					Source:  source.Source,
+					Profile: profile.Profile,
+					Login:   source.Login,
+					Command: command,
+				})
+			}
+		}
+	}
+
+	out, err := json.MarshalIndent(matrix, "", "    ")
+	if err != nil {
+		return "", errors.Wrap(err, "cannot marshal matrix")
+	}
+
+	return string(out) + "\n", nil
+}
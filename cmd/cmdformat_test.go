@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCommands(t *testing.T) {
+	sources := []sourceCmds{
+		{
+			Source: "parent",
+			Login:  "login-command",
+			Profiles: []profileCmds{
+				{
+					Profile:  "child",
+					Commands: []string{"AWS_PROFILE=child echo $HOME"},
+				},
+			},
+		},
+	}
+
+	var cases = []struct {
+		name   string
+		format string
+		out    string
+	}{
+		{
+			name:   "plain",
+			format: "plain",
+			out: heredoc.Doc(`
+				login-command
+				AWS_PROFILE=child echo $HOME
+			`),
+		},
+		{
+			name:   "script",
+			format: "script",
+			out: heredoc.Doc(`
+				#!/usr/bin/env bash
+				# generated by germ cmd
+				set -uo pipefail
+
+				failed=0
+
+				# parent
+				if login-command; then
+				    AWS_PROFILE=child echo $HOME || { echo "germ: command failed for child" >&2; failed=1; }
+				else
+				    echo "germ: login failed for parent" >&2
+				    failed=1
+				fi
+
+				exit $failed
+			`),
+		},
+		{
+			name:   "makefile",
+			format: "makefile",
+			out: "# generated by germ cmd\n\n" +
+				"all: child\n" +
+				".PHONY: all\n\n" +
+				"login-parent:\n" +
+				"\tlogin-command\n" +
+				".PHONY: login-parent\n\n" +
+				"child: login-parent\n" +
+				"\tAWS_PROFILE=child echo $$HOME\n" +
+				".PHONY: child\n",
+		},
+		{
+			name:   "matrix",
+			format: "matrix",
+			out: heredoc.Doc(`
+				{
+				    "include": [
+				        {
+				            "source": "parent",
+				            "profile": "child",
+				            "login": "login-command",
+				            "command": "AWS_PROFILE=child echo $HOME"
+				        }
+				    ]
+				}
+			`),
+		},
+	}
+
+	for _, test := range cases {
+		out, err := renderCommands(test.format, sources)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+
+	_, err := renderCommands("unknown", sources)
+	assert.NotNil(t, err)
+}
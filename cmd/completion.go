@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/spf13/cobra"
+)
+
+// completeSecrets completes the secret names of the keychain service given
+// with --service.
+func completeSecrets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	service, err := cmd.Flags().GetString("service")
+	if err != nil {
+		service = keyChain.Service
+	}
+
+	for _, k := range keyChains() {
+		if k.Service == service {
+			return withPrefix(k.List(), toComplete), cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeSecretArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return completeSecrets(cmd, args, toComplete)
+}
+
+func completeServices(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var services []string
+	for _, k := range keyChains() {
+		services = append(services, k.Service)
+	}
+
+	return withPrefix(services, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeArrangements(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for name := range cfg.Arrangements {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return withPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeWorkspaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return withPrefix(config.Keys(cfg.Workspaces), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeInstances(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	prof, _ := lastProfiles()
+	profiles := prof.Profiles
+	for i := range profiles {
+		if _, found := instance(&profiles[i]); found {
+			names = append(names, profiles[i].Name)
+		}
+	}
+	sort.Strings(names)
+
+	return withPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeAWSProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return withPrefix(aws.ProfileNames(AWSConfig, AWSCredentials), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func withPrefix(values []string, prefix string) []string {
+	var ret []string
+
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			ret = append(ret, value)
+		}
+	}
+
+	return ret
+}
+
+func completeCaches(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	entries, err := cache.List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+
+	return withPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompleteArrangements(t *testing.T) {
+	defer func(c *config.Config) { cfg = c }(cfg)
+
+	cfg = &config.Config{
+		Arrangements: map[string]config.Arrangement{
+			"prod-incident": {},
+			"prod-deploy":   {},
+			"dev":           {},
+		},
+	}
+
+	names, directive := completeArrangements(arrangementCmd, []string{}, "prod")
+	assert.Equal(t, []string{"prod-deploy", "prod-incident"}, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	names, _ = completeArrangements(arrangementCmd, []string{"dev"}, "")
+	assert.Nil(t, names)
+}
+
+func TestCompleteServices(t *testing.T) {
+	defer func(c *config.Config) { cfg = c }(cfg)
+
+	cfg = &config.Config{
+		KeyChains: []config.KeyChain{
+			{Service: "work"},
+		},
+	}
+
+	services, _ := completeServices(newCmd, []string{}, "")
+	assert.Equal(t, []string{"germ", "work"}, services)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check and document the germ configuration",
+}
+
+var configLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Report unknown keys and invalid values in the configuration file",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		path := expandUser(configFile)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"path": path,
+				"err":  err,
+			}).Fatal("Cannot read config file")
+		}
+
+		errs := config.Lint(data)
+		for _, err := range errs {
+			fmt.Println(fmt.Sprintf("%s: %s", path, err))
+		}
+
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+var configDocsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Print the keys of the configuration file with their types",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		fmt.Println(config.Docs())
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configLintCmd)
+	configCmd.AddCommand(configDocsCmd)
+	rootCmd.AddCommand(configCmd)
+}
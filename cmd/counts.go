@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/metrics"
+	"github.com/pkg/errors"
+)
+
+// warnProfiles is the number of profiles of a source that is likely the
+// result of a bad filter, ie an autoscaling group scale out.
+const warnProfiles = 200
+
+// sourceCount is the number of profiles of a source.
+type sourceCount struct {
+	source   string
+	profiles int
+}
+
+// countProfiles records the number of profiles of every source.
+func countProfiles(sources []source, results [][]iterm.Profile) []sourceCount {
+	var ret []sourceCount
+	for i, s := range sources {
+		count := len(results[i])
+		metrics.Default.Add(fmt.Sprintf("profiles_%s", s.name), count)
+
+		ret = append(ret, sourceCount{source: s.name, profiles: count})
+	}
+
+	return ret
+}
+
+// checkCounts warns about the sources with too many profiles and fails when
+// one has more than max, if max is set.
+func checkCounts(counts []sourceCount, max int) error {
+	for _, c := range counts {
+		if max > 0 && c.profiles > max {
+			return errors.Errorf("source %s has %d profiles, more than %d", c.source, c.profiles, max)
+		}
+
+		if c.profiles > warnProfiles {
+			log.WithFields(log.Fields{
+				"source":   c.source,
+				"profiles": c.profiles,
+			}).Warn("Source has too many profiles, use --max-profiles to fail the generation")
+		}
+	}
+
+	return nil
+}
+
+// reportCounts prints the number of profiles of every source.
+func reportCounts(out io.Writer, counts []sourceCount) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, c := range counts {
+		fmt.Fprintf(w, "%s\t%d profiles\n", c.source, c.profiles)
+	}
+	w.Flush()
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountProfiles(t *testing.T) {
+	defer func(r *metrics.Registry) { metrics.Default = r }(metrics.Default)
+	metrics.Default = metrics.New()
+
+	sources := []source{{name: "aws-config"}, {name: "tfstate"}}
+	results := [][]iterm.Profile{
+		{{Name: "dev"}},
+		{{Name: "tf-web-1"}, {Name: "tf-web-2"}, {Name: "tf-web-3"}},
+	}
+
+	counts := countProfiles(sources, results)
+	assert.Equal(t, []sourceCount{{source: "aws-config", profiles: 1}, {source: "tfstate", profiles: 3}}, counts)
+	assert.Equal(t, 1, metrics.Default.Counters["profiles_aws-config"])
+	assert.Equal(t, 3, metrics.Default.Counters["profiles_tfstate"])
+
+	assert.Nil(t, checkCounts(counts, 0))
+	assert.Nil(t, checkCounts(counts, 3))
+	assert.EqualError(t, checkCounts(counts, 2), "source tfstate has 3 profiles, more than 2")
+}
+
+func TestReportCounts(t *testing.T) {
+	var out bytes.Buffer
+	reportCounts(&out, []sourceCount{{source: "aws-config", profiles: 1}, {source: "tfstate", profiles: 300}})
+
+	assert.Equal(t, "aws-config  1 profiles\ntfstate     300 profiles\n", out.String())
+}
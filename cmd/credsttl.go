@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/aws"
+	"github.com/spf13/cobra"
+)
+
+var (
+	credsTTLProfile string
+	ssoCache        = expandUser("~/.aws/sso/cache")
+)
+
+var credsTTLCmd = &cobra.Command{
+	Use:   "creds-ttl",
+	Short: "Print the remaining session time of the AWS credentials",
+	Long: heredoc.Doc(fmt.Sprintf(`
+		Prints the time left before the STS/SSO session of the profile expires,
+		for example 'expires 42m'. Nothing is printed if the expiry is unknown.
+
+		The generated AWS profiles show the value of the iTerm user variable
+		'%s' in their badge. To populate it, add this to your shell rc file
+
+		iterm2_print_user_vars() {
+		  iterm2_set_user_var %s "$(germ creds-ttl)"
+		}
+	`, aws.CredsTTLVariable, aws.CredsTTLVariable)),
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		expires, found := aws.Expiry(credsTTLProfile, AWSConfig, AWSCredentials, ssoCache)
+		if !found {
+			return
+		}
+
+		fmt.Println(aws.TTL(expires, time.Now()))
+	},
+}
+
+func init() {
+	credsTTLCmd.Flags().StringVarP(&credsTTLProfile, "profile", "p", os.Getenv("AWS_PROFILE"), "AWS profile to check")
+	credsTTLCmd.RegisterFlagCompletionFunc("profile", completeAWSProfiles)
+
+	rootCmd.AddCommand(credsTTLCmd)
+}
@@ -1,15 +1,7 @@
 package cmd
 
 import (
-	"bytes"
-	"fmt"
-	"io/ioutil"
-	"os"
-	"os/exec"
-	"text/template"
-
 	"github.com/MakeNowJust/heredoc"
-	"github.com/mhristof/germ/log"
 	"github.com/spf13/cobra"
 )
 
@@ -38,64 +30,11 @@ var defaultCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		Verbose(cmd)
 
-		tmpl, err := template.New("script").Parse(defaultProfilePython)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not create template")
-
-		}
-
-		rendered := new(bytes.Buffer)
-		err = tmpl.Execute(rendered, struct {
+		runPython("default-profile", defaultProfilePython, struct {
 			Profile string
 		}{
 			Profile: defaultProfileName,
 		})
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not render template")
-
-		}
-
-		tmpfile, err := ioutil.TempFile("", "default-profile")
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not create temp file")
-
-		}
-		defer os.Remove(tmpfile.Name())
-
-		fmt.Println(tmpfile.Name())
-		if _, err := tmpfile.Write(rendered.Bytes()); err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not write to file")
-
-		}
-		if err := tmpfile.Close(); err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not close file")
-		}
-
-		python3, err := exec.LookPath("python3")
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not find python3")
-
-		}
-
-		pCmd := exec.Command(python3, tmpfile.Name())
-		err = pCmd.Run()
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Could not set default profile")
-		}
 	},
 }
 
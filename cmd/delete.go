@@ -15,13 +15,17 @@ var deleteCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		Verbose(cmd)
 
-		keyChain.Delete(deleteName)
+		k := findKeyChain(keyChainService)
+		k.Delete(deleteName)
 	},
 }
 
 func init() {
 	deleteCmd.Flags().StringVarP(&deleteName, "name", "", "", "Name of the profile")
+	deleteCmd.Flags().StringVarP(&keyChainService, "service", "s", keyChain.Service, "Keychain service of the secret")
 	deleteCmd.MarkFlagRequired("name")
+	deleteCmd.RegisterFlagCompletionFunc("name", completeSecrets)
+	deleteCmd.RegisterFlagCompletionFunc("service", completeServices)
 
 	rootCmd.AddCommand(deleteCmd)
 }
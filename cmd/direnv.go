@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	direnvBegin = "# BEGIN germ managed environment, generated by 'germ direnv', do not edit"
+	direnvEnd   = "# END germ managed environment"
+)
+
+var direnvCmd = &cobra.Command{
+	Use:   "direnv PROFILE [DIR]",
+	Short: "Write the environment of a profile to the .envrc of a project directory",
+	Long: heredoc.Doc(`
+		Writes the exports of 'germ env PROFILE' in a germ managed section of
+		the .envrc of the directory, the current one by default, so that
+		direnv activates the profile when entering the project. The rest of
+		the .envrc is left as it is, and running the command again updates
+		the section after the profile changes.
+	`),
+	Args: cobra.RangeArgs(1, 2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return generatedNames(), cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		dir := "."
+		if len(args) == 2 {
+			dir = expandUser(args[1])
+		}
+
+		prof, _ := lastProfiles()
+
+		profile, found := findProfile(prof, args[0])
+		if !found {
+			log.WithFields(log.Fields{
+				"profile": args[0],
+			}).Fatal("Profile not found")
+		}
+
+		path := filepath.Join(dir, ".envrc")
+		current, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			log.WithFields(log.Fields{
+				"file": path,
+				"err":  err,
+			}).Fatal("Cannot read .envrc")
+		}
+
+		err = ioutil.WriteFile(path, []byte(envrc(string(current), profile)), 0644)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file": path,
+				"err":  err,
+			}).Fatal("Cannot write .envrc")
+		}
+
+		fmt.Printf("Wrote %s, run 'direnv allow %s' to load it\n", path, dir)
+	},
+}
+
+// envrc returns the .envrc with the managed section replaced by the
+// environment of the profile.
+func envrc(current string, profile *iterm.Profile) string {
+	lines := append([]string{direnvBegin, fmt.Sprintf("# profile %s", profile.Name)}, envLines(profile)...)
+	lines = append(lines, direnvEnd)
+
+	return replaceBlock(current, strings.Join(lines, "\n")+"\n", direnvBegin, direnvEnd)
+}
+
+func init() {
+	rootCmd.AddCommand(direnvCmd)
+}
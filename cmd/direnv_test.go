@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvrc(t *testing.T) {
+	profile := iterm.NewProfile("dev", map[string]string{
+		"Command": "/usr/bin/env AWS_PROFILE=dev /usr/bin/login -fp user",
+		"Tags":    "region=eu-west-1",
+	})
+
+	var cases = []struct {
+		name    string
+		current string
+		exp     string
+	}{
+		{
+			name: "new file",
+			exp: heredoc.Doc(`
+				# BEGIN germ managed environment, generated by 'germ direnv', do not edit
+				# profile dev
+				export AWS_PROFILE=dev
+				export AWS_REGION=eu-west-1
+				export GERM_PROFILE=dev
+				export GERM_REGION=eu-west-1
+				# END germ managed environment
+			`),
+		},
+		{
+			name: "existing section",
+			current: heredoc.Doc(`
+				layout python3
+
+				# BEGIN germ managed environment, generated by 'germ direnv', do not edit
+				# profile prod
+				export AWS_PROFILE=prod
+				# END germ managed environment
+				export FOO=bar
+			`),
+			exp: heredoc.Doc(`
+				layout python3
+
+				# BEGIN germ managed environment, generated by 'germ direnv', do not edit
+				# profile dev
+				export AWS_PROFILE=dev
+				export AWS_REGION=eu-west-1
+				export GERM_PROFILE=dev
+				export GERM_REGION=eu-west-1
+				# END germ managed environment
+				export FOO=bar
+			`),
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, envrc(test.current, profile), test.name)
+	}
+}
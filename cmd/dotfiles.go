@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// dotfilesSources are the generated profiles and the config files germ reads,
+// that are copied to the dotfiles repo.
+func dotfilesSources(outputs []string) []string {
+	var sources []string
+	for _, path := range outputs {
+		if path != "-" {
+			sources = append(sources, path)
+		}
+	}
+
+	sources = append(sources, expandUser(configFile), expandUser("~/.germ.ssr.json"), expandUser(overridesFile))
+
+	schemes, _ := filepath.Glob(filepath.Join(themesDir, "*.itermcolors"))
+
+	return append(sources, schemes...)
+}
+
+// copyDotfiles copies the existing files to the dotfiles repo and returns the
+// destinations.
+func copyDotfiles(dotfiles *config.Dotfiles, sources []string) ([]string, error) {
+	var ret []string
+
+	dir := dotfiles.Dir
+	if dir == "" {
+		dir = "germ"
+	}
+	dest := filepath.Join(expandUser(dotfiles.Path), dir)
+
+	err := os.MkdirAll(dest, 0755)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create dotfiles directory")
+	}
+
+	for _, source := range sources {
+		data, err := ioutil.ReadFile(source)
+		if os.IsNotExist(err) {
+			log.WithFields(log.Fields{
+				"source": source,
+			}).Debug("Skipping missing file")
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read %s", source)
+		}
+
+		target := filepath.Join(dest, filepath.Base(source))
+		err = ioutil.WriteFile(target, data, 0644)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot write %s", target)
+		}
+
+		ret = append(ret, target)
+	}
+
+	return ret, nil
+}
+
+// commitDotfiles commits the files in the dotfiles repo, if they changed.
+func commitDotfiles(repo string, files []string) error {
+	repo = expandUser(repo)
+
+	add := exec.Command("git", append([]string{"-C", repo, "add", "--"}, files...)...)
+	if out, err := add.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "git add failed: %s", out)
+	}
+
+	// exits with 0 when there is nothing staged.
+	if exec.Command("git", "-C", repo, "diff", "--cached", "--quiet").Run() == nil {
+		return nil
+	}
+
+	commit := exec.Command("git", "-C", repo, "commit", "-m", "germ: sync generated profiles")
+	if out, err := commit.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "git commit failed: %s", out)
+	}
+
+	return nil
+}
+
+func dotfiles(outputs []string) {
+	if cfg.Dotfiles == nil || cfg.Dotfiles.Path == "" {
+		log.WithFields(log.Fields{
+			"config": configFile,
+		}).Fatal("The dotfiles path is not configured")
+	}
+
+	files, err := copyDotfiles(cfg.Dotfiles, dotfilesSources(outputs))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": cfg.Dotfiles.Path,
+			"err":  err,
+		}).Fatal("Cannot sync dotfiles")
+	}
+
+	fmt.Printf("Synced %d files to %s\n", len(files), cfg.Dotfiles.Path)
+
+	if !cfg.Dotfiles.Commit {
+		return
+	}
+
+	err = commitDotfiles(cfg.Dotfiles.Path, files)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": cfg.Dotfiles.Path,
+			"err":  err,
+		}).Fatal("Cannot commit dotfiles")
+	}
+}
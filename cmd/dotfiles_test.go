@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyDotfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dotfiles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "profiles.json")
+	assert.Nil(t, ioutil.WriteFile(source, []byte("{}"), 0644))
+
+	files, err := copyDotfiles(&config.Dotfiles{
+		Path: filepath.Join(dir, "repo"),
+	}, []string{source, filepath.Join(dir, "missing.yaml")})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "repo", "germ", "profiles.json")}, files)
+
+	data, err := ioutil.ReadFile(files[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "{}", string(data))
+}
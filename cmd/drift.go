@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var driftSources []string
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Compare the profiles of the last generation with the live sources, without writing anything",
+	Long: `Runs the sources again and reports the profiles that were added or removed
+since the last 'germ generate'. The exit code is 1 when the profiles drifted,
+to be used from cron.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		m := loadManifest()
+		if len(m.Sources) == 0 {
+			log.WithFields(log.Fields{
+				"cache": manifestCache,
+			}).Fatal("No previous generation, run 'germ generate --write' first")
+		}
+
+		var live []source
+		for _, s := range sources() {
+			if _, found := m.Sources[s.name]; !found {
+				continue
+			}
+
+			if len(driftSources) > 0 && !contains(driftSources, s.name) {
+				continue
+			}
+
+			live = append(live, s)
+		}
+
+		results, timedOut := collect(live, sourceTimeout)
+		checkTimeouts(timedOut)
+
+		changes := drift(m, live, results, timedOut)
+		reportDrift(os.Stdout, changes)
+
+		if len(changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// change is a profile added to or removed from a source.
+type change struct {
+	Source  string
+	Profile string
+	Added   bool
+}
+
+// drift compares the profiles of the sources with the manifest, by GUID.
+func drift(m *manifest, sources []source, results [][]iterm.Profile, timedOut []string) []change {
+	var ret []change
+
+	for i, s := range sources {
+		if contains(timedOut, s.name) {
+			continue
+		}
+
+		cached := profileNames(m.Sources[s.name].Profiles)
+		current := profileNames(results[i])
+
+		for guid, name := range current {
+			if _, found := cached[guid]; !found {
+				ret = append(ret, change{Source: s.name, Profile: name, Added: true})
+			}
+		}
+
+		for guid, name := range cached {
+			if _, found := current[guid]; !found {
+				ret = append(ret, change{Source: s.name, Profile: name})
+			}
+		}
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Source != ret[j].Source {
+			return ret[i].Source < ret[j].Source
+		}
+
+		return ret[i].Profile < ret[j].Profile
+	})
+
+	return ret
+}
+
+// profileNames maps the GUIDs of the profiles to their names.
+func profileNames(profiles []iterm.Profile) map[string]string {
+	var ret = map[string]string{}
+
+	for _, profile := range profiles {
+		ret[profile.GUID] = profile.Name
+	}
+
+	return ret
+}
+
+func reportDrift(w io.Writer, changes []change) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range changes {
+		sign := "-"
+		if c.Added {
+			sign = "+"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s %s\n", c.Source, sign, c.Profile)
+	}
+	tw.Flush()
+}
+
+func contains(list []string, needle string) bool {
+	for _, item := range list {
+		if item == needle {
+			return true
+		}
+	}
+
+	return false
+}
+
+func init() {
+	driftCmd.Flags().StringSliceVarP(&driftSources, "source", "s", nil, "Sources to check, defaults to all")
+	driftCmd.Flags().DurationVarP(&sourceTimeout, "source-timeout", "", 5*time.Minute, "Maximum time a source can take to generate its profiles")
+
+	rootCmd.AddCommand(driftCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrift(t *testing.T) {
+	m := newManifest()
+	m.Sources["consul"] = manifestSource{
+		Profiles: []iterm.Profile{
+			{Name: "consul-web-1", GUID: "web-1"},
+			{Name: "consul-web-2", GUID: "web-2"},
+		},
+	}
+	m.Sources["tfstate"] = manifestSource{
+		Profiles: []iterm.Profile{
+			{Name: "tf-bastion", GUID: "bastion"},
+		},
+	}
+
+	sources := []source{{name: "tfstate"}, {name: "consul"}}
+	results := [][]iterm.Profile{
+		{{Name: "tf-bastion", GUID: "bastion"}},
+		{
+			{Name: "consul-web-2", GUID: "web-2"},
+			{Name: "consul-web-3", GUID: "web-3"},
+		},
+	}
+
+	changes := drift(m, sources, results, nil)
+	assert.Equal(t, []change{
+		{Source: "consul", Profile: "consul-web-1"},
+		{Source: "consul", Profile: "consul-web-3", Added: true},
+	}, changes)
+
+	var out bytes.Buffer
+	reportDrift(&out, changes)
+	assert.Equal(t, "consul  - consul-web-1\nconsul  + consul-web-3\n", out.String())
+
+	assert.Nil(t, drift(m, sources[:1], results[:1], nil))
+	assert.Nil(t, drift(m, sources, [][]iterm.Profile{results[0], nil}, []string{"consul"}), "a source that timed out did not drift")
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+// sessionVars are the variables germ adds to the sessions themselves, which
+// should not leak into the shell that activates a profile.
+var sessionVars = []string{"HISTFILE", "TMOUT"}
+
+var envCmd = &cobra.Command{
+	Use:   "env PROFILE",
+	Short: "Print the environment of a profile as shell exports",
+	Long: heredoc.Doc(`
+		Prints the variables the command of the profile exports, ie
+		AWS_PROFILE, VAULT_ADDR or KUBECONFIG, along with the GERM_ variables
+		and the command that loads the secret of keychain profiles, to
+		activate the profile in the current shell of any terminal
+
+		eval "$(germ env prod)"
+	`),
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return generatedNames(), cobra.ShellCompDirectiveNoFileComp
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, _ := lastProfiles()
+
+		profile, found := findProfile(prof, args[0])
+		if !found {
+			log.WithFields(log.Fields{
+				"profile": args[0],
+			}).Fatal("Profile not found")
+		}
+
+		for _, line := range envLines(profile) {
+			fmt.Fprintln(os.Stdout, line)
+		}
+	},
+}
+
+// envLines returns the shell lines that activate the profile in the current
+// shell.
+func envLines(profile *iterm.Profile) []string {
+	var ret []string
+	var names []string
+
+	for _, env := range environment(profile.Command) {
+		name := strings.SplitN(env, "=", 2)[0]
+		if strings.HasPrefix(name, "GERM_") || contains(sessionVars, name) {
+			continue
+		}
+
+		names = append(names, name)
+		ret = append(ret, fmt.Sprintf("export %s", env))
+	}
+
+	if region := profile.TriggerVariables().Region; region != "" && !contains(names, "AWS_REGION") {
+		ret = append(ret, fmt.Sprintf("export AWS_REGION=%s", region))
+	}
+
+	for _, env := range profile.ShellEnv() {
+		ret = append(ret, fmt.Sprintf("export %s", env))
+	}
+
+	if secret := secretEval(profile); secret != "" {
+		ret = append(ret, secret)
+	}
+
+	return ret
+}
+
+// secretEval returns the command that loads the secret of keychain profiles,
+// from the key binding that injects it in the session.
+func secretEval(profile *iterm.Profile) string {
+	binding, found := profile.KeyboardMap["0x61-0x80000"]
+	if !found || binding.Action != 12 {
+		return ""
+	}
+
+	text := strings.TrimSpace(binding.Text)
+	if !strings.HasPrefix(text, "eval ") {
+		return ""
+	}
+
+	return text
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+}
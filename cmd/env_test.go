@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvLines(t *testing.T) {
+	var cases = []struct {
+		name    string
+		profile iterm.Profile
+		exp     []string
+	}{
+		{
+			name: "aws profile",
+			profile: iterm.Profile{
+				Name:    "prod",
+				Command: "/usr/bin/env AWS_PROFILE=prod AWS_REGION=eu-west-1 GERM_PROFILE=prod HISTFILE=/tmp/prod /usr/bin/login -fp user",
+				Tags:    []string{"account=123456789012", "region=eu-west-1"},
+			},
+			exp: []string{
+				"export AWS_PROFILE=prod",
+				"export AWS_REGION=eu-west-1",
+				"export GERM_PROFILE=prod",
+				"export GERM_ACCOUNT=123456789012",
+				"export GERM_REGION=eu-west-1",
+			},
+		},
+		{
+			name: "k8s profile",
+			profile: iterm.Profile{
+				Name:    "k8s-dev",
+				Command: "/usr/bin/env KUBECONFIG=/home/user/.kube/dev AWS_PROFILE=dev /usr/bin/login -fp user",
+				Tags:    []string{"k8s", "cluster=dev"},
+			},
+			exp: []string{
+				"export KUBECONFIG=/home/user/.kube/dev",
+				"export AWS_PROFILE=dev",
+				"export GERM_PROFILE=k8s-dev",
+			},
+		},
+		{
+			name: "keychain profile",
+			profile: iterm.Profile{
+				Name: "secret/token",
+				KeyboardMap: map[string]iterm.KeyboardMap{
+					"0x61-0x80000": {Action: 12, Text: ` eval "$(germ secret env --service germ token)"`},
+				},
+			},
+			exp: []string{
+				"export GERM_PROFILE=secret/token",
+				`eval "$(germ secret env --service germ token)"`,
+			},
+		},
+		{
+			name: "login binding",
+			profile: iterm.Profile{
+				Name: "dev",
+				KeyboardMap: map[string]iterm.KeyboardMap{
+					"0x61-0x80000": {Action: 28, Text: "login-dev"},
+				},
+			},
+			exp: []string{"export GERM_PROFILE=dev"},
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, envLines(&test.profile), test.name)
+	}
+}
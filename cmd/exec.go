@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+
+	"github.com/mhristof/germ/auditlog"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/cobra"
+)
+
+var execProfile string
+
+var execCmd = &cobra.Command{
+	Use:   "exec --profile NAME -- COMMAND [ARGS...]",
+	Short: "Run the command of a profile, logging its start, stop and exit code to the audit file",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		os.Exit(run(auditFile(), execProfile, args))
+	},
+}
+
+// run executes the command with the terminal attached and returns its exit
+// code. Failing to log is not fatal, the session is more important.
+func run(file, profile string, args []string) int {
+	start := time.Now()
+	logEvent(file, auditlog.Event{
+		Time:    start,
+		Profile: profile,
+		Event:   "start",
+		Command: args,
+	})
+
+	// Signals like ctrl-c reach the command as well, germ has to stay
+	// around to log the exit code.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	command := exec.Command(args[0], args[1:]...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	code := 0
+	err := command.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		log.WithFields(log.Fields{
+			"command": args,
+			"err":     err,
+		}).Error("Cannot run command")
+		code = 127
+	}
+
+	logEvent(file, auditlog.Event{
+		Time:     time.Now(),
+		Profile:  profile,
+		Event:    "stop",
+		ExitCode: &code,
+		Duration: time.Since(start),
+	})
+
+	return code
+}
+
+func logEvent(file string, event auditlog.Event) {
+	err := auditlog.Log(file, event)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"file": file,
+			"err":  err,
+		}).Warn("Cannot write audit log")
+	}
+}
+
+// auditFile returns the expanded path of the audit log.
+func auditFile() string {
+	path := config.AuditFile
+	if cfg.Audit != nil && cfg.Audit.File != "" {
+		path = cfg.Audit.File
+	}
+
+	expanded, err := homedir.Expand(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot expand path")
+	}
+
+	return expanded
+}
+
+// germBinary is the path of the running germ, used in the profile commands.
+func germBinary() string {
+	path, err := os.Executable()
+	if err != nil {
+		return "germ"
+	}
+
+	return path
+}
+
+// loginShell is the shell of the audited profiles without a command.
+func loginShell() string {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		return "/bin/zsh"
+	}
+
+	return shell
+}
+
+func init() {
+	execCmd.Flags().StringVarP(&execProfile, "profile", "p", "", "Profile GUID recorded in the audit log")
+	execCmd.MarkFlagRequired("profile")
+
+	rootCmd.AddCommand(execCmd)
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/auditlog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "audit.jsonl")
+
+	var cases = []struct {
+		name string
+		args []string
+		exp  int
+	}{
+		{
+			name: "success",
+			args: []string{"true"},
+			exp:  0,
+		},
+		{
+			name: "exit code",
+			args: []string{"sh", "-c", "exit 3"},
+			exp:  3,
+		},
+		{
+			name: "missing command",
+			args: []string{"germ-does-not-exist"},
+			exp:  127,
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, run(file, test.name, test.args), test.name)
+	}
+
+	events, err := auditlog.Read(file)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(events))
+	assert.Equal(t, "start", events[2].Event)
+	assert.Equal(t, []string{"sh", "-c", "exit 3"}, events[2].Command)
+	assert.Equal(t, "stop", events[3].Event)
+	assert.Equal(t, 3, *events[3].ExitCode)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+)
+
+// createdCache records when each profile was first generated.
+var createdCache = "profiles-created.json"
+
+// loadCreated returns when each profile was first generated.
+func loadCreated() map[string]time.Time {
+	var created = map[string]time.Time{}
+
+	data, _, err := cache.Read(createdCache)
+	if err != nil {
+		return created
+	}
+
+	err = json.Unmarshal(data, &created)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"cache": createdCache,
+			"err":   err,
+		}).Warn("Ignoring invalid profile creation times")
+		return map[string]time.Time{}
+	}
+
+	return created
+}
+
+// expire removes the profiles older than their TTL. The new profiles are
+// added to the creation times and the removed ones dropped, which are only
+// saved by `germ generate --write`.
+func expire(prof *iterm.Profiles, created map[string]time.Time, removed []string, now time.Time) []iterm.Expiry {
+	expired, err := prof.Expire(created, removed, cfg.Expiry, now)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot expire profiles")
+	}
+
+	return expired
+}
+
+func saveCreated(created map[string]time.Time) {
+	data, err := json.Marshal(created)
+	if err == nil {
+		err = cache.Write(createdCache, data)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"cache": createdCache,
+			"err":   err,
+		}).Error("Cannot save profile creation times")
+	}
+}
+
+func reportExpired(out io.Writer, expired []iterm.Expiry, now time.Time) {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, e := range expired {
+		fmt.Fprintf(w, "%s\tcreated %s\tttl %s\texpired %s ago\n",
+			e.Name,
+			e.Created.Format(time.RFC3339),
+			e.TTL,
+			now.Sub(e.Created.Add(e.TTL)).Round(time.Minute),
+		)
+	}
+	w.Flush()
+}
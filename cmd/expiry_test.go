@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportExpired(t *testing.T) {
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	reportExpired(&out, []iterm.Expiry{
+		{
+			Name:    "incident-1",
+			Created: now.Add(-4 * 24 * time.Hour),
+			TTL:     72 * time.Hour,
+		},
+	}, now)
+
+	assert.Equal(t, "incident-1  created 2021-06-06T12:00:00Z  ttl 72h0m0s  expired 24h0m0s ago\n", out.String())
+}
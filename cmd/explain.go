@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var envAssignment = regexp.MustCompile(`(?:^|\s)([A-Z_][A-Z0-9_]*)=([^\s'"]*)`)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <profile>",
+	Short: "Print what a generated profile does and where it comes from, without writing it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, owners := lastProfiles()
+
+		profile, found := findProfile(prof, args[0])
+		if !found {
+			log.WithFields(log.Fields{
+				"profile": args[0],
+			}).Fatal("Profile not found")
+		}
+
+		source := owners[profile.GUID]
+		var inputs []string
+		for _, s := range sources() {
+			if s.name == source {
+				inputs = s.inputs
+			}
+		}
+
+		explain(os.Stdout, profile, source, inputs, matchedRules(cfg, profile, source))
+	},
+}
+
+// findProfile returns the profile with the name or GUID.
+func findProfile(prof iterm.Profiles, name string) (*iterm.Profile, bool) {
+	for i := range prof.Profiles {
+		if prof.Profiles[i].Name == name || prof.Profiles[i].GUID == name {
+			return &prof.Profiles[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// environment returns the variables the command of the profile exports.
+func environment(command string) []string {
+	var ret []string
+	for _, match := range envAssignment.FindAllStringSubmatch(command, -1) {
+		ret = append(ret, fmt.Sprintf("%s=%s", match[1], match[2]))
+	}
+
+	return ret
+}
+
+// matchedRules returns the config settings, with the pattern, that apply to
+// the profile.
+func matchedRules(c *config.Config, profile *iterm.Profile, source string) []string {
+	var ret []string
+
+	match := func(key string, patterns []string, name string) {
+		if pattern, found := config.MatchKey(patterns, name); found {
+			ret = append(ret, fmt.Sprintf("%s: %s", key, pattern))
+		}
+	}
+
+	match("close", config.Keys(c.Close), profile.Name)
+	match("close", config.Keys(c.Close), source)
+	match("colorSchemes", config.Keys(c.ColorSchemes), profile.Name)
+	match("expiry", config.Keys(c.Expiry), profile.Name)
+	match("favorites", c.Favorites, profile.Name)
+	match("login", config.Keys(c.Login), strings.TrimPrefix(profile.Name, "login-"))
+	match("regions", config.Keys(c.Regions), profile.Name)
+	match("secretInjection", config.Keys(c.SecretInjection), profile.Name)
+	match("semanticHistory", config.Keys(c.SemanticHistory), profile.Name)
+	match("titles", config.Keys(c.Titles), source)
+
+	if c.Dangerous != nil && profile.HasTag(iterm.DangerousTag) {
+		match("dangerous.profiles", c.Dangerous.Profiles, profile.Name)
+		for _, tag := range c.Dangerous.Tags {
+			if profile.HasTag(tag) {
+				ret = append(ret, fmt.Sprintf("dangerous.tags: %s", tag))
+			}
+		}
+	}
+
+	if c.Audit != nil {
+		match("audit.profiles", c.Audit.Profiles, profile.Name)
+	}
+
+	if c.HostKeys != nil {
+		match("hostKeys.profiles", c.HostKeys.Profiles, profile.Name)
+	}
+
+	if c.History != nil {
+		match("history.profiles", c.History.Profiles, profile.Name)
+	}
+
+	if c.Idle != nil {
+		match("idle.profiles", c.Idle.Profiles, profile.Name)
+	}
+
+	for i, rule := range c.Logging {
+		match(fmt.Sprintf("logging[%d].profiles", i), rule.Profiles, profile.Name)
+		match(fmt.Sprintf("logging[%d].sources", i), rule.Sources, source)
+	}
+
+	for i, notification := range c.Notifications {
+		match(fmt.Sprintf("notifications[%d].profiles", i), notification.Profiles, profile.Name)
+	}
+
+	for i, password := range c.Passwords {
+		match(fmt.Sprintf("passwords[%d].profiles", i), password.Profiles, profile.Name)
+	}
+
+	return ret
+}
+
+func explain(w io.Writer, profile *iterm.Profile, source string, inputs, rules []string) {
+	out := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	launch := profile.Launch()
+
+	if source == "" {
+		source = "default"
+	}
+
+	fmt.Fprintf(out, "Name\t%s\n", profile.Name)
+	fmt.Fprintf(out, "GUID\t%s\n", profile.GUID)
+	fmt.Fprintf(out, "Source\t%s\n", source)
+	for _, input := range inputs {
+		fmt.Fprintf(out, "\t%s\n", input)
+	}
+
+	command := launch.Command
+	if command == "" {
+		command = "login shell"
+	}
+	fmt.Fprintf(out, "Command\t%s\n", command)
+	if launch.InitialText != "" {
+		fmt.Fprintf(out, "Initial text\t%s\n", launch.InitialText)
+	}
+	fmt.Fprintf(out, "Close\t%s\n", launch.Close)
+
+	list := func(title string, items []string) {
+		for i, item := range items {
+			if i == 0 {
+				fmt.Fprintf(out, "%s\t%s\n", title, item)
+				continue
+			}
+			fmt.Fprintf(out, "\t%s\n", item)
+		}
+	}
+
+	list("Environment", environment(launch.Command))
+	list("Tags", profile.Tags)
+
+	var triggers []string
+	for _, trigger := range profile.Triggers {
+		triggers = append(triggers, fmt.Sprintf("%s -> %s %s", trigger.Regex, trigger.Action, trigger.Parameter))
+	}
+	list("Triggers", triggers)
+
+	var maps []string
+	for key, value := range profile.KeyboardMap {
+		maps = append(maps, fmt.Sprintf("%s -> %d %s", key, value.Action, strings.ReplaceAll(value.Text, "\n", " ")))
+	}
+	sort.Strings(maps)
+	list("Keyboard maps", maps)
+
+	list("Config", rules)
+
+	out.Flush()
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
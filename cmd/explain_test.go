@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	profile := &iterm.Profile{
+		Name:          "prod-eu-west-1",
+		GUID:          "prod-eu-west-1",
+		Command:       "/usr/bin/env AWS_PROFILE=prod AWS_REGION=eu-west-1 /usr/bin/login -fp user",
+		CustomCommand: "Yes",
+		Tags:          []string{"region=eu-west-1", iterm.DangerousTag},
+		Triggers:      []iterm.Trigger{{Regex: "ExpiredToken", Action: "SendTextTrigger", Parameter: "aws sso login"}},
+		KeyboardMap:   map[string]iterm.KeyboardMap{"0x61-0x80000": {Action: 28, Text: "login-prod"}},
+	}
+
+	c := &config.Config{
+		Regions:   map[string][]string{"prod": {"eu-west-1"}},
+		Favorites: []string{"prod-*"},
+		Dangerous: &config.Dangerous{Profiles: []string{"prod*"}},
+		Logging:   []config.Logging{{Sources: []string{"aws-*"}}},
+	}
+
+	rules := matchedRules(c, profile, "aws-config")
+	assert.Equal(t, []string{"favorites: prod-*", "dangerous.profiles: prod*", "logging[0].sources: aws-*"}, rules)
+
+	var out bytes.Buffer
+	explain(&out, profile, "aws-config", []string{"/home/user/.aws/config"}, rules)
+	assert.Equal(t, heredoc.Doc(`
+		Name           prod-eu-west-1
+		GUID           prod-eu-west-1
+		Source         aws-config
+		               /home/user/.aws/config
+		Command        /usr/bin/env AWS_PROFILE=prod AWS_REGION=eu-west-1 /usr/bin/login -fp user
+		Close          always
+		Environment    AWS_PROFILE=prod
+		               AWS_REGION=eu-west-1
+		Tags           region=eu-west-1
+		               dangerous
+		Triggers       ExpiredToken -> SendTextTrigger aws sso login
+		Keyboard maps  0x61-0x80000 -> 28 login-prod
+		Config         favorites: prod-*
+		               dangerous.profiles: prod*
+		               logging[0].sources: aws-*
+	`), out.String())
+
+	_, found := findProfile(iterm.Profiles{Profiles: []iterm.Profile{*profile}}, "missing")
+	assert.False(t, found)
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+var (
+	ssmTarget     = regexp.MustCompile(`--target\s+([^\s'"]+)`)
+	sshUserTarget = regexp.MustCompile(`\bssh\s.*?[^\s@'"]+@([^\s'"]+)`)
+	sshTarget     = regexp.MustCompile(`\bssh\s+([^-\s'"][^\s'"]*)`)
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print an inventory of the generated profiles, without writing them",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, owners := lastProfiles()
+
+		var err error
+		switch exportFormat {
+		case "vscode":
+			err = writeVSCode(os.Stdout, prof.Profiles)
+		case "finicky":
+			err = writeFinicky(os.Stdout, accounts(prof.Profiles, cfg.AccountAliases), cfg.Browsers)
+		default:
+			err = writeInventory(os.Stdout, exportFormat, inventory(prof, owners))
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"format": exportFormat,
+				"err":    err,
+			}).Fatal("Cannot export profiles")
+		}
+	},
+}
+
+// inventoryRow describes the environment a profile gives access to.
+type inventoryRow struct {
+	Name    string `json:"name"`
+	Source  string `json:"source"`
+	Account string `json:"account"`
+	Region  string `json:"region"`
+	Target  string `json:"target"`
+}
+
+func inventory(prof iterm.Profiles, owners map[string]string) []inventoryRow {
+	var ret []inventoryRow
+
+	for i := range prof.Profiles {
+		profile := &prof.Profiles[i]
+		if profile.GUID == DefaultProfile {
+			continue
+		}
+
+		account := profile.AccountID()
+		if alias, found := cfg.AccountAliases[account]; found {
+			account = fmt.Sprintf("%s (%s)", alias, account)
+		}
+
+		ret = append(ret, inventoryRow{
+			Name:    profile.Name,
+			Source:  owners[profile.GUID],
+			Account: account,
+			Region:  profile.TriggerVariables().Region,
+			Target:  target(profile),
+		})
+	}
+
+	return ret
+}
+
+// target is the instance, host or cluster the profile connects to.
+func target(profile *iterm.Profile) string {
+	if match := ssmTarget.FindStringSubmatch(profile.Command); match != nil {
+		return match[1]
+	}
+
+	for _, ssh := range []*regexp.Regexp{sshUserTarget, sshTarget} {
+		if match := ssh.FindStringSubmatch(profile.Command); match != nil {
+			return match[1]
+		}
+	}
+
+	if cluster, found := profile.FindTag("cluster"); found {
+		return cluster
+	}
+
+	return ""
+}
+
+func writeInventory(w io.Writer, format string, rows []inventoryRow) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(rows, "", "    ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "csv":
+		out := csv.NewWriter(w)
+		out.Write([]string{"name", "source", "account", "region", "target"})
+		for _, row := range rows {
+			out.Write([]string{row.Name, row.Source, row.Account, row.Region, row.Target})
+		}
+		out.Flush()
+
+		return out.Error()
+	case "md":
+		fmt.Fprintln(w, "| Name | Source | Account | Region | Target |")
+		fmt.Fprintln(w, "| --- | --- | --- | --- | --- |")
+		for _, row := range rows {
+			cells := []string{row.Name, row.Source, row.Account, row.Region, row.Target}
+			for i := range cells {
+				cells[i] = strings.ReplaceAll(cells[i], "|", `\|`)
+			}
+
+			fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("unknown format %s, use csv, json, md, vscode or finicky", format)
+}
+
+// writeVSCode writes the VS Code settings of the ssh and SSM hosts, for the
+// Remote SSH extension with the hosts of `germ ssh-config --write`.
+func writeVSCode(w io.Writer, profiles []iterm.Profile) error {
+	var platforms = map[string]string{}
+
+	for i := range profiles {
+		if isSSHHost(&profiles[i]) {
+			platforms[profiles[i].Name] = "linux"
+		}
+	}
+
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"remote.SSH.remotePlatform": platforms,
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "csv", "Output format, one of csv, json, md, vscode or finicky")
+	exportCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"csv", "json", "md", "vscode", "finicky"}, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	rootCmd.AddCommand(exportCmd)
+}
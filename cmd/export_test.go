@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInventory(t *testing.T) {
+	prof := iterm.Profiles{
+		Profiles: []iterm.Profile{
+			{
+				Name:    "prod-eu-west-1",
+				GUID:    "prod-eu-west-1",
+				Command: "/usr/bin/env AWS_PROFILE=prod AWS_REGION=eu-west-1 /usr/bin/login -fp user",
+				Tags:    []string{"account=123456789012"},
+			},
+			{
+				Name:    "tf-web",
+				GUID:    "tf-web",
+				Command: "/bin/bash -lc 'AWS_PROFILE=prod aws ssm start-session --target i-0123'",
+			},
+			{Name: "ansible-db", GUID: "ansible-db", Command: "ssh -p 2222 admin@db.internal"},
+			{Name: "k8s-prod", GUID: "k8s-prod", Tags: []string{"cluster=prod"}},
+			{Name: DefaultProfile, GUID: DefaultProfile},
+		},
+	}
+	owners := map[string]string{
+		"prod-eu-west-1": "aws-config",
+		"tf-web":         "tfstate",
+		"ansible-db":     "ansible",
+		"k8s-prod":       "k8s",
+	}
+
+	rows := inventory(prof, owners)
+	assert.Equal(t, []inventoryRow{
+		{Name: "prod-eu-west-1", Source: "aws-config", Account: "123456789012", Region: "eu-west-1"},
+		{Name: "tf-web", Source: "tfstate", Target: "i-0123"},
+		{Name: "ansible-db", Source: "ansible", Target: "db.internal"},
+		{Name: "k8s-prod", Source: "k8s", Target: "prod"},
+	}, rows)
+
+	var out bytes.Buffer
+	assert.Nil(t, writeInventory(&out, "csv", rows[:2]))
+	assert.Equal(t, heredoc.Doc(`
+		name,source,account,region,target
+		prod-eu-west-1,aws-config,123456789012,eu-west-1,
+		tf-web,tfstate,,,i-0123
+	`), out.String())
+
+	out.Reset()
+	assert.Nil(t, writeInventory(&out, "md", rows[3:]))
+	assert.Equal(t, heredoc.Doc(`
+		| Name | Source | Account | Region | Target |
+		| --- | --- | --- | --- | --- |
+		| k8s-prod | k8s |  |  | prod |
+	`), out.String())
+
+	out.Reset()
+	assert.Nil(t, writeInventory(&out, "json", rows[3:]))
+	assert.Contains(t, out.String(), `"target": "prod"`)
+
+	assert.NotNil(t, writeInventory(&out, "xml", rows))
+}
+
+func TestWriteVSCode(t *testing.T) {
+	profiles := []iterm.Profile{
+		*iterm.NewProfile("tf-web", map[string]string{"Command": "aws ssm start-session --target i-0123"}),
+		*iterm.NewProfile("db", map[string]string{"Command": "ssh db.internal"}),
+		*iterm.NewProfile("k8s-prod", map[string]string{"Tags": "cluster=prod"}),
+	}
+
+	var out bytes.Buffer
+	assert.Nil(t, writeVSCode(&out, profiles))
+	assert.Equal(t, heredoc.Doc(`
+		{
+		    "remote.SSH.remotePlatform": {
+		        "db": "linux",
+		        "tf-web": "linux"
+		    }
+		}
+	`), out.String())
+}
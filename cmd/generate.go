@@ -1,28 +1,60 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/mhristof/germ/ansible"
 	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/bundle"
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/gcp"
+	"github.com/mhristof/germ/hashicorp"
 	"github.com/mhristof/germ/iterm"
 	"github.com/mhristof/germ/k8s"
 	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/metrics"
+	"github.com/mhristof/germ/plugin"
+	"github.com/mhristof/germ/tfstate"
+	"github.com/mhristof/germ/vim"
 	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var (
 	output         string
 	write          bool
-	kubeConfig     string
+	kubeConfigs    []string
 	diff           bool
+	live           bool
+	syncDotfiles   bool
+	timings        bool
+	timingsFile    string
+	splitOutput    bool
+	sourceTimeout  time.Duration
+	strictTimeout  bool
+	force          bool
+	maxProfiles    int
+	showExpired    bool
+	allowUnsigned  bool
 	AWSConfig      = expandUser("~/.aws/config")
 	AWSCredentials = expandUser("~/.aws/credentials")
+	Saml2AWSConfig = expandUser("~/.saml2aws")
 	DefaultProfile = "default-profile"
+	overridesFile  = config.OverridesPath
 )
 
 var generateCmd = &cobra.Command{
@@ -46,67 +78,792 @@ var generateCmd = &cobra.Command{
 			}).Fatal("--write and --diff are incompatible")
 		}
 
-		var prof iterm.Profiles
+		if live && !diff {
+			log.WithFields(log.Fields{
+				"live": live,
+				"diff": diff,
+			}).Fatal("--live requires --diff")
+		}
+
+		if syncDotfiles && !write {
+			log.WithFields(log.Fields{
+				"write":         write,
+				"sync-dotfiles": syncDotfiles,
+			}).Fatal("--sync-dotfiles requires --write")
+		}
+
+		if record && (replay || mockData != "") {
+			log.WithFields(log.Fields{
+				"record":    record,
+				"replay":    replay,
+				"mock-data": mockData,
+			}).Fatal("--record is incompatible with --replay and --mock-data")
+		}
+
+		// The timings are most useful when the generation fails.
+		log.RegisterExitHandler(report)
+		defer report()
+
+		if replay {
+			mockData = cache.Path(recordings)
+		}
+
+		// Dedupe goes first, so the recordings have the calls of every
+		// profile, and the fixtures serve each profile its own.
+		if mockData != "" {
+			useFixtures(expandUser(mockData))
+		} else {
+			aws.Dedupe(aws.Accounts(AWSConfig, AWSCredentials))
+		}
+
+		if record && !dryRun {
+			force = true
+			recordResponses()
+		}
+
+		var m *manifest
+		if !dryRun && mockData == "" {
+			m = loadManifest()
+			if force {
+				m = newManifest()
+			}
+		}
 
-		prof.Profiles = append(prof.Profiles, aws.Profiles("config", AWSConfig)...)
-		prof.Profiles = append(prof.Profiles, aws.Profiles("credentials", AWSCredentials)...)
-		prof.Profiles = append(prof.Profiles, k8s.Profiles(kubeConfig, dryRun)...)
-		prof.Profiles = append(prof.Profiles, keyChain.Profiles()...)
-		prof.Profiles = append(prof.Profiles, *iterm.NewProfile(DefaultProfile, map[string]string{
-			"AllowTitleSetting": "true",
-			"BadgeText":         "",
-		}))
-		prof.UpdateKeyboardMaps()
-		prof.UpdateAWSSmartSelectionRules()
+		all := sources()
+		if m != nil {
+			all = m.apply(all)
+		}
+
+		g := buildProfiles(all, m, func(host, port string) (string, bool) {
+			if !write {
+				return pinnedHostKeys(cfg.HostKeys.Directory, host, port)
+			}
 
-		profJSON, err := json.MarshalIndent(prof, "", "    ")
+			return hostKeysFile(cfg.HostKeys.Directory, host, port)
+		})
+		prof, owners := g.profiles, g.owners
+
+		err := checkCounts(g.counts, maxProfiles)
 		if err != nil {
 			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("Cannot indent json results")
+				"max-profiles": maxProfiles,
+				"err":          err,
+			}).Fatal("Cannot generate profiles")
+		}
+
+		if showExpired {
+			reportExpired(os.Stderr, g.expired, time.Now())
+		}
+
+		outputs := map[string]iterm.Profiles{output: prof}
+		if splitOutput {
+			outputs = splitProfiles(prof, owners, output)
+		}
+
+		var paths []string
+		for path := range outputs {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		// iTerm is queried once for the profiles of every output.
+		var loaded map[string]iterm.Profile
+		if diff && live {
+			loaded = liveProfiles(outputs)
+		}
+
+		for _, path := range paths {
+			emit(path, outputs[path], loaded)
 		}
 
 		if write {
-			err = ioutil.WriteFile(output, profJSON, 0644)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"output": output,
-					"err":    err,
-				}).Fatal("Cannot write to file")
-			}
-		} else if diff {
-			curr, err := ioutil.ReadFile(output)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"err":    err,
-					"output": output,
-				}).Fatal("Cannot read file")
+			g.save()
+		}
+
+		if write && cfg.Bookmarks != nil {
+			saveBookmarks(cfg.Bookmarks, accounts(prof.Profiles, cfg.AccountAliases))
+		}
+
+		if syncDotfiles {
+			dotfiles(paths)
+		}
+
+		reportCounts(os.Stderr, g.counts)
+	},
+}
+
+// generation is a build of the profiles, along with the state `germ
+// generate --write` saves once the profiles are written.
+type generation struct {
+	profiles iterm.Profiles
+	// owners maps the profile GUIDs to their source.
+	owners map[string]string
+	// manifest records the results of the sources, nil if they are not
+	// recorded.
+	manifest *manifest
+	// created is when each profile was first generated.
+	created map[string]time.Time
+	expired []iterm.Expiry
+	// dirs are the history and log directories of the profiles.
+	dirs []string
+	// counts are the number of profiles of every source.
+	counts []sourceCount
+}
+
+// lastProfiles builds the profiles from the source results of the last
+// written generation, for the commands that only read the profiles. It does
+// not call the sources, scan host keys or save anything.
+func lastProfiles() (iterm.Profiles, map[string]string) {
+	g := buildProfiles(lastSources(), nil, func(host, port string) (string, bool) {
+		return pinnedHostKeys(cfg.HostKeys.Directory, host, port)
+	})
+
+	return g.profiles, g.owners
+}
+
+// lastSources are the sources with the profiles of the last written
+// generation. The sources that were not generated have no profiles.
+func lastSources() []source {
+	m := loadManifest()
+	if len(m.Sources) == 0 {
+		log.WithFields(log.Fields{
+			"manifest": cache.Path(manifestCache),
+		}).Warn("No generated profiles, run `germ generate --write` first")
+	}
+
+	var ret []source
+	for _, s := range sources() {
+		previous, found := m.Sources[s.name]
+		if !found {
+			log.WithFields(log.Fields{
+				"source": s.name,
+			}).Debug("Source was not generated")
+		}
+
+		profiles := previous.Profiles
+		s.profiles = func(context.Context) []iterm.Profile { return profiles }
+		ret = append(ret, s)
+	}
+
+	return ret
+}
+
+// buildProfiles runs the sources and post processes their profiles. The
+// results of the sources are recorded in the manifest, if any. Nothing is
+// saved, see save.
+func buildProfiles(all []source, m *manifest, hostKeys func(host, port string) (string, bool)) *generation {
+	var prof iterm.Profiles
+	owners := map[string]string{}
+
+	tfstate.Executable = germBinary()
+
+	results, timedOut := collect(all, sourceTimeout)
+	checkTimeouts(timedOut)
+	if m != nil {
+		m.previous(all, results, timedOut)
+	}
+
+	counts := countProfiles(all, results)
+
+	resolved, err := resolveNames(all, results, cfg.Names)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot generate profiles")
+	}
+
+	for i, profiles := range resolved {
+		for _, profile := range profiles {
+			owners[profile.GUID] = all[i].name
+		}
+		prof.Profiles = append(prof.Profiles, profiles...)
+	}
+
+	var removed []string
+	if m != nil {
+		removed = m.removed(all, results, timedOut)
+		m.update(all, results, timedOut)
+	}
+	prof.Profiles = append(prof.Profiles, *iterm.NewProfile(DefaultProfile, map[string]string{
+		"AllowTitleSetting": "true",
+		"BadgeText":         "",
+	}))
+	if cfg.AutomaticProfileSwitching {
+		prof.UpdateBoundHosts()
+	}
+
+	if cfg.HostKeys != nil {
+		prof.UpdateHostKeys(cfg.HostKeys, hostKeys)
+	}
+
+	prof.UpdateClose(cfg.Close, func(profile *iterm.Profile) string { return owners[profile.GUID] })
+	prof.UpdatePre(cfg.Pre, cfg.VPNs, loginShell())
+	prof.UpdateKeyboardMaps()
+	prof.UpdateAWSSmartSelectionRules()
+	prof.UpdateGitSmartSelectionRules(cfg.Workspace)
+	prof.UpdateLinks(cfg.Links)
+	prof.UpdateTitles(cfg.Titles, func(profile *iterm.Profile) string { return owners[profile.GUID] })
+	prof.UpdateColorSchemes(cfg.ColorSchemes, themesDir)
+	prof.UpdateDangerous(cfg.Dangerous)
+	prof.UpdateTheme(cfg.Theme)
+	prof.UpdateSemanticHistory(cfg.SemanticHistory)
+	prof.UpdateTriggerVariables()
+
+	err = prof.UpdateSnippets(cfg.Snippets)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot add the snippets")
+	}
+
+	err = prof.UpdatePasswordTriggers(cfg.Passwords)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot add the password triggers")
+	}
+
+	err = prof.UpdateNotifications(cfg.Notifications)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot add the notification triggers")
+	}
+
+	err = prof.UpdateLocalTriggers(cfg.Installer, func(profile *iterm.Profile) bool {
+		return isLocal(owners[profile.GUID])
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"installer": cfg.Installer,
+			"err":       err,
+		}).Fatal("Cannot update the not found triggers")
+	}
+
+	prof.UpdateAgents(cfg.Agents, func(profile *iterm.Profile) bool {
+		return isLocal(owners[profile.GUID])
+	})
+
+	if cfg.DetectOS {
+		prof.UpdateOSTriggers(
+			func(profile *iterm.Profile) bool { return isLocal(owners[profile.GUID]) },
+			func(profile *iterm.Profile) bool { return isRemoteSession(owners[profile.GUID]) },
+		)
+	}
+
+	now := time.Now()
+	created := loadCreated()
+	expired := expire(&prof, created, removed, now)
+
+	// UpdateShellEnv wraps the local shells with a custom command, which
+	// UpdateIdle would take for remote sessions.
+	prof.UpdateIdle(cfg.Idle, loginShell())
+	prof.UpdateShellEnv(loginShell())
+
+	dirs := prof.UpdateHistory(cfg.History, loginShell())
+
+	prof.UpdateAudit(cfg.Audit, germBinary(), loginShell())
+
+	dirs = append(dirs, prof.UpdateLogging(cfg.Logging, func(profile *iterm.Profile) string { return owners[profile.GUID] }, now)...)
+
+	prof.UpdateFavorites(usage())
+
+	err = prof.UpdateShortcuts(cfg.Shortcuts)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Warn("Cannot assign shortcuts")
+	}
+
+	if cfg.HierarchicalNames {
+		prof.UpdateHierarchicalNames(cfg.AccountAliases)
+	}
+
+	overrides, err := config.LoadOverrides(overridesFile)
+	if err == nil {
+		err = prof.UpdateOverrides(overrides)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"overrides": overridesFile,
+			"err":       err,
+		}).Fatal("Cannot apply the overrides")
+	}
+
+	err = prof.Transform(cfg.Transforms, runJQ)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot transform the profiles")
+	}
+
+	return &generation{
+		profiles: prof,
+		owners:   owners,
+		manifest: m,
+		created:  created,
+		expired:  expired,
+		dirs:     dirs,
+		counts:   counts,
+	}
+}
+
+// save records the generation for the next one and creates the directories
+// of the profiles. The manifest and the creation times are not saved with
+// --mock-data.
+func (g *generation) save() {
+	if g.manifest != nil {
+		g.manifest.save()
+	}
+
+	if mockData == "" {
+		saveCreated(g.created)
+	}
+
+	for _, dir := range g.dirs {
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"dir": dir,
+				"err": err,
+			}).Warn("Cannot create directory")
+		}
+	}
+}
+
+// splitProfiles groups the profiles per source, keyed by the output path of
+// the source.
+func splitProfiles(prof iterm.Profiles, owners map[string]string, tmpl string) map[string]iterm.Profiles {
+	var ret = map[string]iterm.Profiles{}
+
+	for _, profile := range prof.Profiles {
+		source, found := owners[profile.GUID]
+		if !found {
+			source = "default"
+		}
+
+		path, err := outputPath(tmpl, source)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"output": tmpl,
+				"err":    err,
+			}).Fatal("Cannot render output path")
+		}
+
+		this := ret[path]
+		this.Profiles = append(this.Profiles, profile)
+		ret[path] = this
+	}
+
+	return ret
+}
+
+// outputPath renders the {{ .Source }} template of the output. Paths without
+// a template get the source name appended, ie aws-profiles-k8s.json.
+func outputPath(tmpl, source string) (string, error) {
+	if tmpl == "-" {
+		return tmpl, nil
+	}
+
+	if !strings.Contains(tmpl, "{{") {
+		ext := filepath.Ext(tmpl)
+		tmpl = fmt.Sprintf("%s-{{ .Source }}%s", strings.TrimSuffix(tmpl, ext), ext)
+	}
+
+	t, err := template.New("output").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse output template")
+	}
+
+	var path bytes.Buffer
+	err = t.Execute(&path, struct{ Source string }{source})
+	if err != nil {
+		return "", errors.Wrap(err, "cannot render output template")
+	}
+
+	return path.String(), nil
+}
+
+// emit prints, writes or diffs the profiles of the given output path. The
+// path - is always printed to stdout.
+func emit(path string, prof iterm.Profiles, loaded map[string]iterm.Profile) {
+	profJSON, err := prof.JSON()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot indent json results")
+	}
+
+	switch {
+	case path == "-" || (!write && !diff):
+		fmt.Println(string(profJSON))
+	case write:
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"output": path,
+				"err":    err,
+			}).Fatal("Cannot create output directory")
+		}
+
+		err = ioutil.WriteFile(path, profJSON, 0644)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"output": path,
+				"err":    err,
+			}).Fatal("Cannot write to file")
+		}
+	case diff:
+		current, err := readProfiles(path)
+		if err != nil && !live {
+			log.WithFields(log.Fields{
+				"err":    err,
+				"output": path,
+			}).Fatal("Cannot read output file")
+		}
+
+		sortProfiles(current.Profiles)
+		sortProfiles(prof.Profiles)
+
+		if live {
+			inMemory := loadedProfiles(loaded, current.Profiles, prof.Profiles)
+			if diff := cmp.Diff(inMemory, current); diff != "" && err == nil {
+				fmt.Println(fmt.Sprintf("Not loaded by iTerm yet, %s (-loaded +file):", path), diff)
 			}
 
-			var current iterm.Profiles
-			err = json.Unmarshal(curr, &current)
-			if err != nil {
-				log.WithFields(log.Fields{
-					"err":    err,
-					"output": output,
-				}).Fatal("Cannot unmarshal output file")
+			current = inMemory
+		}
+
+		if diff := cmp.Diff(current, prof); diff != "" {
+			fmt.Println(fmt.Sprintf("Updating %s (-current +new):", path), diff)
+		}
+	}
+}
+
+func sortProfiles(profiles []iterm.Profile) {
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].GUID < profiles[j].GUID
+	})
+}
+
+// readProfiles reads the generated profiles of the output file.
+func readProfiles(path string) (iterm.Profiles, error) {
+	var ret iterm.Profiles
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ret, errors.Wrap(err, "cannot read file")
+	}
+
+	err = json.Unmarshal(data, &ret)
+	if err != nil {
+		return ret, errors.Wrap(err, "cannot unmarshal profiles")
+	}
+
+	return ret, nil
+}
+
+// source generates the profiles of one provider.
+type source struct {
+	name string
+	// profiles runs the source, ctx is cancelled once the source times out.
+	profiles func(ctx context.Context) []iterm.Profile
+	// inputs are the files the profiles are generated from. Sources without
+	// inputs are always regenerated.
+	inputs []string
+}
+
+func sources() []source {
+	ret := []source{
+		{
+			name:     "aws-config",
+			profiles: func(context.Context) []iterm.Profile { return aws.Profiles("config", AWSConfig, cfg) },
+			inputs:   []string{AWSConfig},
+		},
+		{
+			name: "aws-credentials",
+			profiles: func(context.Context) []iterm.Profile {
+				return aws.CredentialProfiles("credentials", AWSCredentials, AWSConfig, cfg)
+			},
+			inputs: []string{AWSCredentials, AWSConfig},
+		},
+		{
+			name:     "saml2aws",
+			profiles: func(context.Context) []iterm.Profile { return aws.Saml2AWSProfiles(Saml2AWSConfig, cfg) },
+			inputs:   []string{Saml2AWSConfig},
+		},
+		{
+			name:     "cloudformation",
+			profiles: func(context.Context) []iterm.Profile { return aws.StackProfiles(cfg.Stacks) },
+		},
+		{
+			name:     "s3",
+			profiles: func(context.Context) []iterm.Profile { return aws.BucketProfiles(cfg.Buckets) },
+		},
+		{
+			name:     "k8s-port-forward",
+			profiles: func(context.Context) []iterm.Profile { return k8s.PortForwardProfiles(cfg.PortForwards) },
+		},
+		{
+			name:     "gcp",
+			profiles: func(context.Context) []iterm.Profile { return gcp.Profiles(cfg.GCP) },
+		},
+		{
+			name:     "k8s",
+			profiles: func(context.Context) []iterm.Profile { return k8s.Profiles(kubeConfigPaths(kubeConfigs), dryRun) },
+			inputs:   kubeConfigPaths(kubeConfigs),
+		},
+		{
+			name:     "tfstate",
+			profiles: func(context.Context) []iterm.Profile { return tfstate.Profiles(cfg.TFState) },
+			inputs:   tfstatePaths(),
+		},
+		{
+			name:     "ansible",
+			profiles: func(context.Context) []iterm.Profile { return ansible.Profiles(cfg.Ansible) },
+			inputs:   ansibleInventories(),
+		},
+		{
+			name:     "consul",
+			profiles: func(ctx context.Context) []iterm.Profile { return hashicorp.ConsulProfiles(ctx, cfg.Consul) },
+		},
+		{
+			name:     "nomad",
+			profiles: func(ctx context.Context) []iterm.Profile { return hashicorp.NomadProfiles(ctx, cfg.Nomad) },
+		},
+		{
+			name:     "agents",
+			profiles: func(context.Context) []iterm.Profile { return iterm.AgentsProfiles(cfg.Agents) },
+		},
+		{
+			name:     "vpn",
+			profiles: func(context.Context) []iterm.Profile { return iterm.VPNProfiles(cfg.VPNs, loginShell()) },
+		},
+		{
+			name:     "vim",
+			profiles: func(context.Context) []iterm.Profile { return vim.Profiles(cfg.Projects) },
+			inputs:   projectDirs(),
+		},
+	}
+
+	for _, p := range plugin.Discover(plugin.Prefix, os.Getenv("PATH"), cfg.Plugins) {
+		p := p
+		ret = append(ret, source{
+			name:     p.Source(),
+			profiles: p.Profiles,
+		})
+	}
+
+	for _, p := range plugin.DiscoverRPC(os.Getenv("PATH"), cfg.RPCPlugins) {
+		p := p
+		ret = append(ret, source{
+			name:     p.Source(),
+			profiles: func(context.Context) []iterm.Profile { return p.RPCProfiles(sourceTimeout) },
+		})
+	}
+
+	for _, b := range cfg.Bundles {
+		b := b
+		ret = append(ret, source{
+			name:     fmt.Sprintf("bundle-%s", bundle.Name(b)),
+			profiles: func(context.Context) []iterm.Profile { return bundle.Profiles(b, allowUnsigned) },
+		})
+	}
+
+	for _, k := range keyChains() {
+		k := k
+		ret = append(ret, source{
+			name:     fmt.Sprintf("keychain-%s", k.Service),
+			profiles: func(context.Context) []iterm.Profile { return k.Profiles(cfg) },
+		})
+	}
+
+	return ret
+}
+
+// localSources are the sources whose profiles run a shell on this machine,
+// instead of a remote session. The default profile has no source.
+var localSources = []string{"", "agents", "aws-config", "aws-credentials", "saml2aws", "s3", "vim", "vpn", "keychain-*"}
+
+func isLocal(source string) bool {
+	_, found := config.MatchKey(localSources, source)
+
+	return found
+}
+
+// remoteSessions are the sources whose profile commands open a shell on a
+// remote host.
+var remoteSessions = []string{"ansible", "cloudformation", "consul", "nomad", "tfstate"}
+
+func isRemoteSession(source string) bool {
+	_, found := config.MatchKey(remoteSessions, source)
+
+	return found
+}
+
+// defaultKubeConfigs returns the KUBECONFIG path list, or ~/.kube/config.
+func defaultKubeConfigs() []string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return kubeConfigPaths([]string{env})
+	}
+
+	return []string{expandUser("~/.kube/config")}
+}
+
+// kubeConfigPaths splits the path lists and removes the duplicates.
+func kubeConfigPaths(configs []string) []string {
+	var ret []string
+	var seen = map[string]bool{}
+
+	for _, config := range configs {
+		for _, path := range filepath.SplitList(config) {
+			path = expandUser(path)
+			if path == "" || seen[path] {
+				continue
 			}
+			seen[path] = true
+			ret = append(ret, path)
+		}
+	}
+
+	return ret
+}
+
+// tfstatePaths returns the local state files, or nothing if any of the states
+// is in a remote backend, since those cannot be fingerprinted, or looks up
+// the live EC2 or SSM state of its instances.
+func tfstatePaths() []string {
+	var ret []string
+
+	for _, state := range cfg.TFState {
+		if state.Path == "" || state.Stopped != "" || state.Ping != "" {
+			return nil
+		}
+
+		ret = append(ret, expandUser(state.Path))
+	}
+
+	return ret
+}
+
+// ansibleInventories returns the static inventories, or nothing if any of them
+// is a dynamic inventory script, whose output cannot be fingerprinted.
+func ansibleInventories() []string {
+	var ret []string
+
+	for _, path := range cfg.Ansible {
+		path = expandUser(path)
+
+		info, err := os.Stat(path)
+		if err == nil && info.Mode()&0111 != 0 {
+			return nil
+		}
+
+		ret = append(ret, path)
+	}
+
+	return ret
+}
+
+func projectDirs() []string {
+	var ret []string
+
+	if cfg.Projects == nil {
+		return ret
+	}
+
+	for _, dir := range cfg.Projects.Dirs {
+		ret = append(ret, expandUser(dir))
+	}
+
+	return ret
+}
 
-			sort.Slice(current.Profiles, func(i, j int) bool {
-				return current.Profiles[i].GUID < current.Profiles[j].GUID
-			})
+// collect runs the sources concurrently, each with its own timeout, and
+// returns their profiles in the order of the sources along with the names of
+// the sources that timed out. The sources that timed out have no profiles and
+// their context is cancelled.
+func collect(sources []source, timeout time.Duration) ([][]iterm.Profile, []string) {
+	var wg sync.WaitGroup
+	var results = make([][]iterm.Profile, len(sources))
+	var timedOut = make([]bool, len(sources))
 
-			sort.Slice(prof.Profiles, func(i, j int) bool {
-				return prof.Profiles[i].GUID < prof.Profiles[j].GUID
-			})
+	for i, s := range sources {
+		wg.Add(1)
+		go func(i int, s source) {
+			defer wg.Done()
 
-			if diff := cmp.Diff(current, prof); diff != "" {
-				fmt.Println("Updating (-current +new):", diff)
+			start := time.Now()
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			finished := make(chan []iterm.Profile, 1)
+			go func() {
+				finished <- s.profiles(ctx)
+			}()
+
+			select {
+			case profiles := <-finished:
+				results[i] = profiles
+				metrics.Since("source", s.name, start)
+			case <-ctx.Done():
+				timedOut[i] = true
 			}
-		} else {
-			fmt.Println(string(profJSON))
+		}(i, s)
+	}
+
+	wg.Wait()
+
+	var names []string
+	for i, s := range sources {
+		if timedOut[i] {
+			names = append(names, s.name)
 		}
-	},
+	}
+
+	return results, names
+}
+
+// checkTimeouts warns about the sources that timed out, or fails the
+// generation with --strict-timeout.
+func checkTimeouts(timedOut []string) {
+	if len(timedOut) == 0 {
+		return
+	}
+
+	if strictTimeout {
+		log.WithFields(log.Fields{
+			"source-timeout": sourceTimeout,
+			"sources":        strings.Join(timedOut, ", "),
+		}).Fatal("Sources timed out")
+	}
+
+	for _, name := range timedOut {
+		log.WithFields(log.Fields{
+			"source-timeout": sourceTimeout,
+			"source":         name,
+		}).Warn("Source timed out, keeping its previous profiles")
+	}
+}
+
+// report prints or writes the timings. It can run from a Fatal, so it only
+// reports its own errors.
+func report() {
+	if timings {
+		metrics.Default.Report(os.Stderr)
+	}
+
+	if timingsFile == "" {
+		return
+	}
+
+	err := metrics.Default.Write(timingsFile)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"timings-file": timingsFile,
+			"err":          err,
+		}).Error("Cannot write timings")
+	}
 }
 
 func expandUser(path string) string {
@@ -124,7 +881,7 @@ func init() {
 	generateCmd.Flags().StringVarP(
 		&output, "output", "o",
 		expandUser("~/Library/Application Support/iTerm2/DynamicProfiles/aws-profiles.json"),
-		"File to save the generated profiles",
+		"File to save the generated profiles, - for stdout. With --split-output, {{ .Source }} is replaced with the source name",
 	)
 	generateCmd.Flags().StringVarP(
 		&AWSConfig, "aws-config", "a",
@@ -137,12 +894,32 @@ func init() {
 		"AWS credentials file path",
 	)
 	generateCmd.Flags().StringVarP(
-		&kubeConfig, "kube-config", "k",
-		expandUser("~/.kube/config"),
-		"Kubernetes configuration file",
+		&Saml2AWSConfig, "saml2aws-config", "",
+		Saml2AWSConfig,
+		"saml2aws config file path",
+	)
+	generateCmd.Flags().StringSliceVarP(
+		&kubeConfigs, "kube-config", "k",
+		defaultKubeConfigs(),
+		"Kubernetes configuration files, either repeated or as a KUBECONFIG path list",
 	)
 	generateCmd.Flags().BoolVarP(&write, "write", "w", false, "Write the output to the destination file")
 	generateCmd.Flags().BoolVarP(&diff, "diff", "d", false, "Generate a diff for the new changes")
+	generateCmd.Flags().BoolVarP(&live, "live", "", false, "Diff against the profiles loaded in iTerm instead of the output file")
+	generateCmd.Flags().DurationVarP(&sourceTimeout, "source-timeout", "", 5*time.Minute, "Maximum time a source can take to generate its profiles")
+	generateCmd.Flags().BoolVarP(&strictTimeout, "strict-timeout", "", false, "Fail the generation when a source times out, instead of skipping it")
+	generateCmd.Flags().IntVarP(&maxProfiles, "max-profiles", "", 0, "Fail when a source generates more profiles than this")
+	generateCmd.Flags().BoolVarP(&force, "force", "f", false, "Regenerate all sources, even if their inputs did not change")
+	generateCmd.Flags().BoolVarP(&showExpired, "show-expired", "", false, "Report the profiles removed because they are older than their TTL")
+	generateCmd.Flags().BoolVarP(&allowUnsigned, "allow-unsigned", "", false, "Merge the bundles without a valid signature")
+	generateCmd.Flags().BoolVarP(&splitOutput, "split-output", "", false, "Write a file per source")
+	generateCmd.Flags().BoolVarP(&timings, "timings", "", false, "Report the time spent per source and AWS profile")
+	generateCmd.Flags().StringVarP(&timingsFile, "timings-file", "", "", "Write the timings as JSON to this file")
+	generateCmd.Flags().StringVarP(&overridesFile, "overrides", "", overridesFile, "File with the patches of the generated profiles, applied last")
+	generateCmd.Flags().StringVarP(&mockData, "mock-data", "", "", "Directory with JSON fixtures of the remote calls, to generate without credentials")
+	generateCmd.Flags().BoolVarP(&record, "record", "", false, "Save the responses of the remote calls in the cache, for --replay")
+	generateCmd.Flags().BoolVarP(&replay, "replay", "", false, "Generate from the responses saved by the last --record, without calling the remote sources")
+	generateCmd.Flags().BoolVarP(&syncDotfiles, "sync-dotfiles", "", false, "Copy the generated and config files to the 'dotfiles' repo of the config")
 
 	rootCmd.AddCommand(generateCmd)
 }
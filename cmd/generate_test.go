@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputPath(t *testing.T) {
+	var cases = []struct {
+		name   string
+		tmpl   string
+		source string
+		path   string
+	}{
+		{
+			name:   "template",
+			tmpl:   "/tmp/scratch/{{ .Source }}.json",
+			source: "k8s",
+			path:   "/tmp/scratch/k8s.json",
+		},
+		{
+			name:   "plain path",
+			tmpl:   "/tmp/aws-profiles.json",
+			source: "k8s",
+			path:   "/tmp/aws-profiles-k8s.json",
+		},
+		{
+			name:   "stdout",
+			tmpl:   "-",
+			source: "k8s",
+			path:   "-",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			path, err := outputPath(test.tmpl, test.source)
+			assert.Nil(t, err)
+			assert.Equal(t, test.path, path, test.name)
+		})
+	}
+
+	_, err := outputPath("{{ .Source", "k8s")
+	assert.NotNil(t, err)
+}
+
+func TestSplitProfiles(t *testing.T) {
+	prof := iterm.Profiles{
+		Profiles: []iterm.Profile{
+			*iterm.NewProfile("config-prod", map[string]string{}),
+			*iterm.NewProfile("k8s-prod", map[string]string{}),
+			*iterm.NewProfile(DefaultProfile, map[string]string{}),
+		},
+	}
+
+	outputs := splitProfiles(prof, map[string]string{
+		"config-prod": "aws-config",
+		"k8s-prod":    "k8s",
+	}, "out/{{ .Source }}.json")
+
+	assert.Equal(t, 3, len(outputs))
+	assert.Equal(t, "config-prod", outputs["out/aws-config.json"].Profiles[0].GUID)
+	assert.Equal(t, "k8s-prod", outputs["out/k8s.json"].Profiles[0].GUID)
+	assert.Equal(t, DefaultProfile, outputs["out/default.json"].Profiles[0].GUID)
+}
+
+func TestCollect(t *testing.T) {
+	slow := func(name string, delay time.Duration) source {
+		return source{
+			name: name,
+			profiles: func(context.Context) []iterm.Profile {
+				time.Sleep(delay)
+				return []iterm.Profile{*iterm.NewProfile(name, map[string]string{})}
+			},
+		}
+	}
+
+	results, timedOut := collect([]source{
+		slow("first", 20*time.Millisecond),
+		slow("second", 0),
+	}, time.Second)
+
+	assert.Nil(t, timedOut)
+	assert.Equal(t, "first", results[0][0].GUID)
+	assert.Equal(t, "second", results[1][0].GUID)
+
+	results, timedOut = collect([]source{
+		slow("fast", 0),
+		slow("stuck", time.Second),
+	}, 20*time.Millisecond)
+
+	assert.Equal(t, []string{"stuck"}, timedOut)
+	assert.Equal(t, "fast", results[0][0].GUID)
+	assert.Nil(t, results[1])
+
+	stopped := make(chan bool, 1)
+	_, timedOut = collect([]source{{
+		name: "hung",
+		profiles: func(ctx context.Context) []iterm.Profile {
+			<-ctx.Done()
+			stopped <- true
+			return nil
+		},
+	}}, 20*time.Millisecond)
+
+	assert.Equal(t, []string{"hung"}, timedOut)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("the source that timed out is not stopped")
+	}
+}
+
+func TestKubeConfigPaths(t *testing.T) {
+	assert.Equal(t,
+		[]string{"/kube/a.yml", "/kube/b.yml", "/kube/c.yml"},
+		kubeConfigPaths([]string{"/kube/a.yml:/kube/b.yml", "/kube/c.yml", "/kube/a.yml", ""}),
+	)
+}
+
+func TestIsLocal(t *testing.T) {
+	var cases = []struct {
+		source string
+		exp    bool
+	}{
+		{source: "", exp: true},
+		{source: "aws-config", exp: true},
+		{source: "keychain-work", exp: true},
+		{source: "k8s", exp: false},
+		{source: "consul", exp: false},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, isLocal(test.source), test.source)
+	}
+}
+
+func TestIsRemoteSession(t *testing.T) {
+	assert.True(t, isRemoteSession("consul"))
+	assert.False(t, isRemoteSession("k8s"))
+	assert.False(t, isRemoteSession(""))
+}
+
+func TestTFStatePaths(t *testing.T) {
+	defer func(c *config.Config) { cfg = c }(cfg)
+
+	var cases = []struct {
+		name   string
+		states []config.TFState
+		exp    []string
+	}{
+		{
+			name:   "local states",
+			states: []config.TFState{{Path: "/infra/prod.tfstate"}, {Path: "/infra/dev.tfstate"}},
+			exp:    []string{"/infra/prod.tfstate", "/infra/dev.tfstate"},
+		},
+		{
+			name:   "remote backend",
+			states: []config.TFState{{Path: "/infra/prod.tfstate"}, {Dir: "/infra/staging"}},
+		},
+		{
+			name:   "live EC2 state",
+			states: []config.TFState{{Path: "/infra/prod.tfstate", Stopped: "skip"}},
+		},
+		{
+			name:   "SSM ping",
+			states: []config.TFState{{Path: "/infra/prod.tfstate"}, {Path: "/infra/dev.tfstate", Ping: "24h"}},
+		},
+	}
+
+	for _, test := range cases {
+		cfg = &config.Config{TFState: test.states}
+		assert.Equal(t, test.exp, tfstatePaths(), test.name)
+	}
+}
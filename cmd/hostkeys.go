@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// defaultHostKeysDir keeps the pinned host keys, a known_hosts file per host.
+const defaultHostKeysDir = "~/.local/state/germ/known_hosts"
+
+// hostKeysFile returns the known_hosts file of the host, scanning its keys
+// with ssh-keyscan the first time. The keys stay pinned until the file is
+// removed.
+func hostKeysFile(dir, host, port string) (string, bool) {
+	path, ok := pinnedHostKeys(dir, host, port)
+	if ok || dryRun {
+		return path, true
+	}
+
+	keys, err := keyscan(host, port)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(path, keys, 0600)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"host": host,
+			"port": port,
+			"err":  err,
+		}).Warn("Cannot pin host keys")
+		return "", false
+	}
+
+	return path, true
+}
+
+// pinnedHostKeys returns the known_hosts file of the host if its keys are
+// already pinned.
+func pinnedHostKeys(dir, host, port string) (string, bool) {
+	if dir == "" {
+		dir = defaultHostKeysDir
+	}
+
+	path := filepath.Join(expandUser(dir), fmt.Sprintf("%s_%s", host, port))
+	if _, err := os.Stat(path); err != nil {
+		return path, false
+	}
+
+	return path, true
+}
+
+func keyscan(host, port string) ([]byte, error) {
+	out, err := exec.Command("ssh-keyscan", "-T", "5", "-p", port, host).Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "ssh-keyscan failed")
+	}
+
+	if len(out) == 0 {
+		return nil, errors.New("ssh-keyscan found no keys")
+	}
+
+	return out, nil
+}
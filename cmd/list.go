@@ -2,21 +2,62 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/mhristof/germ/auditlog"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
 	"github.com/spf13/cobra"
 )
 
 var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
-	Short:   "List the keychain profiles",
+	Short:   "List the keychain profiles, favorites and most recently used first",
 	Run: func(cmd *cobra.Command, args []string) {
 		Verbose(cmd)
 
-		fmt.Println(keyChain.List())
+		k := findKeyChain(keyChainService)
+		accounts := k.List()
+		sortAccounts(accounts, k.Prefix, usage())
+
+		fmt.Println(accounts)
 	},
 }
 
+// sortAccounts orders the accounts by the usage of their profile. The other
+// accounts keep their order at the end.
+func sortAccounts(accounts []string, prefix string, u iterm.Usage) {
+	profile := func(account string) *iterm.Profile {
+		name := fmt.Sprintf("%s/%s", prefix, account)
+
+		return &iterm.Profile{Name: name, GUID: name}
+	}
+
+	sort.SliceStable(accounts, func(i, j int) bool {
+		return u.Less(profile(accounts[i]), profile(accounts[j]))
+	})
+}
+
+// usage returns the favorites and the last use of the profiles. A broken
+// audit log only loses the ordering.
+func usage() iterm.Usage {
+	used, err := auditlog.LastUsed(auditFile())
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Warn("Cannot read audit log")
+	}
+
+	return iterm.Usage{
+		Favorites: cfg.Favorites,
+		LastUsed:  used,
+	}
+}
+
 func init() {
+	listCmd.Flags().StringVarP(&keyChainService, "service", "s", keyChain.Service, "Keychain service to list")
+	listCmd.RegisterFlagCompletionFunc("service", completeServices)
+
 	rootCmd.AddCommand(listCmd)
 }
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortAccounts(t *testing.T) {
+	now := time.Now()
+	accounts := []string{"never", "old", "new", "unused", "token"}
+
+	sortAccounts(accounts, "custom", iterm.Usage{
+		Favorites: []string{"custom/tok*"},
+		LastUsed: map[string]time.Time{
+			"custom/old":  now.Add(-time.Hour),
+			"custom/new":  now,
+			"other/never": now,
+		},
+	})
+
+	assert.Equal(t, []string{"token", "new", "old", "never", "unused"}, accounts)
+}
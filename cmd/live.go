@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// livePython prints the properties of the profiles iTerm has loaded, with
+// the given GUIDs, as a dynamic profiles document. The full profiles are
+// fetched concurrently.
+var livePython = heredoc.Doc(`
+	#!/usr/bin/env python3.7
+
+	import asyncio
+	import json
+
+	import iterm2
+
+	GUIDS = set(json.loads({{ .GUIDs }}))
+
+	async def main(connection):
+		partials = await iterm2.PartialProfile.async_query(connection)
+		wanted = [p for p in partials if p.guid in GUIDS]
+		profiles = await asyncio.gather(*[p.async_get_full_profile() for p in wanted])
+		print(json.dumps({"Profiles": [p.all_properties for p in profiles]}))
+
+	iterm2.run_until_complete(main)
+`)
+
+// liveProfiles returns the profiles loaded in iTerm with the GUIDs of the
+// outputs, either in their files or generated, by GUID. iTerm returns every
+// key of a profile, including its defaults, so only the keys the files or
+// the generated profiles set are kept.
+func liveProfiles(outputs map[string]iterm.Profiles) map[string]iterm.Profile {
+	var keys = map[string]map[string]bool{}
+
+	for path, prof := range outputs {
+		current, _ := readProfiles(path)
+
+		for _, profile := range append(current.Profiles, prof.Profiles...) {
+			err := profileKeys(profile, keys)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"profile": profile.Name,
+					"err":     err,
+				}).Fatal("Cannot marshal profile")
+			}
+		}
+	}
+
+	var guids []string
+	for guid := range keys {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+
+	encoded, err := json.Marshal(guids)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot marshal GUIDs")
+	}
+
+	out := runPython("live-profiles", livePython, struct {
+		GUIDs string
+	}{
+		GUIDs: fmt.Sprintf("%q", string(encoded)),
+	})
+
+	ret, err := parseLive(out, keys)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot unmarshal the iTerm profiles")
+	}
+
+	return ret
+}
+
+// profileKeys adds the keys of the profile JSON to the keys of its GUID.
+func profileKeys(profile iterm.Profile, keys map[string]map[string]bool) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+
+	var values map[string]json.RawMessage
+	err = json.Unmarshal(data, &values)
+	if err != nil {
+		return err
+	}
+
+	if keys[profile.GUID] == nil {
+		keys[profile.GUID] = map[string]bool{}
+	}
+
+	for key := range values {
+		keys[profile.GUID][key] = true
+	}
+
+	return nil
+}
+
+// parseLive reads the profiles printed by livePython, with the given keys
+// only.
+func parseLive(data []byte, keys map[string]map[string]bool) (map[string]iterm.Profile, error) {
+	var doc struct {
+		Profiles []map[string]json.RawMessage `json:"Profiles"`
+	}
+
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret = map[string]iterm.Profile{}
+
+	for _, values := range doc.Profiles {
+		var guid string
+		err = json.Unmarshal(values["Guid"], &guid)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid Guid")
+		}
+
+		for key := range values {
+			if !keys[guid][key] {
+				delete(values, key)
+			}
+		}
+
+		data, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+
+		var profile iterm.Profile
+		err = json.Unmarshal(data, &profile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid profile %s", guid)
+		}
+
+		ret[guid] = profile
+	}
+
+	return ret, nil
+}
+
+// loadedProfiles returns the loaded profiles with the GUIDs of any of the
+// profiles, sorted by GUID.
+func loadedProfiles(loaded map[string]iterm.Profile, profiles ...[]iterm.Profile) iterm.Profiles {
+	var ret iterm.Profiles
+	var seen = map[string]bool{}
+
+	for _, list := range profiles {
+		for _, profile := range list {
+			live, found := loaded[profile.GUID]
+			if !found || seen[profile.GUID] {
+				continue
+			}
+
+			seen[profile.GUID] = true
+			ret.Profiles = append(ret.Profiles, live)
+		}
+	}
+
+	sortProfiles(ret.Profiles)
+
+	return ret
+}
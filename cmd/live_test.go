@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLive(t *testing.T) {
+	keys := map[string]map[string]bool{}
+	assert.Nil(t, profileKeys(*iterm.NewProfile("prod", map[string]string{"Command": "ssh prod"}), keys))
+
+	loaded, err := parseLive([]byte(`{"Profiles": [{
+		"Guid": "prod",
+		"Name": "prod",
+		"Command": "ssh prod-1",
+		"Normal Font": "Monaco 12",
+		"Ansi 0 Color": {"Red Component": 0},
+		"Scrollback Lines": 1000
+	}]}`), keys)
+	assert.Nil(t, err)
+
+	profile := loaded["prod"]
+	assert.Equal(t, "ssh prod-1", profile.Command)
+	assert.Nil(t, profile.Extra, "the iTerm defaults are left out")
+	assert.Nil(t, profile.ColorScheme)
+
+	_, err = parseLive([]byte(`{"Profiles": [{"Guid": 1}]}`), keys)
+	assert.NotNil(t, err)
+}
+
+func TestLoadedProfiles(t *testing.T) {
+	loaded := map[string]iterm.Profile{
+		"b": {GUID: "b", Name: "b"},
+		"a": {GUID: "a", Name: "a"},
+		"c": {GUID: "c", Name: "c"},
+	}
+
+	current := []iterm.Profile{{GUID: "b"}, {GUID: "missing"}}
+	generated := []iterm.Profile{{GUID: "b"}, {GUID: "a"}}
+
+	assert.Equal(t, iterm.Profiles{Profiles: []iterm.Profile{
+		{GUID: "a", Name: "a"},
+		{GUID: "b", Name: "b"},
+	}}, loadedProfiles(loaded, current, generated))
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/metrics"
+)
+
+// manifestCache is the name of the cache with the manifest of the last
+// generation.
+var manifestCache = "generate.json"
+
+// manifest records the input fingerprints and the profiles of every source of
+// the last generation, to skip the sources whose inputs did not change.
+type manifest struct {
+	Version string                    `json:"version"`
+	Sources map[string]manifestSource `json:"sources"`
+}
+
+type manifestSource struct {
+	Inputs   map[string]string `json:"inputs"`
+	Profiles []iterm.Profile   `json:"profiles"`
+}
+
+func newManifest() *manifest {
+	return &manifest{
+		Version: version,
+		Sources: map[string]manifestSource{},
+	}
+}
+
+func loadManifest() *manifest {
+	data, _, err := cache.Read(manifestCache)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Debug("No previous manifest")
+		return newManifest()
+	}
+
+	var m manifest
+	err = json.Unmarshal(data, &m)
+	if err != nil || m.Version != version {
+		log.WithFields(log.Fields{
+			"version": m.Version,
+			"err":     err,
+		}).Debug("Ignoring previous manifest")
+		return newManifest()
+	}
+
+	return &m
+}
+
+// apply replaces the sources with unchanged inputs with their profiles from
+// the manifest.
+func (m *manifest) apply(sources []source) []source {
+	var ret []source
+
+	for _, s := range sources {
+		previous, found := m.Sources[s.name]
+		hit := found && len(s.inputs) > 0 && cmp.Equal(previous.Inputs, fingerprints(s.inputs))
+
+		if len(s.inputs) > 0 {
+			metrics.CacheHit("sources", hit)
+		}
+
+		if hit {
+			log.WithFields(log.Fields{
+				"source": s.name,
+			}).Debug("Inputs did not change, reusing profiles")
+
+			profiles := previous.Profiles
+			s.profiles = func(context.Context) []iterm.Profile { return profiles }
+		}
+
+		ret = append(ret, s)
+	}
+
+	return ret
+}
+
+// update records the inputs and the results of the sources. The sources
+// without inputs are recorded as well, for `germ drift`. The sources that
+// timed out keep their previous record.
+func (m *manifest) update(sources []source, results [][]iterm.Profile, timedOut []string) {
+	previous := m.Sources
+	m.Sources = map[string]manifestSource{}
+
+	for i, s := range sources {
+		if contains(timedOut, s.name) {
+			if record, found := previous[s.name]; found {
+				m.Sources[s.name] = record
+			}
+			continue
+		}
+
+		m.Sources[s.name] = manifestSource{
+			Inputs:   fingerprints(s.inputs),
+			Profiles: results[i],
+		}
+	}
+}
+
+// removed returns the GUIDs of the profiles the sources do not generate
+// anymore, including the ones of the sources that were removed. The sources
+// that timed out or have no profiles, ie as they failed, are left out.
+func (m *manifest) removed(sources []source, results [][]iterm.Profile, timedOut []string) []string {
+	var ret []string
+	var ran = map[string]bool{}
+
+	for i, s := range sources {
+		ran[s.name] = true
+		if contains(timedOut, s.name) || len(results[i]) == 0 {
+			continue
+		}
+
+		var generated = map[string]bool{}
+		for _, profile := range results[i] {
+			generated[profile.GUID] = true
+		}
+
+		for _, profile := range m.Sources[s.name].Profiles {
+			if !generated[profile.GUID] {
+				ret = append(ret, profile.GUID)
+			}
+		}
+	}
+
+	for name, record := range m.Sources {
+		if ran[name] {
+			continue
+		}
+
+		for _, profile := range record.Profiles {
+			ret = append(ret, profile.GUID)
+		}
+	}
+
+	return ret
+}
+
+// previous replaces the results of the sources that timed out with their
+// profiles of the last generation, so they are not removed from iTerm.
+func (m *manifest) previous(sources []source, results [][]iterm.Profile, timedOut []string) {
+	for i, s := range sources {
+		if contains(timedOut, s.name) {
+			results[i] = m.Sources[s.name].Profiles
+		}
+	}
+}
+
+func (m *manifest) save() {
+	data, err := json.Marshal(m)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("Cannot marshal manifest")
+		return
+	}
+
+	err = cache.Write(manifestCache, data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("Cannot save manifest")
+	}
+}
+
+// fingerprints returns the fingerprints of the inputs, along with the germ
+// config, the custom smart selection rules and the user triggers every source
+// depends on.
+func fingerprints(inputs []string) map[string]string {
+	var ret = map[string]string{}
+
+	paths := append([]string{expandUser(configFile), expandUser("~/.germ.ssr.json"), expandUser(iterm.UserTriggers)}, inputs...)
+	for _, path := range paths {
+		ret[path] = fingerprint(path)
+	}
+
+	return ret
+}
+
+// fingerprint is the sha256 of a file, or of the entry names of a directory.
+func fingerprint(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "missing"
+	}
+
+	var data []byte
+	if info.IsDir() {
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return "unreadable"
+		}
+
+		var names []string
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		sort.Strings(names)
+
+		data = []byte(strings.Join(names, "\n"))
+	} else {
+		data, err = ioutil.ReadFile(path)
+		if err != nil {
+			return "unreadable"
+		}
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "config")
+	assert.Nil(t, ioutil.WriteFile(input, []byte("[profile dev]"), 0644))
+
+	var calls int
+	sources := []source{
+		{
+			name: "aws-config",
+			profiles: func(context.Context) []iterm.Profile {
+				calls++
+				return []iterm.Profile{*iterm.NewProfile("dev", map[string]string{})}
+			},
+			inputs: []string{input},
+		},
+		{
+			name: "keychain",
+			profiles: func(context.Context) []iterm.Profile {
+				calls++
+				return nil
+			},
+		},
+	}
+
+	m := newManifest()
+	m.update(sources, [][]iterm.Profile{sources[0].profiles(context.Background()), sources[1].profiles(context.Background())}, nil)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 2, len(m.Sources))
+
+	cached := m.apply(sources)
+	assert.Equal(t, "dev", cached[0].profiles(context.Background())[0].GUID)
+	cached[1].profiles(context.Background())
+	assert.Equal(t, 3, calls, "only the source without inputs runs again")
+
+	assert.Nil(t, ioutil.WriteFile(input, []byte("[profile prod]"), 0644))
+
+	calls = 0
+	m.apply(sources)[0].profiles(context.Background())
+	assert.Equal(t, 1, calls)
+
+	results := [][]iterm.Profile{nil, nil}
+	m.previous(sources, results, []string{"aws-config"})
+	assert.Equal(t, "dev", results[0][0].GUID, "a source that timed out keeps its profiles")
+	assert.Nil(t, results[1])
+
+	m.update(sources, [][]iterm.Profile{nil, nil}, []string{"aws-config"})
+	assert.Equal(t, "dev", m.Sources["aws-config"].Profiles[0].GUID, "a source that timed out keeps its record")
+}
+
+func TestManifestRemoved(t *testing.T) {
+	profiles := func(guids ...string) []iterm.Profile {
+		var ret []iterm.Profile
+		for _, guid := range guids {
+			ret = append(ret, *iterm.NewProfile(guid, map[string]string{}))
+		}
+
+		return ret
+	}
+
+	m := &manifest{Sources: map[string]manifestSource{
+		"consul":  {Profiles: profiles("node-1", "node-2")},
+		"nomad":   {Profiles: profiles("job-1")},
+		"plugin":  {Profiles: profiles("plugin-1")},
+		"deleted": {Profiles: profiles("old-1")},
+	}}
+
+	sources := []source{{name: "consul"}, {name: "nomad"}, {name: "plugin"}}
+	results := [][]iterm.Profile{profiles("node-1", "node-3"), nil, nil}
+
+	removed := m.removed(sources, results, []string{"plugin"})
+	assert.ElementsMatch(t, []string{"node-2", "old-1"}, removed, "failed and timed out sources keep their profiles")
+}
+
+func TestFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fingerprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	empty := fingerprint(dir)
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "project"), 0755))
+	assert.NotEqual(t, empty, fingerprint(dir))
+
+	assert.Equal(t, "missing", fingerprint(filepath.Join(dir, "missing")))
+
+	defer func(path string) { iterm.UserTriggers = path }(iterm.UserTriggers)
+	iterm.UserTriggers = filepath.Join(dir, "triggers.json")
+
+	before := fingerprints(nil)
+	assert.Nil(t, ioutil.WriteFile(iterm.UserTriggers, []byte(`[]`), 0644))
+	assert.NotEqual(t, before, fingerprints(nil), "the user triggers are part of every source")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/hashicorp"
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/tfstate"
+)
+
+// recordings is the cache directory of the API responses of --record, with
+// the layout of --mock-data.
+var recordings = "recordings"
+
+var (
+	// mockData is the directory with the fixtures of the remote calls, see
+	// useFixtures.
+	mockData string
+	record   bool
+	replay   bool
+)
+
+// useFixtures replaces the aws cli, `terraform state pull` and the Consul and
+// Nomad APIs with the JSON fixtures of the aws, terraform and hashicorp sub
+// directories, to generate the profiles without credentials. The manifest
+// and the profile creation times are not saved, so that the next real
+// generation is not affected.
+func useFixtures(dir string) {
+	aws.Fixtures(filepath.Join(dir, "aws"))
+	tfstate.Fixtures(filepath.Join(dir, "terraform"))
+	hashicorp.Fixtures(filepath.Join(dir, "hashicorp"))
+}
+
+// recordResponses saves the responses of the remote calls in the recordings
+// cache, replacing the previous recording.
+func recordResponses() {
+	dir := cache.Path(recordings)
+
+	err := os.RemoveAll(dir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"dir": dir,
+			"err": err,
+		}).Fatal("Cannot remove the previous recording")
+	}
+
+	aws.Record(recorder(filepath.Join(dir, "aws")))
+	tfstate.Record(recorder(filepath.Join(dir, "terraform")))
+	hashicorp.Record(recorder(filepath.Join(dir, "hashicorp")))
+}
+
+// recorder returns a function that saves the responses in the directory. A
+// response that cannot be saved is only reported, the generation goes on.
+func recorder(dir string) func(name string, data []byte) {
+	return func(name string, data []byte) {
+		path := filepath.Join(dir, name)
+
+		err := os.MkdirAll(dir, 0755)
+		if err == nil {
+			err = ioutil.WriteFile(path, data, 0644)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file": path,
+				"err":  err,
+			}).Warn("Cannot record response")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// Collision policies for profiles with the same name.
+const (
+	collisionWarn     = "warn"
+	collisionError    = "error"
+	collisionSuffix   = "suffix"
+	collisionPriority = "priority"
+)
+
+// resolveNames sanitizes and prefixes the profile names per source, and
+// handles the profiles with the same name according to the collision
+// policy.
+func resolveNames(all []source, results [][]iterm.Profile, names *config.Names) ([][]iterm.Profile, error) {
+	if names == nil {
+		names = &config.Names{}
+	}
+
+	var ret = make([][]iterm.Profile, len(results))
+	for i, profiles := range results {
+		batch := iterm.Profiles{Profiles: append([]iterm.Profile{}, profiles...)}
+		batch.Sanitize(names.Sanitize)
+
+		if prefix, found := config.Lookup(names.Prefixes, all[i].name); found {
+			var renames = map[string]string{}
+			for _, profile := range batch.Profiles {
+				renames[profile.GUID] = prefix + profile.Name
+			}
+			batch.Rename(renames)
+		}
+
+		ret[i] = batch.Profiles
+	}
+
+	// owner is the index of the source that keeps each name.
+	var owner = map[string]int{}
+	for i, profiles := range ret {
+		for _, profile := range profiles {
+			previous, found := owner[profile.Name]
+			if !found || (names.Collisions == collisionPriority && priority(names.Priority, all[i].name) < priority(names.Priority, all[previous].name)) {
+				owner[profile.Name] = i
+			}
+		}
+	}
+
+	var duplicates []string
+	for i := range ret {
+		var kept []iterm.Profile
+		var renames = map[string]string{}
+		batch := iterm.Profiles{Profiles: ret[i]}
+
+		for _, profile := range batch.Profiles {
+			if owner[profile.Name] == i {
+				kept = append(kept, profile)
+				continue
+			}
+
+			fields := log.Fields{
+				"profile": profile.Name,
+				"source":  all[i].name,
+				"kept":    all[owner[profile.Name]].name,
+			}
+
+			switch names.Collisions {
+			case collisionSuffix:
+				renames[profile.GUID] = fmt.Sprintf("%s-%s", profile.Name, all[i].name)
+				kept = append(kept, profile)
+			case collisionError:
+				duplicates = append(duplicates, fmt.Sprintf("%s (%s and %s)", profile.Name, all[owner[profile.Name]].name, all[i].name))
+			case collisionPriority:
+				log.WithFields(fields).Debug("Dropping profile of a lower priority source")
+			default:
+				log.WithFields(fields).Warn("Dropping duplicate profile")
+			}
+		}
+
+		batch.Profiles = kept
+		batch.Rename(renames)
+		ret[i] = batch.Profiles
+	}
+
+	if len(duplicates) > 0 {
+		return nil, errors.Errorf("duplicate profile names %v", duplicates)
+	}
+
+	return ret, nil
+}
+
+// priority is the index of the first pattern that matches the source, lower
+// is preferred.
+func priority(patterns []string, source string) int {
+	for i, pattern := range patterns {
+		if config.Match(pattern, source) {
+			return i
+		}
+	}
+
+	return len(patterns)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveNames(t *testing.T) {
+	all := []source{{name: "ansible"}, {name: "k8s"}}
+	results := func() [][]iterm.Profile {
+		return [][]iterm.Profile{
+			{*iterm.NewProfile("staging", map[string]string{}), *iterm.NewProfile("web", map[string]string{})},
+			{*iterm.NewProfile("staging", map[string]string{})},
+		}
+	}
+
+	var cases = []struct {
+		name  string
+		names *config.Names
+		exp   [][]string
+		err   bool
+	}{
+		{
+			name: "first one wins by default",
+			exp:  [][]string{{"staging", "web"}, nil},
+		},
+		{
+			name:  "suffix",
+			names: &config.Names{Collisions: "suffix"},
+			exp:   [][]string{{"staging", "web"}, {"staging-k8s"}},
+		},
+		{
+			name:  "priority",
+			names: &config.Names{Collisions: "priority", Priority: []string{"k8s"}},
+			exp:   [][]string{{"web"}, {"staging"}},
+		},
+		{
+			name:  "error",
+			names: &config.Names{Collisions: "error"},
+			err:   true,
+		},
+		{
+			name:  "prefixes",
+			names: &config.Names{Collisions: "error", Prefixes: map[string]string{"k*": "kube-"}},
+			exp:   [][]string{{"staging", "web"}, {"kube-staging"}},
+		},
+	}
+
+	for _, test := range cases {
+		resolved, err := resolveNames(all, results(), test.names)
+		assert.Equal(t, test.err, err != nil, test.name)
+		if test.err {
+			continue
+		}
+
+		var names [][]string
+		for _, profiles := range resolved {
+			var batch []string
+			for _, profile := range profiles {
+				assert.Equal(t, profile.Name, profile.GUID, test.name)
+				batch = append(batch, profile.Name)
+			}
+			names = append(names, batch)
+		}
+
+		assert.Equal(t, test.exp, names, test.name)
+	}
+}
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"bufio"
 	"encoding/csv"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"strings"
 	"syscall"
 
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/keychain"
 	"github.com/mhristof/germ/log"
 	"github.com/pkg/errors"
@@ -22,8 +25,11 @@ var (
 	keyChain = keychain.KeyChain{
 		Service:     "germ",
 		AccessGroup: "germ",
+		Prefix:      "custom",
 	}
-	exported bool
+	keyChainService string
+	exported        bool
+	newTriggers     string
 )
 
 var newCmd = &cobra.Command{
@@ -33,21 +39,102 @@ var newCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		Verbose(cmd)
 
-		keyChain.Add(newName, findPassword(file))
+		reader := bufio.NewReader(os.Stdin)
+
+		if newName == "" {
+			newName = prompt(reader, os.Stdout, "Name: ")
+		}
+
+		if !cmd.Flags().Changed("service") && len(keyChains()) > 1 {
+			var services []string
+			for _, k := range keyChains() {
+				services = append(services, k.Service)
+			}
+
+			keyChainService = choose(reader, os.Stdout, "Keychain service:", services)
+		}
+
+		if file == "" && !cmd.Flags().Changed("export") {
+			exported = confirm(reader, os.Stdout, fmt.Sprintf("Export the secret as %s", strings.ToUpper(newName)))
+		}
+
+		if !cmd.Flags().Changed("triggers") && confirm(reader, os.Stdout, "Add a trigger set to the profile") {
+			newTriggers = choose(reader, os.Stdout, "Trigger set:", triggerSetNames())
+		}
+
+		triggers, found := triggerSets[newTriggers]
+		if newTriggers != "" && !found {
+			log.WithFields(log.Fields{
+				"triggers": newTriggers,
+			}).Fatal("Unknown trigger set")
+		}
+
+		k := findKeyChain(keyChainService)
+		k.Add(newName, findPassword(file))
+
+		if newTriggers == "" {
+			return
+		}
+
+		// The profile is matched by its GUID, which the names config
+		// does not change.
+		err := config.AddOverride(overridesFile, config.Override{
+			Profiles: []string{fmt.Sprintf("%s/%s", k.Prefix, newName)},
+			Triggers: triggers,
+		})
+		if err != nil {
+			log.WithFields(log.Fields{
+				"overrides": overridesFile,
+				"err":       err,
+			}).Fatal("Cannot add the triggers")
+		}
 	},
 }
 
+// keyChains returns the default keychain and the ones defined in the config.
+func keyChains() []keychain.KeyChain {
+	ret := []keychain.KeyChain{keyChain}
+
+	for _, k := range cfg.KeyChains {
+		prefix := k.Prefix
+		if prefix == "" {
+			prefix = k.Service
+		}
+
+		ret = append(ret, keychain.KeyChain{
+			Service:     k.Service,
+			AccessGroup: keyChain.AccessGroup,
+			Prefix:      prefix,
+		})
+	}
+
+	return ret
+}
+
+func findKeyChain(service string) keychain.KeyChain {
+	for _, k := range keyChains() {
+		if k.Service == service {
+			return k
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"service": service,
+	}).Fatal("Unknown keychain service")
+
+	return keychain.KeyChain{}
+}
+
 func findPassword(file string) string {
 	if file != "" {
 		return handleFile(file)
 	}
 
-	fmt.Print("Enter secret:")
-	bytePassword, err := terminal.ReadPassword(int(syscall.Stdin))
-	if err != nil {
+	bytePassword := readSecret("Enter secret:")
+	if string(bytePassword) != string(readSecret("Confirm secret:")) {
 		log.WithFields(log.Fields{
-			"err": err,
-		}).Fatal("Cannot read secret")
+			"name": newName,
+		}).Fatal("Secrets do not match")
 	}
 
 	if exported {
@@ -57,6 +144,20 @@ func findPassword(file string) string {
 	return string(bytePassword)
 }
 
+func readSecret(message string) []byte {
+	fmt.Print(message)
+	defer fmt.Println()
+
+	bytePassword, err := terminal.ReadPassword(int(syscall.Stdin))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot read secret")
+	}
+
+	return bytePassword
+}
+
 func loadRootKey(file string) (string, error) {
 	cfg, err := ini.Load(file)
 	if err != nil {
@@ -157,10 +258,13 @@ func exportAWS(access, secret string) string {
 }
 
 func init() {
-	newCmd.Flags().StringVarP(&newName, "name", "", "", "Name of the profile")
+	newCmd.Flags().StringVarP(&newName, "name", "", "", "Name of the profile. Prompted for if missing")
 	newCmd.Flags().StringVarP(&file, "file", "f", "", "Credentials file to parse")
 	newCmd.Flags().BoolVarP(&exported, "export", "e", false, "Treat the password as an exported variable. The name of the variable will be the uppercased name provided.")
-	newCmd.MarkFlagRequired("name")
+	newCmd.Flags().StringVarP(&keyChainService, "service", "s", keyChain.Service, "Keychain service to store the secret in")
+	newCmd.RegisterFlagCompletionFunc("service", completeServices)
+	newCmd.Flags().StringVarP(&newTriggers, "triggers", "t", "", fmt.Sprintf("Trigger set of the profile, added to the overrides file, one of %s", strings.Join(triggerSetNames(), ", ")))
+	newCmd.Flags().StringVarP(&overridesFile, "overrides", "", overridesFile, "File to add the trigger set to")
 
 	rootCmd.AddCommand(newCmd)
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/log"
 	"gotest.tools/assert"
 )
@@ -74,3 +75,27 @@ func tempFile(contents, name string) (string, func()) {
 		os.RemoveAll(dir)
 	}
 }
+
+func TestKeyChains(t *testing.T) {
+	defer func(orig *config.Config) { cfg = orig }(cfg)
+
+	cfg = &config.Config{
+		KeyChains: []config.KeyChain{
+			{
+				Service: "work",
+			},
+			{
+				Service: "personal",
+				Prefix:  "home",
+			},
+		},
+	}
+
+	var prefixes []string
+	for _, k := range keyChains() {
+		prefixes = append(prefixes, k.Prefix)
+	}
+
+	assert.DeepEqual(t, []string{"custom", "work", "home"}, prefixes)
+	assert.Equal(t, "home", findKeyChain("personal").Prefix)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	promptFormat = `{{ .Profile }}{{ if .Region }} {{ .Region }}{{ end }}{{ if .TTL }} ({{ .TTL }}){{ end }}`
+	promptTTL    bool
+)
+
+// promptContext is the context of the current shell, available to the
+// prompt format.
+type promptContext struct {
+	Profile    string
+	Account    string
+	Region     string
+	AWSProfile string
+	TTL        string
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Print the germ context of the current shell for prompt frameworks",
+	Long: heredoc.Doc(`
+		Prints the profile, account and region of the current shell, from the
+		GERM_ variables of the generated sessions or of 'germ env', along with
+		the time left of the AWS session from the STS/SSO cache. Nothing is
+		printed outside a germ profile. The command does not call AWS or
+		generate the profiles, so that it can run on every prompt, ie with
+		starship
+
+		[custom.germ]
+		command = "germ prompt"
+		when = "test -n \"$GERM_PROFILE\""
+		format = "[$output]($style) "
+
+		or with powerlevel10k
+
+		function prompt_germ() {
+		  p10k segment -t "$(germ prompt)"
+		}
+
+		The fields of --format are .Profile, .Account, .Region, .AWSProfile
+		and .TTL.
+	`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		context := currentContext(os.Getenv)
+		if context.Profile == "" {
+			return
+		}
+
+		if promptTTL && context.AWSProfile != "" {
+			if expires, found := aws.Expiry(context.AWSProfile, AWSConfig, AWSCredentials, ssoCache); found {
+				context.TTL = aws.TTL(expires, time.Now())
+			}
+		}
+
+		out, err := renderPrompt(promptFormat, context)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"format": promptFormat,
+				"err":    err,
+			}).Fatal("Cannot render prompt")
+		}
+
+		fmt.Println(out)
+	},
+}
+
+// currentContext returns the context of the shell from its environment. The
+// shells with an AWS_PROFILE that were not started by germ use it as their
+// profile.
+func currentContext(getenv func(string) string) promptContext {
+	context := promptContext{
+		Profile:    getenv("GERM_PROFILE"),
+		Account:    getenv("GERM_ACCOUNT"),
+		Region:     getenv("GERM_REGION"),
+		AWSProfile: getenv("AWS_PROFILE"),
+	}
+
+	if context.Profile == "" {
+		context.Profile = context.AWSProfile
+	}
+
+	if context.Region == "" {
+		context.Region = getenv("AWS_REGION")
+	}
+
+	return context
+}
+
+func renderPrompt(format string, context promptContext) (string, error) {
+	t, err := template.New("prompt").Parse(format)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse prompt format")
+	}
+
+	var out bytes.Buffer
+	err = t.Execute(&out, context)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot render prompt format")
+	}
+
+	return out.String(), nil
+}
+
+func init() {
+	promptCmd.Flags().StringVarP(&promptFormat, "format", "f", promptFormat, "Template of the output")
+	promptCmd.Flags().BoolVarP(&promptTTL, "ttl", "t", true, "Show the time left of the AWS session")
+
+	rootCmd.AddCommand(promptCmd)
+}
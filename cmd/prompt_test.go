@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	var cases = []struct {
+		name   string
+		env    map[string]string
+		format string
+		exp    string
+	}{
+		{
+			name: "germ session",
+			env:  map[string]string{"GERM_PROFILE": "prod", "GERM_REGION": "eu-west-1", "AWS_PROFILE": "prod"},
+			exp:  "prod eu-west-1",
+		},
+		{
+			name: "aws profile outside germ",
+			env:  map[string]string{"AWS_PROFILE": "dev", "AWS_REGION": "us-east-1"},
+			exp:  "dev us-east-1",
+		},
+		{
+			name:   "custom format",
+			env:    map[string]string{"GERM_PROFILE": "prod", "GERM_ACCOUNT": "123456789012"},
+			format: "{{ .Account }}/{{ .Profile }}",
+			exp:    "123456789012/prod",
+		},
+	}
+
+	for _, test := range cases {
+		format := test.format
+		if format == "" {
+			format = promptFormat
+		}
+
+		context := currentContext(func(key string) string { return test.env[key] })
+		out, err := renderPrompt(format, context)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.exp, out, test.name)
+	}
+
+	_, err := renderPrompt("{{ .Missing", promptContext{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"text/template"
+
+	"github.com/mhristof/germ/log"
+)
+
+// runPython renders the iTerm python API script with the given data, runs it
+// and returns its output.
+func runPython(name, script string, data interface{}) []byte {
+	tmpl, err := template.New(name).Parse(script)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Could not create template")
+
+	}
+
+	rendered := new(bytes.Buffer)
+	err = tmpl.Execute(rendered, data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Could not render template")
+
+	}
+
+	tmpfile, err := ioutil.TempFile("", name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Could not create temp file")
+
+	}
+	defer os.Remove(tmpfile.Name())
+
+	log.WithFields(log.Fields{
+		"script": tmpfile.Name(),
+	}).Debug("Running python script")
+
+	if _, err := tmpfile.Write(rendered.Bytes()); err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Could not write to file")
+
+	}
+	if err := tmpfile.Close(); err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Could not close file")
+	}
+
+	python3, err := exec.LookPath("python3")
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Could not find python3")
+
+	}
+
+	pCmd := exec.Command(python3, tmpfile.Name())
+	pCmd.Stderr = os.Stderr
+	out, err := pCmd.Output()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"name": name,
+			"err":  err,
+		}).Fatal("Could not run python script")
+	}
+
+	return out
+}
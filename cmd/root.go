@@ -3,13 +3,19 @@ package cmd
 import (
 	"os"
 
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/log"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dryRun  bool
-	version = "devel"
+	dryRun     bool
+	version    = "devel"
+	configFile string
+	cfg        = &config.Config{}
+	logFormat  string
+	logFile    string
+	logLevel   string
 )
 
 var rootCmd = &cobra.Command{
@@ -34,10 +40,49 @@ func Verbose(cmd *cobra.Command) {
 	}
 }
 
+func initLog() {
+	err := log.SetLevelName(logLevel)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"log-level": logLevel,
+			"err":       err,
+		}).Fatal("Invalid log level")
+	}
+
+	err = log.SetFormat(logFormat)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"log-format": logFormat,
+			"err":        err,
+		}).Fatal("Invalid log format")
+	}
+
+	if logFile == "" {
+		return
+	}
+
+	err = log.SetFile(logFile)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"log-file": logFile,
+			"err":      err,
+		}).Fatal("Cannot open log file")
+	}
+}
+
+func initConfig() {
+	cfg = config.Load(configFile)
+}
+
 func init() {
-	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dryrun", "n", false, "Dry run mode, no changes will be made on the system")
-	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Increase verbosity")
+	cobra.OnInitialize(initLog, initConfig)
 
+	rootCmd.PersistentFlags().BoolVarP(&dryRun, "dryrun", "n", false, "Dry run mode, no changes will be made on the system")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Increase verbosity, same as --log-level debug")
+	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "", "info", "Log level, one of debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVarP(&logFormat, "log-format", "", "console", "Log format, either console or json")
+	rootCmd.PersistentFlags().StringVarP(&logFile, "log-file", "", "", "Append the logs to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVarP(&configFile, "config", "", config.Path, "Germ configuration file")
 }
 
 func Execute() {
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	secretFile string
+	secretTool string
+)
+
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "Manage the keychain secrets",
+}
+
+var secretExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the keychain secrets to an encrypted file, to move them to another machine",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+		checkSecretTool()
+
+		var secrets = map[string]map[string]string{}
+		for _, k := range keyChains() {
+			secrets[k.Service] = map[string]string{}
+
+			for _, name := range k.List() {
+				secrets[k.Service][name] = k.Get(name)
+			}
+		}
+
+		data, err := json.Marshal(secrets)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err": err,
+			}).Fatal("Cannot marshal secrets")
+		}
+
+		err = runCrypto(encryptArgs(secretTool, secretFile), data, nil)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file": secretFile,
+				"tool": secretTool,
+				"err":  err,
+			}).Fatal("Cannot encrypt secrets")
+		}
+	},
+}
+
+var secretImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import the keychain secrets from a file created by 'secret export'",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+		checkSecretTool()
+
+		var data bytes.Buffer
+		err := runCrypto(decryptArgs(secretTool, secretFile), nil, &data)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file": secretFile,
+				"tool": secretTool,
+				"err":  err,
+			}).Fatal("Cannot decrypt secrets")
+		}
+
+		var secrets map[string]map[string]string
+		err = json.Unmarshal(data.Bytes(), &secrets)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file": secretFile,
+				"err":  err,
+			}).Fatal("Cannot parse secrets")
+		}
+
+		for service, values := range secrets {
+			k := findKeyChain(service)
+
+			existing := map[string]bool{}
+			for _, name := range k.List() {
+				existing[name] = true
+			}
+
+			for name, value := range values {
+				if existing[name] {
+					log.WithFields(log.Fields{
+						"service": service,
+						"name":    name,
+					}).Warn("Secret already exists, skipping")
+					continue
+				}
+
+				if dryRun {
+					fmt.Println(fmt.Sprintf("%s/%s", service, name))
+					continue
+				}
+
+				k.Add(name, value)
+			}
+		}
+	},
+}
+
+var secretEnvCmd = &cobra.Command{
+	Use:               "env NAME",
+	Short:             "Print the secret, to be evaluated in the current shell",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSecretArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		k := findKeyChain(keyChainService)
+		fmt.Println(k.Get(args[0]))
+	},
+}
+
+func checkSecretTool() {
+	if secretTool != "gpg" && secretTool != "age" {
+		log.WithFields(log.Fields{
+			"tool": secretTool,
+		}).Fatal("Unsupported encryption tool")
+	}
+}
+
+func encryptArgs(tool, file string) []string {
+	if tool == "age" {
+		return []string{"age", "--passphrase", "--armor", "--output", file}
+	}
+
+	return []string{"gpg", "--symmetric", "--armor", "--output", file}
+}
+
+func decryptArgs(tool, file string) []string {
+	if tool == "age" {
+		return []string{"age", "--decrypt", file}
+	}
+
+	return []string{"gpg", "--decrypt", file}
+}
+
+func runCrypto(args []string, stdin []byte, stdout *bytes.Buffer) error {
+	c := exec.Command(args[0], args[1:]...)
+	c.Stdin = bytes.NewReader(stdin)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	if stdout != nil {
+		c.Stdout = stdout
+	}
+
+	return c.Run()
+}
+
+func init() {
+	for _, c := range []*cobra.Command{secretExportCmd, secretImportCmd} {
+		c.Flags().StringVarP(&secretFile, "encrypted", "f", "", "Encrypted file with the secrets")
+		c.Flags().StringVarP(&secretTool, "tool", "t", "gpg", "Encryption tool to use, gpg or age")
+		c.MarkFlagRequired("encrypted")
+
+		secretCmd.AddCommand(c)
+	}
+
+	secretEnvCmd.Flags().StringVarP(&keyChainService, "service", "s", keyChain.Service, "Keychain service of the secret")
+	secretEnvCmd.RegisterFlagCompletionFunc("service", completeServices)
+	secretCmd.AddCommand(secretEnvCmd)
+
+	rootCmd.AddCommand(secretCmd)
+}
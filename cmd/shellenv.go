@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var shellenvCmd = &cobra.Command{
+	Use:   "shellenv PROFILE",
+	Short: "Print the GERM_ variables of a profile as shell exports",
+	Long: heredoc.Doc(`
+		Every generated profile starts its session with GERM_PROFILE,
+		GERM_ACCOUNT and GERM_REGION in the environment. This prints the same
+		variables for shells that were not started by germ, ie
+
+		eval "$(germ shellenv prod)"
+	`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, _ := lastProfiles()
+
+		profile, found := findProfile(prof, args[0])
+		if !found {
+			log.WithFields(log.Fields{
+				"profile": args[0],
+			}).Fatal("Profile not found")
+		}
+
+		shellenv(os.Stdout, profile)
+	},
+}
+
+func shellenv(w io.Writer, profile *iterm.Profile) {
+	for _, env := range profile.ShellEnv() {
+		fmt.Fprintf(w, "export %s\n", env)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(shellenvCmd)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/tfstate"
+	"github.com/spf13/cobra"
+)
+
+const (
+	sshConfigBegin = "# BEGIN germ managed hosts, generated by 'germ ssh-config', do not edit"
+	sshConfigEnd   = "# END germ managed hosts"
+)
+
+var (
+	sshConfigFile  string
+	sshConfigUser  string
+	sshConfigWrite bool
+)
+
+var sshConfigCmd = &cobra.Command{
+	Use:   "ssh-config",
+	Short: "Print, or write, the ssh and SSM hosts of the last generation as an ssh config section",
+	Long: `Hosts named after the generated profiles, for the tools outside iTerm,
+ie VS Code Remote SSH or rsync. The SSM instances connect with a ProxyCommand.
+--write replaces the germ managed section of the ssh config, and leaves the
+rest of the file as it is.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, _ := lastProfiles()
+		block := sshConfig(prof.Profiles, sshConfigUser)
+		if !sshConfigWrite {
+			fmt.Print(block)
+			return
+		}
+
+		path := expandUser(sshConfigFile)
+		current, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			log.WithFields(log.Fields{
+				"file": path,
+				"err":  err,
+			}).Fatal("Cannot read ssh config")
+		}
+
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+		if err == nil {
+			err = ioutil.WriteFile(path, []byte(replaceBlock(string(current), block, sshConfigBegin, sshConfigEnd)), 0600)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"file": path,
+				"err":  err,
+			}).Fatal("Cannot write ssh config")
+		}
+	},
+}
+
+// sshConfig returns the managed section with a Host per ssh or SSM profile.
+// The SSM instances without a user in their command get the default user.
+func sshConfig(profiles []iterm.Profile, defaultUser string) string {
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	var out strings.Builder
+	out.WriteString(sshConfigBegin + "\n")
+
+	for i := range profiles {
+		profile := &profiles[i]
+		if !isSSHHost(profile) {
+			continue
+		}
+
+		user, host, port, _ := profile.SSHTarget()
+
+		if ssm, found := instance(profile); found {
+			if user == "" {
+				user = defaultUser
+			}
+
+			proxy := tfstate.ProxyCommand(ssm.Region)
+			if ssm.Profile != "" {
+				proxy = fmt.Sprintf("env AWS_PROFILE=%s %s", ssm.Profile, proxy)
+			}
+
+			fmt.Fprintf(&out, "\nHost %s\n    HostName %s\n    User %s\n    ProxyCommand %s\n", profile.Name, ssm.ID, user, proxy)
+			continue
+		}
+
+		fmt.Fprintf(&out, "\nHost %s\n    HostName %s\n", profile.Name, host)
+		if user != "" {
+			fmt.Fprintf(&out, "    User %s\n", user)
+		}
+		if port != "22" {
+			fmt.Fprintf(&out, "    Port %s\n", port)
+		}
+	}
+
+	out.WriteString(sshConfigEnd + "\n")
+
+	return out.String()
+}
+
+// isSSHHost reports whether the profile is an ssh or SSM host with a name
+// that can be an ssh config Host.
+func isSSHHost(profile *iterm.Profile) bool {
+	if strings.ContainsAny(profile.Name, " \t") {
+		return false
+	}
+
+	if _, found := instance(profile); found {
+		return true
+	}
+
+	_, _, _, found := profile.SSHTarget()
+
+	return found
+}
+
+// replaceBlock replaces the managed section of the config, between the begin
+// and end markers, with the block, or appends it.
+func replaceBlock(config, block, beginMarker, endMarker string) string {
+	begin := strings.Index(config, beginMarker)
+	end := strings.Index(config, endMarker)
+
+	if begin < 0 || end < begin {
+		if config != "" && !strings.HasSuffix(config, "\n") {
+			config += "\n"
+		}
+		if config != "" {
+			config += "\n"
+		}
+
+		return config + block
+	}
+
+	rest := strings.TrimPrefix(config[end+len(endMarker):], "\n")
+
+	return config[:begin] + block + rest
+}
+
+func init() {
+	sshConfigCmd.Flags().StringVarP(&sshConfigFile, "file", "", "~/.ssh/config", "ssh config file to write")
+	sshConfigCmd.Flags().StringVarP(&sshConfigUser, "user", "u", "ec2-user", "User of the SSM instances")
+	sshConfigCmd.Flags().BoolVarP(&sshConfigWrite, "write", "w", false, "Write the hosts to the ssh config file")
+
+	rootCmd.AddCommand(sshConfigCmd)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHConfig(t *testing.T) {
+	profiles := []iterm.Profile{
+		*iterm.NewProfile("web", map[string]string{"Command": "ssh -p 2222 admin@web.example.com"}),
+		*iterm.NewProfile("tf-app", map[string]string{
+			"Command": "/usr/bin/env AWS_PROFILE=prod aws ssm start-session --target i-0123 --region eu-west-1",
+			"Tags":    "instance=i-0123",
+		}),
+		*iterm.NewProfile("dev", map[string]string{}),
+	}
+
+	assert.Equal(t, heredoc.Doc(`
+		# BEGIN germ managed hosts, generated by 'germ ssh-config', do not edit
+
+		Host tf-app
+		    HostName i-0123
+		    User ec2-user
+		    ProxyCommand env AWS_PROFILE=prod aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p --region eu-west-1
+
+		Host web
+		    HostName web.example.com
+		    User admin
+		    Port 2222
+		# END germ managed hosts
+	`), sshConfig(profiles, "ec2-user"))
+}
+
+func TestReplaceBlock(t *testing.T) {
+	block := sshConfigBegin + "\nHost new\n" + sshConfigEnd + "\n"
+
+	var cases = []struct {
+		name   string
+		config string
+		exp    string
+	}{
+		{
+			name: "empty config",
+			exp:  block,
+		},
+		{
+			name:   "append",
+			config: "Host mine\n    User me",
+			exp:    "Host mine\n    User me\n\n" + block,
+		},
+		{
+			name:   "replace",
+			config: "Host mine\n\n" + sshConfigBegin + "\nHost old\n" + sshConfigEnd + "\n\nHost after\n",
+			exp:    "Host mine\n\n" + block + "\nHost after\n",
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, replaceBlock(test.config, block, sshConfigBegin, sshConfigEnd), test.name)
+	}
+}
@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"regexp"
+	"syscall"
+
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	instanceID = regexp.MustCompile(`^m?i-[0-9a-f]+$`)
+	targetFlag = regexp.MustCompile(`--target (\S+)`)
+	regionFlag = regexp.MustCompile(`--region (\S+)`)
+)
+
+var (
+	ssmASG             string
+	ssmProfile         string
+	ssmRegion          string
+	ssmConnect         bool
+	ssmUser            string
+	ssmKey             string
+	ssmInstanceConnect bool
+)
+
+var ssmCmd = &cobra.Command{
+	Use:   "ssm",
+	Short: "SSM session helpers",
+}
+
+var pickInstanceCmd = &cobra.Command{
+	Use:   "pick-instance --asg NAME",
+	Short: "Print a healthy instance of an autoscaling group, or start an SSM session to it",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		ids, err := aws.HealthyInstances(ssmProfile, ssmRegion, ssmASG)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"asg": ssmASG,
+				"err": err,
+			}).Fatal("Cannot list the autoscaling group instances")
+		}
+
+		if len(ids) == 0 {
+			log.WithFields(log.Fields{
+				"asg": ssmASG,
+			}).Fatal("No healthy instances")
+		}
+
+		if !ssmConnect {
+			fmt.Println(ids[0])
+			return
+		}
+
+		startSession(ids[0], ssmProfile, ssmRegion)
+	},
+}
+
+var connectCmd = &cobra.Command{
+	Use:               "connect NAME|ID",
+	Short:             "Start an SSM session to an instance of the last generation, by profile name or instance id",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeInstances,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, _ := lastProfiles()
+		instance, found := findInstance(args[0], prof.Profiles)
+		if !found {
+			log.WithFields(log.Fields{
+				"instance": args[0],
+			}).Fatal("Instance not found, run 'germ generate --write' first")
+		}
+
+		if ssmProfile != "" {
+			instance.Profile = ssmProfile
+		}
+
+		if ssmRegion != "" {
+			instance.Region = ssmRegion
+		}
+
+		startSession(instance.ID, instance.Profile, instance.Region)
+	},
+}
+
+var pushKeyCmd = &cobra.Command{
+	Use:               "push-key NAME|ID",
+	Short:             "Add your public key to the authorized_keys of an instance with SSM, for ssh and scp over SSM",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeInstances,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		prof, _ := lastProfiles()
+		instance, found := findInstance(args[0], prof.Profiles)
+		if !found {
+			log.WithFields(log.Fields{
+				"instance": args[0],
+			}).Fatal("Instance not found, run 'germ generate --write' first")
+		}
+
+		if ssmProfile != "" {
+			instance.Profile = ssmProfile
+		}
+
+		if ssmRegion != "" {
+			instance.Region = ssmRegion
+		}
+
+		key, err := ioutil.ReadFile(expandUser(ssmKey))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"key": ssmKey,
+				"err": err,
+			}).Fatal("Cannot read public key")
+		}
+
+		push := aws.PushKey
+		if ssmInstanceConnect {
+			push = aws.SendSSHPublicKey
+		}
+
+		err = push(instance.Profile, instance.Region, instance.ID, ssmUser, string(key))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"instance": instance.ID,
+				"err":      err,
+			}).Fatal("Cannot push public key")
+		}
+	},
+}
+
+// ssmInstance is an SSM session target of the generated profiles.
+type ssmInstance struct {
+	ID      string
+	Profile string
+	Region  string
+}
+
+// instance returns the SSM target of the profile, if it is an SSM session to
+// an instance.
+func instance(profile *iterm.Profile) (ssmInstance, bool) {
+	var ret ssmInstance
+
+	if id, found := profile.FindTag("instance"); found {
+		ret.ID = id
+	} else if match := targetFlag.FindStringSubmatch(profile.Command); match != nil && instanceID.MatchString(match[1]) {
+		ret.ID = match[1]
+	} else {
+		return ret, false
+	}
+
+	vars := profile.TriggerVariables()
+	ret.Profile = vars.AWSProfile
+	ret.Region = vars.Region
+	if match := regionFlag.FindStringSubmatch(profile.Command); match != nil && ret.Region == "" {
+		ret.Region = match[1]
+	}
+
+	return ret, true
+}
+
+// findInstance returns the instance of the profile with the name, or the
+// instance with the id. Unknown ids are returned as they are.
+func findInstance(name string, profiles []iterm.Profile) (ssmInstance, bool) {
+	for i := range profiles {
+		ret, found := instance(&profiles[i])
+		if !found {
+			continue
+		}
+
+		if profiles[i].Name == name || ret.ID == name {
+			return ret, true
+		}
+	}
+
+	if instanceID.MatchString(name) {
+		return ssmInstance{ID: name}, true
+	}
+
+	return ssmInstance{}, false
+}
+
+// startSession replaces germ with an SSM session to the instance.
+func startSession(id, profile, region string) {
+	args := []string{"aws", "ssm", "start-session", "--target", id}
+	if profile != "" {
+		args = append(args, "--profile", profile)
+	}
+	if region != "" {
+		args = append(args, "--region", region)
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err == nil {
+		err = syscall.Exec(path, args, os.Environ())
+	}
+
+	log.WithFields(log.Fields{
+		"instance": id,
+		"err":      err,
+	}).Fatal("Cannot start the SSM session")
+}
+
+func init() {
+	pickInstanceCmd.Flags().StringVarP(&ssmASG, "asg", "", "", "Name of the autoscaling group")
+	pickInstanceCmd.Flags().StringVarP(&ssmProfile, "profile", "", "", "AWS profile, defaults to AWS_PROFILE")
+	pickInstanceCmd.Flags().StringVarP(&ssmRegion, "region", "", "", "AWS region")
+	pickInstanceCmd.Flags().BoolVarP(&ssmConnect, "connect", "", false, "Start an SSM session to the instance")
+	pickInstanceCmd.MarkFlagRequired("asg")
+
+	connectCmd.Flags().StringVarP(&ssmProfile, "profile", "", "", "AWS profile, defaults to the one of the instance profile")
+	connectCmd.Flags().StringVarP(&ssmRegion, "region", "", "", "AWS region, defaults to the one of the instance profile")
+
+	pushKeyCmd.Flags().StringVarP(&ssmProfile, "profile", "", "", "AWS profile, defaults to the one of the instance profile")
+	pushKeyCmd.Flags().StringVarP(&ssmRegion, "region", "", "", "AWS region, defaults to the one of the instance profile")
+	pushKeyCmd.Flags().StringVarP(&ssmUser, "user", "u", "ec2-user", "User of the instance")
+	pushKeyCmd.Flags().StringVarP(&ssmKey, "key", "k", "~/.ssh/id_ed25519.pub", "Public key to push")
+	pushKeyCmd.Flags().BoolVarP(&ssmInstanceConnect, "instance-connect", "", false, "Push the key with EC2 Instance Connect, valid for 60 seconds")
+
+	ssmCmd.AddCommand(pickInstanceCmd)
+	ssmCmd.AddCommand(pushKeyCmd)
+	ssmCmd.AddCommand(connectCmd)
+	rootCmd.AddCommand(ssmCmd)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindInstance(t *testing.T) {
+	profiles := []iterm.Profile{
+		*iterm.NewProfile("tf-web-0", map[string]string{
+			"Command": "/usr/bin/env AWS_PROFILE=prod aws ssm start-session --target i-0123 --region eu-west-1",
+			"Tags":    "tfstate,instance=i-0123",
+		}),
+		*iterm.NewProfile("cfn-app-bastion", map[string]string{
+			"Command": "/usr/bin/env AWS_PROFILE=dev aws ssm start-session --target i-4567",
+		}),
+		*iterm.NewProfile("dev", map[string]string{}),
+	}
+
+	var cases = []struct {
+		name  string
+		exp   ssmInstance
+		found bool
+	}{
+		{name: "tf-web-0", exp: ssmInstance{ID: "i-0123", Profile: "prod", Region: "eu-west-1"}, found: true},
+		{name: "i-4567", exp: ssmInstance{ID: "i-4567", Profile: "dev"}, found: true},
+		{name: "i-89ab", exp: ssmInstance{ID: "i-89ab"}, found: true},
+		{name: "dev"},
+	}
+
+	for _, test := range cases {
+		instance, found := findInstance(test.name, profiles)
+		assert.Equal(t, test.found, found, test.name)
+		assert.Equal(t, test.exp, instance, test.name)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	themesDir = expandUser(config.ThemesDir)
+	// schemesURL is where the iTerm2-Color-Schemes are downloaded from, by name.
+	schemesURL = "https://raw.githubusercontent.com/mbadolato/iTerm2-Color-Schemes/master/schemes"
+)
+
+var themesCmd = &cobra.Command{
+	Use:   "themes",
+	Short: "Manage the color schemes used by the generated profiles",
+	Long: heredoc.Doc(`
+		Color schemes are .itermcolors files, for example from
+		https://github.com/mbadolato/iTerm2-Color-Schemes. Once added, map them
+		to profiles in the 'colorSchemes' section of the config.
+	`),
+}
+
+var themesAddCmd = &cobra.Command{
+	Use:   "add NAME|URL",
+	Short: "Download an .itermcolors file, by iTerm2-Color-Schemes name or URL",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		name, source := schemeSource(args[0])
+
+		data, err := download(source)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"url": source,
+				"err": err,
+			}).Fatal("Cannot download color scheme")
+		}
+
+		if _, err := iterm.ParseColorScheme(data); err != nil {
+			log.WithFields(log.Fields{
+				"url": source,
+				"err": err,
+			}).Fatal("Invalid color scheme")
+		}
+
+		dest := filepath.Join(themesDir, fmt.Sprintf("%s.itermcolors", name))
+		if dryRun {
+			fmt.Println(dest)
+			return
+		}
+
+		err = os.MkdirAll(themesDir, 0755)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"dir": themesDir,
+				"err": err,
+			}).Fatal("Cannot create themes directory")
+		}
+
+		err = ioutil.WriteFile(dest, data, 0644)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"dest": dest,
+				"err":  err,
+			}).Fatal("Cannot write color scheme")
+		}
+
+		fmt.Printf("Added %s, use it with\n\ncolorSchemes:\n  '*': %s\n", name, name)
+	},
+}
+
+var themesListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the added color schemes",
+	Aliases: []string{"ls"},
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		files, err := filepath.Glob(filepath.Join(themesDir, "*.itermcolors"))
+		if err != nil {
+			log.WithFields(log.Fields{
+				"dir": themesDir,
+				"err": err,
+			}).Fatal("Cannot list color schemes")
+		}
+
+		sort.Strings(files)
+		for _, file := range files {
+			fmt.Println(strings.TrimSuffix(filepath.Base(file), ".itermcolors"))
+		}
+	},
+}
+
+// schemeSource returns the name and download URL of a scheme given either as
+// a URL or as an iTerm2-Color-Schemes name.
+func schemeSource(arg string) (string, string) {
+	if strings.HasPrefix(arg, "http://") || strings.HasPrefix(arg, "https://") {
+		name := path.Base(arg)
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			name = unescaped
+		}
+
+		return strings.TrimSuffix(name, ".itermcolors"), arg
+	}
+
+	name := strings.TrimSuffix(arg, ".itermcolors")
+
+	return name, fmt.Sprintf("%s/%s.itermcolors", schemesURL, url.PathEscape(name))
+}
+
+func download(source string) ([]byte, error) {
+	resp, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+func init() {
+	themesCmd.AddCommand(themesAddCmd)
+	themesCmd.AddCommand(themesListCmd)
+	rootCmd.AddCommand(themesCmd)
+}
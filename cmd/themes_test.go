@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemeSource(t *testing.T) {
+	var cases = []struct {
+		name   string
+		arg    string
+		scheme string
+		source string
+	}{
+		{
+			name:   "scheme name",
+			arg:    "Solarized Dark",
+			scheme: "Solarized Dark",
+			source: schemesURL + "/Solarized%20Dark.itermcolors",
+		},
+		{
+			name:   "url",
+			arg:    "https://example.com/themes/Solarized%20Dark.itermcolors",
+			scheme: "Solarized Dark",
+			source: "https://example.com/themes/Solarized%20Dark.itermcolors",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			scheme, source := schemeSource(test.arg)
+			assert.Equal(t, test.scheme, scheme, test.name)
+			assert.Equal(t, test.source, source, test.name)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// runJQ runs the jq filter over the JSON document, with jq from the PATH.
+func runJQ(filter string, data []byte) ([]byte, error) {
+	var stderr bytes.Buffer
+
+	jq := exec.Command("jq", filter)
+	jq.Stdin = bytes.NewReader(data)
+	jq.Stderr = &stderr
+
+	out, err := jq.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "jq failed: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/mhristof/germ/log"
+	"github.com/mhristof/germ/release"
+	"github.com/spf13/cobra"
+)
+
+var versionCheck bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the germ version",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		fmt.Println(version)
+
+		if !versionCheck {
+			return
+		}
+
+		latest := latestRelease()
+		if release.Newer(version, latest.TagName) {
+			fmt.Println(fmt.Sprintf("germ %s is available, run 'germ upgrade'", latest.TagName))
+			return
+		}
+
+		fmt.Println("germ is up to date")
+	},
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:     "upgrade",
+	Aliases: []string{"update"},
+	Short:   "Replace germ with the latest release",
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		latest := latestRelease()
+		if !release.Newer(version, latest.TagName) {
+			fmt.Println(fmt.Sprintf("germ %s is up to date", version))
+			return
+		}
+
+		path, err := os.Executable()
+		if err == nil {
+			path, err = filepath.EvalSymlinks(path)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err": err,
+			}).Fatal("Cannot find the germ binary")
+		}
+
+		asset := fmt.Sprintf("germ.%s", runtime.GOOS)
+		data, err := latest.Download(asset)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"release": latest.TagName,
+				"asset":   asset,
+				"err":     err,
+			}).Fatal("Cannot download release")
+		}
+
+		if dryRun {
+			fmt.Println(fmt.Sprintf("Would replace %s with germ %s", path, latest.TagName))
+			return
+		}
+
+		err = release.Replace(path, data)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"path": path,
+				"err":  err,
+			}).Fatal("Cannot replace germ")
+		}
+
+		fmt.Println(fmt.Sprintf("Upgraded germ from %s to %s", version, latest.TagName))
+	},
+}
+
+func latestRelease() *release.Release {
+	latest, err := release.Get(release.Latest)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"url": release.Latest,
+			"err": err,
+		}).Fatal("Cannot find the latest release")
+	}
+
+	return latest
+}
+
+func init() {
+	versionCmd.Flags().BoolVarP(&versionCheck, "check", "", false, "Check if a newer release is available")
+
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(upgradeCmd)
+}
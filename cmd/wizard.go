@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// prompt asks the question until a non empty answer is given.
+func prompt(r *bufio.Reader, w io.Writer, question string) string {
+	for {
+		fmt.Fprint(w, question)
+
+		answer, err := r.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer != "" {
+			return answer
+		}
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err": err,
+			}).Fatal("Cannot read answer")
+		}
+	}
+}
+
+// confirm asks a yes/no question, defaulting to no.
+func confirm(r *bufio.Reader, w io.Writer, question string) bool {
+	fmt.Fprintf(w, "%s [y/N]: ", question)
+
+	answer, _ := r.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+
+	return false
+}
+
+// choose asks the user to pick one of the options.
+func choose(r *bufio.Reader, w io.Writer, question string, options []string) string {
+	for {
+		fmt.Fprintln(w, question)
+		for i, option := range options {
+			fmt.Fprintf(w, "  %d) %s\n", i+1, option)
+		}
+
+		answer := prompt(r, w, "Choice: ")
+		i, err := strconv.Atoi(answer)
+		if err == nil && i > 0 && i <= len(options) {
+			return options[i-1]
+		}
+
+		for _, option := range options {
+			if option == answer {
+				return option
+			}
+		}
+	}
+}
+
+// triggerSets are the triggers `germ new` can add to the profile of the
+// secret, with the overrides file.
+var triggerSets = map[string][]config.OverrideTrigger{
+	"highlight-errors": {
+		{Regex: `(?i)\b(error|failed|denied)\b`, Action: "HighlightTrigger", Parameter: "{#ff0000,}"},
+	},
+	"notify-errors": {
+		{Regex: `(?i)\b(error|failed|denied)\b`, Action: "GrowlTrigger", Parameter: "\\0"},
+	},
+	"mfa": {
+		{Regex: `(?i)(mfa|otp|verification) code:`, Action: "BounceTrigger", Partial: true},
+		{Regex: `(?i)(mfa|otp|verification) code:`, Action: "GrowlTrigger", Parameter: "Waiting for a code", Partial: true},
+	},
+}
+
+func triggerSetNames() []string {
+	var ret []string
+	for name := range triggerSets {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bufio"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestPrompt(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\n  \nfoo\n"))
+
+	assert.Equal(t, "foo", prompt(r, ioutil.Discard, "Name: "))
+}
+
+func TestConfirm(t *testing.T) {
+	var cases = []struct {
+		name  string
+		input string
+		exp   bool
+	}{
+		{
+			name:  "yes",
+			input: "y\n",
+			exp:   true,
+		},
+		{
+			name:  "uppercase yes",
+			input: "YES\n",
+			exp:   true,
+		},
+		{
+			name:  "default is no",
+			input: "\n",
+			exp:   false,
+		},
+	}
+
+	for _, test := range cases {
+		r := bufio.NewReader(strings.NewReader(test.input))
+		assert.Equal(t, test.exp, confirm(r, ioutil.Discard, "Export"), test.name)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	var cases = []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{
+			name:  "by number",
+			input: "2\n",
+			exp:   "work",
+		},
+		{
+			name:  "by name",
+			input: "germ\n",
+			exp:   "germ",
+		},
+		{
+			name:  "invalid choice is asked again",
+			input: "5\nwork\n",
+			exp:   "work",
+		},
+	}
+
+	for _, test := range cases {
+		r := bufio.NewReader(strings.NewReader(test.input))
+		assert.Equal(t, test.exp, choose(r, ioutil.Discard, "Service:", []string{"germ", "work"}), test.name)
+	}
+}
+
+func TestTriggerSets(t *testing.T) {
+	assert.DeepEqual(t, []string{"highlight-errors", "mfa", "notify-errors"}, triggerSetNames())
+
+	for name, triggers := range triggerSets {
+		for _, trigger := range triggers {
+			_, err := regexp.Compile(trigger.Regex)
+			assert.NilError(t, err, name)
+		}
+	}
+}
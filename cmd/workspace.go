@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/spf13/cobra"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:     "workspace NAME",
+	Aliases: []string{"ws"},
+	Short:   "Open the profiles of a workspace defined in the config",
+	Long: heredoc.Doc(`
+		Opens the profiles of the workspace in a new iTerm window, a tab per
+		profile or split panes in a single tab. The globs are matched against
+		the profiles of the last 'germ generate'. For example
+
+		workspaces:
+		  morning:
+		    profiles: [login-prod, "k8s-prod*", vim-germ]
+		  incident:
+		    profiles: [k8s-prod, tf-bastion, logs-prod]
+		    layout: splits
+	`),
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaces,
+	Run: func(cmd *cobra.Command, args []string) {
+		Verbose(cmd)
+
+		workspace, found := cfg.Workspaces[args[0]]
+		if !found {
+			log.WithFields(log.Fields{
+				"name":      args[0],
+				"available": strings.Join(config.Keys(cfg.Workspaces), ","),
+			}).Fatal("Workspace not found")
+		}
+
+		profiles := expandProfiles(workspace.Profiles, generatedNames())
+		if len(profiles) == 0 {
+			log.WithFields(log.Fields{
+				"name": args[0],
+			}).Fatal("Workspace has no profiles")
+		}
+
+		openLayout(args[0], workspaceLayout(workspace, profiles), false)
+	},
+}
+
+// generatedNames returns the names of the profiles of the last generation,
+// as `germ generate` writes them.
+func generatedNames() []string {
+	var ret []string
+
+	prof, _ := lastProfiles()
+	for _, profile := range prof.Profiles {
+		ret = append(ret, profile.Name)
+	}
+	sort.Strings(ret)
+
+	return ret
+}
+
+// expandProfiles replaces the globs with the matching names, in order and
+// without duplicates. Names without a glob are kept even if they were not
+// generated, ie for hand made iTerm profiles.
+func expandProfiles(patterns, names []string) []string {
+	var ret []string
+	var seen = map[string]bool{}
+
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			ret = append(ret, name)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !strings.ContainsAny(pattern, "*?[") {
+			add(pattern)
+			continue
+		}
+
+		var found bool
+		for _, name := range names {
+			if config.Match(pattern, name) {
+				found = true
+				add(name)
+			}
+		}
+
+		if !found {
+			log.WithFields(log.Fields{
+				"pattern": pattern,
+			}).Warn("No profile matches the pattern")
+		}
+	}
+
+	return ret
+}
+
+// workspaceLayout returns the arrangement of the profiles, a tab per profile
+// or a tab with split panes.
+func workspaceLayout(workspace config.Workspace, profiles []string) config.Arrangement {
+	if workspace.Layout == "splits" {
+		return config.Arrangement{
+			Tabs: []config.Tab{{Panes: profiles, Vertical: workspace.Vertical}},
+		}
+	}
+
+	var ret config.Arrangement
+	for _, profile := range profiles {
+		ret.Tabs = append(ret.Tabs, config.Tab{Panes: []string{profile}})
+	}
+
+	return ret
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceLayout(t *testing.T) {
+	names := []string{"k8s-dev", "k8s-prod", "k8s-prod-eu", "login-prod"}
+
+	var cases = []struct {
+		name      string
+		workspace config.Workspace
+		exp       config.Arrangement
+	}{
+		{
+			name:      "tab per profile",
+			workspace: config.Workspace{Profiles: []string{"login-prod", "k8s-prod*", "k8s-prod", "hand-made"}},
+			exp: config.Arrangement{
+				Tabs: []config.Tab{
+					{Panes: []string{"login-prod"}},
+					{Panes: []string{"k8s-prod"}},
+					{Panes: []string{"k8s-prod-eu"}},
+					{Panes: []string{"hand-made"}},
+				},
+			},
+		},
+		{
+			name:      "splits",
+			workspace: config.Workspace{Profiles: []string{"k8s-*", "missing-*"}, Layout: "splits", Vertical: true},
+			exp: config.Arrangement{
+				Tabs: []config.Tab{{Panes: []string{"k8s-dev", "k8s-prod", "k8s-prod-eu"}, Vertical: true}},
+			},
+		},
+	}
+
+	for _, test := range cases {
+		profiles := expandProfiles(test.workspace.Profiles, names)
+		assert.Equal(t, test.exp, workspaceLayout(test.workspace, profiles), test.name)
+	}
+}
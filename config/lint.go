@@ -0,0 +1,452 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+	shortcut = regexp.MustCompile(`^[a-zA-Z0-9]$`)
+)
+
+// Lint returns the problems of the configuration, the unknown or mistyped
+// keys with their line and the invalid values.
+func Lint(data []byte) []error {
+	var cfg Config
+
+	var errs []error
+	err := yaml.UnmarshalStrict(data, &cfg)
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, e := range typeErr.Errors {
+			errs = append(errs, errors.New(e))
+		}
+	} else if err != nil {
+		return []error{err}
+	}
+
+	return append(errs, cfg.Validate()...)
+}
+
+// Validate returns the invalid values of the configuration.
+func (c *Config) Validate() []error {
+	var errs []error
+
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, errors.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	globs := func(key string, patterns []string) {
+		for _, pattern := range patterns {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				invalid(key, "invalid glob %s", pattern)
+			}
+		}
+	}
+
+	globs("login", Keys(c.Login))
+	for _, name := range Keys(c.LoginCommands) {
+		if _, err := template.New(name).Parse(c.LoginCommands[name]); err != nil {
+			invalid(fmt.Sprintf("loginCommands.%s", name), "invalid template %s", c.LoginCommands[name])
+		}
+	}
+	globs("secretInjection", Keys(c.SecretInjection))
+	globs("regions", Keys(c.Regions))
+	globs("colorSchemes", Keys(c.ColorSchemes))
+	globs("semanticHistory", Keys(c.SemanticHistory))
+	globs("expiry", Keys(c.Expiry))
+	globs("favorites", c.Favorites)
+
+	for _, name := range Keys(c.SecretInjection) {
+		if v := c.SecretInjection[name]; v != "security" && v != "germ" {
+			invalid("secretInjection", "%s must be security or germ, not %s", name, v)
+		}
+	}
+
+	for _, name := range Keys(c.Expiry) {
+		if _, err := ParseTTL(c.Expiry[name]); err != nil {
+			invalid("expiry", "%s has an invalid ttl %s", name, c.Expiry[name])
+		}
+	}
+
+	switch c.Installer {
+	case "", "brew", "asdf", "mise":
+	default:
+		invalid("installer", "must be one of brew, asdf or mise, not %s", c.Installer)
+	}
+
+	if c.Theme != nil {
+		for mode, set := range map[string]ColorSet{"light": c.Theme.Light, "dark": c.Theme.Dark} {
+			for _, color := range []string{set.Foreground, set.Background, set.Prod, set.K8s} {
+				if color != "" && !hexColor.MatchString(color) {
+					invalid("theme", "%s color %s is not like #1d1f21", mode, color)
+				}
+			}
+		}
+	}
+
+	if c.Dangerous != nil {
+		globs("dangerous.profiles", c.Dangerous.Profiles)
+	}
+
+	globs("close", Keys(c.Close))
+	for source, policy := range c.Close {
+		if policy != "always" && policy != "never" && policy != "on-failure" {
+			invalid(fmt.Sprintf("close.%s", source), "must be always, never or on-failure, not %s", policy)
+		}
+	}
+
+	globs("shortcuts", Keys(c.Shortcuts))
+	var letters = map[string]string{}
+	for _, pattern := range Keys(c.Shortcuts) {
+		letter := strings.ToUpper(c.Shortcuts[pattern])
+		if !shortcut.MatchString(letter) {
+			invalid(fmt.Sprintf("shortcuts.%s", pattern), "must be a single letter or digit, not %s", letter)
+		}
+
+		if other, found := letters[letter]; found {
+			invalid(fmt.Sprintf("shortcuts.%s", pattern), "%s is already used by %s", letter, other)
+		}
+		letters[letter] = pattern
+	}
+
+	if c.Names != nil {
+		globs("names.prefixes", Keys(c.Names.Prefixes))
+		globs("names.priority", c.Names.Priority)
+
+		if s := c.Names.Sanitize; s != nil {
+			for regex := range s.Replace {
+				if _, err := regexp.Compile(regex); err != nil {
+					invalid("names.sanitize.replace", "invalid regex %s", regex)
+				}
+			}
+		}
+
+		switch c.Names.Collisions {
+		case "", "warn", "error", "suffix", "priority":
+		default:
+			invalid("names.collisions", "must be warn, error, suffix or priority, not %s", c.Names.Collisions)
+		}
+	}
+
+	var vpns = map[string]bool{}
+	for i, vpn := range c.VPNs {
+		vpns[vpn.Name] = true
+		key := fmt.Sprintf("vpn[%d]", i)
+		globs(key, vpn.Profiles)
+
+		if vpn.Name == "" {
+			invalid(key, "missing name")
+		}
+
+		switch {
+		case vpn.Tool == "tailscale" && vpn.ExitNode == "":
+			invalid(key, "tailscale needs an exitNode")
+		case (vpn.Tool == "openvpn" || vpn.Tool == "wireguard") && vpn.Config == "":
+			invalid(key, "%s needs a config", vpn.Tool)
+		case vpn.Tool != "openvpn" && vpn.Tool != "wireguard" && vpn.Tool != "tailscale":
+			invalid(key, "tool must be openvpn, wireguard or tailscale, not %s", vpn.Tool)
+		}
+	}
+
+	for i, pre := range c.Pre {
+		key := fmt.Sprintf("pre[%d]", i)
+		globs(key, pre.Profiles)
+
+		for j, step := range pre.Steps {
+			var set int
+			for _, value := range []string{step.VPN, step.AWSLogin, step.Kubectx, step.Run} {
+				if value != "" {
+					set++
+				}
+			}
+
+			if set != 1 {
+				invalid(fmt.Sprintf("%s.steps[%d]", key, j), "set one of vpn, awsLogin, kubectx or run")
+			}
+
+			if step.VPN != "" && !vpns[step.VPN] {
+				invalid(fmt.Sprintf("%s.steps[%d]", key, j), "unknown vpn %s", step.VPN)
+			}
+		}
+	}
+
+	for _, name := range Keys(c.Workspaces) {
+		workspace := c.Workspaces[name]
+		globs(fmt.Sprintf("workspaces.%s", name), workspace.Profiles)
+
+		if workspace.Layout != "" && workspace.Layout != "tabs" && workspace.Layout != "splits" {
+			invalid(fmt.Sprintf("workspaces.%s", name), "layout must be tabs or splits, not %s", workspace.Layout)
+		}
+	}
+
+	if c.HostKeys != nil {
+		globs("hostKeys.profiles", c.HostKeys.Profiles)
+	}
+
+	if c.History != nil {
+		globs("history.profiles", c.History.Profiles)
+	}
+
+	if c.Idle != nil {
+		globs("idle.profiles", c.Idle.Profiles)
+
+		if c.Idle.Minutes <= 0 {
+			invalid("idle", "minutes must be positive")
+		}
+	}
+
+	if c.Audit != nil {
+		globs("audit.profiles", c.Audit.Profiles)
+	}
+
+	for i, rule := range c.Logging {
+		key := fmt.Sprintf("logging[%d]", i)
+		globs(key, rule.Profiles)
+		globs(key, rule.Sources)
+
+		switch rule.Style {
+		case "", "raw", "plain", "html", "asciicast":
+		default:
+			invalid(key, "style must be raw, plain, html or asciicast, not %s", rule.Style)
+		}
+	}
+
+	if c.Dotfiles != nil && c.Dotfiles.Path == "" {
+		invalid("dotfiles", "missing path")
+	}
+
+	for i, password := range c.Passwords {
+		key := fmt.Sprintf("passwords[%d]", i)
+		if password.Account == "" {
+			invalid(key, "missing account")
+		}
+
+		if _, err := regexp.Compile(password.Regex); err != nil {
+			invalid(key, "invalid regex %s", password.Regex)
+		}
+
+		globs(key, password.Profiles)
+	}
+
+	for i, transform := range c.Transforms {
+		key := fmt.Sprintf("transforms[%d]", i)
+
+		var set int
+		for _, field := range []bool{len(transform.Patch) > 0, transform.PatchFile != "", transform.JQ != ""} {
+			if field {
+				set++
+			}
+		}
+		if set != 1 {
+			invalid(key, "must have one of patch, patchFile or jq")
+		}
+
+		for j, operation := range transform.Patch {
+			switch operation.Op {
+			case "add", "remove", "replace", "move", "copy", "test":
+			default:
+				invalid(fmt.Sprintf("%s.patch[%d]", key, j), "unknown op %s", operation.Op)
+			}
+		}
+	}
+
+	for i, notification := range c.Notifications {
+		key := fmt.Sprintf("notifications[%d]", i)
+		globs(key, notification.Profiles)
+
+		if len(notification.Patterns) == 0 {
+			invalid(key, "missing patterns")
+		}
+
+		for _, pattern := range notification.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				invalid(key, "invalid pattern %s", pattern)
+			}
+		}
+	}
+
+	globs("titles", Keys(c.Titles))
+
+	if c.Bookmarks != nil && c.Bookmarks.Path == "" {
+		invalid("bookmarks", "missing path")
+	}
+
+	if c.Browsers != nil {
+		globs("browsers.accounts", Keys(c.Browsers.Accounts))
+
+		for _, account := range Keys(c.Browsers.Accounts) {
+			if c.Browsers.Accounts[account].Name == "" {
+				invalid(fmt.Sprintf("browsers.accounts.%s", account), "missing name")
+			}
+		}
+	}
+
+	for i, snippet := range c.Snippets {
+		key := fmt.Sprintf("snippets[%d]", i)
+		globs(key, snippet.Profiles)
+
+		if snippet.Key == "" || snippet.Text == "" {
+			invalid(key, "missing key or text")
+		}
+	}
+
+	for regex := range c.Links {
+		if _, err := regexp.Compile(regex); err != nil {
+			invalid("links", "invalid regex %s", regex)
+		}
+	}
+
+	for i, state := range c.TFState {
+		if (state.Path == "") == (state.Dir == "") {
+			invalid(fmt.Sprintf("tfstate[%d]", i), "set either path or dir")
+		}
+
+		switch state.Stopped {
+		case "", "skip", "tag":
+		default:
+			invalid(fmt.Sprintf("tfstate[%d].stopped", i), "unknown value %s, use skip or tag", state.Stopped)
+		}
+
+		if state.Ping != "" && state.Ping != "online" {
+			if _, err := time.ParseDuration(state.Ping); err != nil {
+				invalid(fmt.Sprintf("tfstate[%d].ping", i), "invalid value %s, use online or a duration", state.Ping)
+			}
+		}
+	}
+
+	for i, stack := range c.Stacks {
+		if stack.Profile == "" || stack.Stack == "" {
+			invalid(fmt.Sprintf("stacks[%d]", i), "missing profile or stack")
+		}
+	}
+
+	for i, buckets := range c.Buckets {
+		if buckets.Profile == "" {
+			invalid(fmt.Sprintf("buckets[%d]", i), "missing profile")
+		}
+	}
+
+	for i, forward := range c.PortForwards {
+		key := fmt.Sprintf("portForwards[%d]", i)
+		if forward.Context == "" {
+			invalid(key, "missing context")
+		}
+
+		for service, ports := range forward.Services {
+			if len(ports) == 0 {
+				invalid(key, "service %s has no ports", service)
+			}
+		}
+	}
+
+	for i, tunnel := range c.GCP {
+		key := fmt.Sprintf("gcp[%d]", i)
+		if (tunnel.SQL == "") == (tunnel.Instance == "") {
+			invalid(key, "set either sql or instance")
+		}
+
+		if tunnel.Instance != "" && (tunnel.Zone == "" || tunnel.Port <= 0) {
+			invalid(key, "instance needs a zone and a port")
+		}
+	}
+
+	for name, servers := range map[string][]HashiCorp{"consul": c.Consul, "nomad": c.Nomad} {
+		for i, server := range servers {
+			if server.Address == "" {
+				invalid(fmt.Sprintf("%s[%d]", name, i), "missing address")
+			}
+		}
+	}
+
+	for i, bundle := range c.Bundles {
+		if (bundle.URL == "") == (bundle.Git == "") {
+			invalid(fmt.Sprintf("bundles[%d]", i), "set either url or git")
+		}
+
+		if s := bundle.Signature; s != nil {
+			if s.Tool != "minisign" && s.Tool != "ssh" {
+				invalid(fmt.Sprintf("bundles[%d]", i), "signature tool must be minisign or ssh, not %s", s.Tool)
+			}
+
+			if s.Key == "" || (s.Tool == "ssh" && s.Identity == "") {
+				invalid(fmt.Sprintf("bundles[%d]", i), "signature needs a key, and an identity for ssh")
+			}
+		}
+	}
+
+	for i, keychain := range c.KeyChains {
+		if keychain.Service == "" {
+			invalid(fmt.Sprintf("keychain[%d]", i), "missing service")
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].Error() < errs[j].Error()
+	})
+
+	return errs
+}
+
+// Docs returns the keys of the configuration with their types, indented like
+// the YAML file.
+func Docs() string {
+	var lines []string
+	docs(reflect.TypeOf(Config{}), 0, &lines)
+
+	return strings.Join(lines, "\n")
+}
+
+func docs(t reflect.Type, depth int, lines *[]string) {
+	indent := strings.Repeat("  ", depth)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft.Kind() == reflect.Struct:
+			*lines = append(*lines, fmt.Sprintf("%s%s:", indent, key))
+			docs(ft, depth+1, lines)
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Struct:
+			*lines = append(*lines, fmt.Sprintf("%s%s:", indent, key))
+			first := len(*lines)
+			docs(ft.Elem(), depth+2, lines)
+			(*lines)[first] = fmt.Sprintf("%s  - %s", indent, strings.TrimLeft((*lines)[first], " "))
+		case ft.Kind() == reflect.Map && ft.Elem().Kind() == reflect.Struct:
+			*lines = append(*lines, fmt.Sprintf("%s%s:", indent, key))
+			*lines = append(*lines, fmt.Sprintf("%s  <%s>:", indent, ft.Key()))
+			docs(ft.Elem(), depth+2, lines)
+		default:
+			*lines = append(*lines, fmt.Sprintf("%s%s: %s", indent, key, ft))
+		}
+	}
+}
+
+// Keys returns the sorted keys of a map with string keys.
+func Keys(m interface{}) []string {
+	var ret []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		ret = append(ret, key.String())
+	}
+	sort.Strings(ret)
+
+	return ret
+}
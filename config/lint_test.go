@@ -0,0 +1,128 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		exp  []string
+	}{
+		{
+			name: "valid",
+			in: heredoc.Doc(`
+				login:
+				  okta-*: gimme-aws-creds
+				expiry:
+				  incident-*: 3d
+				installer: brew
+			`),
+		},
+		{
+			name: "unknown and mistyped keys",
+			in: heredoc.Doc(`
+				login:
+				  okta-*: gimme-aws-creds
+				regoins:
+				  prod: [eu-west-1]
+				hierarchicalNames: maybe
+			`),
+			exp: []string{
+				"line 3: field regoins not found in type config.Config",
+				"line 5: cannot unmarshal !!str `maybe` into bool",
+			},
+		},
+		{
+			name: "invalid values",
+			in: heredoc.Doc(`
+				loginCommands:
+				  saml2aws: "saml2aws login {{ .Profile"
+				secretInjection:
+				  custom/*: env
+				expiry:
+				  "[incident": 3x
+				installer: port
+				theme:
+				  dark:
+				    background: black
+				passwords:
+				  - regex: "^(Password"
+				tfstate:
+				  - path: a
+				    dir: b
+				bundles:
+				  - name: team
+			`),
+			exp: []string{
+				"bundles[0]: set either url or git",
+				"expiry: [incident has an invalid ttl 3x",
+				"expiry: invalid glob [incident",
+				"installer: must be one of brew, asdf or mise, not port",
+				"loginCommands.saml2aws: invalid template saml2aws login {{ .Profile",
+				"passwords[0]: invalid regex ^(Password",
+				"passwords[0]: missing account",
+				"secretInjection: custom/* must be security or germ, not env",
+				"tfstate[0]: set either path or dir",
+				"theme: dark color black is not like #1d1f21",
+			},
+		},
+		{
+			name: "shortcut conflicts",
+			in: heredoc.Doc(`
+				shortcuts:
+				  prod: p
+				  "prod-*": P
+				  dev: dd
+			`),
+			exp: []string{
+				"shortcuts.dev: must be a single letter or digit, not DD",
+				"shortcuts.prod-*: P is already used by prod",
+			},
+		},
+		{
+			name: "transforms",
+			in: heredoc.Doc(`
+				transforms:
+				  - jq: .
+				  - jq: .
+				    patchFile: patch.json
+				  - patch:
+				      - op: delete
+				        path: /Profiles/0
+			`),
+			exp: []string{
+				"transforms[1]: must have one of patch, patchFile or jq",
+				"transforms[2].patch[0]: unknown op delete",
+			},
+		},
+		{
+			name: "invalid yaml",
+			in:   "login: [",
+			exp:  []string{"yaml: line 1: did not find expected node content"},
+		},
+	}
+
+	for _, test := range cases {
+		var errs []string
+		for _, err := range Lint([]byte(test.in)) {
+			errs = append(errs, err.Error())
+		}
+
+		assert.Equal(t, test.exp, errs, test.name)
+	}
+}
+
+func TestDocs(t *testing.T) {
+	docs := Docs()
+
+	assert.True(t, strings.HasPrefix(docs, "login: map[string]string\n"))
+	assert.Contains(t, docs, "keychain:\n  - service: string\n    prefix: string\n")
+	assert.Contains(t, docs, "semanticHistory:\n  <string>:\n    action: string\n")
+	assert.Contains(t, docs, "agents:\n  keys: []string\n")
+}
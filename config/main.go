@@ -0,0 +1,689 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Path is the default location of the germ configuration file.
+var Path = "~/.config/germ/config.yaml"
+
+// AuditFile is the default JSONL log of `germ exec`.
+var AuditFile = "~/.local/state/germ/audit.jsonl"
+
+// ThemesDir is where `germ themes add` stores the .itermcolors files.
+var ThemesDir = "~/.config/germ/themes"
+
+type Config struct {
+	// Login maps profile names (or globs) to the login provider to use.
+	Login map[string]string `yaml:"login"`
+	// LoginCommands maps login provider names to command templates that
+	// replace the built in ones, rendered with .Profile, .Account and .Role.
+	LoginCommands map[string]string `yaml:"loginCommands"`
+	// Saml2AWS maps saml2aws idp accounts to the role ARNs that get their
+	// own profiles.
+	Saml2AWS map[string][]string `yaml:"saml2aws"`
+	// KeyChains are additional keychain services to store secrets in.
+	KeyChains []KeyChain `yaml:"keychain"`
+	// SecretInjection maps keychain profile names (or globs) to the way the
+	// secret is injected, either 'security' (default) or 'germ'.
+	SecretInjection map[string]string `yaml:"secretInjection"`
+	// Regions maps AWS profile names (or globs) to the regions that get
+	// their own profile.
+	Regions map[string][]string `yaml:"regions"`
+	// AccountAliases maps AWS account IDs to friendly names.
+	AccountAliases map[string]string `yaml:"accountAliases"`
+	// Bookmarks writes the AWS console links of the accounts to a bookmarks
+	// file with `germ generate --write`.
+	Bookmarks *Bookmarks `yaml:"bookmarks"`
+	// Browsers are the browser profiles the AWS console URLs of the
+	// accounts open in, for `germ export --format finicky`.
+	Browsers *Browsers `yaml:"browsers"`
+	// Arrangements are iTerm window layouts, by name.
+	Arrangements map[string]Arrangement `yaml:"arrangements"`
+	// Workspaces are sets of profiles opened together with `germ
+	// workspace`, by name.
+	Workspaces map[string]Workspace `yaml:"workspaces"`
+	// Theme sets separate colors for the macOS light and dark appearance.
+	Theme *Theme `yaml:"theme"`
+	// ColorSchemes maps profile names (or globs) to color schemes added with
+	// `germ themes add`.
+	ColorSchemes map[string]string `yaml:"colorSchemes"`
+	// SemanticHistory maps profile names (or globs) to what cmd+click on a
+	// file or URL does.
+	SemanticHistory map[string]SemanticHistory `yaml:"semanticHistory"`
+	// Workspace is where the git remotes are cloned from the smart
+	// selection, defaults to ~/src.
+	Workspace string `yaml:"workspace"`
+	// Titles sets the iTerm titles of the profiles of the matching
+	// sources.
+	Titles map[string]Title `yaml:"titles"`
+	// Snippets are commands typed with a shortcut in the matching
+	// profiles, ie runbook steps.
+	Snippets []Snippet `yaml:"snippets"`
+	// Links maps regexes to the URL the smart selection opens, ie
+	// PROJ-\d+ to https://jira.example.com/browse/\0.
+	Links map[string]string `yaml:"links"`
+	// Projects are the directories scanned for per project editor profiles.
+	Projects *Projects `yaml:"projects"`
+	// Dotfiles is the repo `germ generate --sync-dotfiles` copies the files to.
+	Dotfiles *Dotfiles `yaml:"dotfiles"`
+	// Names sets the prefixes of the profile names per source, and what
+	// happens to profiles with the same name.
+	Names *Names `yaml:"names"`
+	// HierarchicalNames groups the AWS and Kubernetes profiles in folders,
+	// ie aws/account/region/name and k8s/cluster/namespace.
+	HierarchicalNames bool `yaml:"hierarchicalNames"`
+	// TFState are the Terraform states to generate instance and bastion
+	// profiles from.
+	TFState []TFState `yaml:"tfstate"`
+	// Ansible are the inventories to generate ssh profiles from. Executable
+	// inventories are run as dynamic inventory scripts.
+	Ansible []string `yaml:"ansible"`
+	// Buckets are the S3 buckets that get a shell profile.
+	Buckets []Buckets `yaml:"buckets"`
+	// PortForwards are the Kubernetes services that get a kubectl
+	// port-forward profile.
+	PortForwards []PortForward `yaml:"portForwards"`
+	// GCP are the Cloud SQL and IAP tunnels that get a profile.
+	GCP []GCPTunnel `yaml:"gcp"`
+	// Consul are the catalogs to generate node ssh profiles from.
+	Consul []HashiCorp `yaml:"consul"`
+	// Nomad are the clusters to generate allocation exec profiles from.
+	Nomad []HashiCorp `yaml:"nomad"`
+	// Stacks are the CloudFormation stacks with bastion entry points.
+	Stacks []Stack `yaml:"stacks"`
+	// Expiry maps profile names (or globs) to their time to live, ie 72h or
+	// 7d. Profiles with a ttl= tag use that instead.
+	Expiry map[string]string `yaml:"expiry"`
+	// Dangerous marks profiles as production, with a red background, a
+	// badge and a confirmation before the session starts.
+	Dangerous *Dangerous `yaml:"dangerous"`
+	// Close maps profile names or germ sources (or globs) to what happens
+	// to their sessions once the command exits, either always (default)
+	// close, never, or on-failure to start a login shell if it failed.
+	Close map[string]string `yaml:"close"`
+	// Idle exits the sessions of the matching profiles after they are idle
+	// for a number of minutes.
+	Idle *Idle `yaml:"idle"`
+	// History gives the sessions of the matching profiles their own
+	// HISTFILE.
+	History *History `yaml:"history"`
+	// Audit wraps the profile commands with `germ exec` to log when each
+	// profile is used.
+	Audit *Audit `yaml:"audit"`
+	// Logging enables the iTerm session logging of the matching profiles,
+	// the first rule that matches wins.
+	Logging []Logging `yaml:"logging"`
+	// Shortcuts maps profile names (or globs) to the letter that opens them
+	// with ⌃⌘.
+	Shortcuts map[string]string `yaml:"shortcuts"`
+	// Favorites are profile names (or globs) listed before the rest, which
+	// are ordered by their last use in the audit log.
+	Favorites []string `yaml:"favorites"`
+	// Installer is the package manager the not found triggers of the local
+	// shell profiles install the missing commands with, one of brew, asdf
+	// or mise. Remote sessions keep apt, yum and apk.
+	Installer string `yaml:"installer"`
+	// DetectOS replaces the apt, yum and apk chain of the not found triggers
+	// of the remote sessions with the install command of the remote OS,
+	// detected from /etc/os-release.
+	DetectOS bool `yaml:"detectOS"`
+	// AutomaticProfileSwitching binds the hosts of the ssh and SSM profiles
+	// to them, so iTerm switches to the profile on a manual ssh.
+	AutomaticProfileSwitching bool `yaml:"automaticProfileSwitching"`
+	// HostKeys pins the host keys of the matching ssh profiles, instead of
+	// trusting them on the first connection.
+	HostKeys *HostKeys `yaml:"hostKeys"`
+	// Passwords are the prompts answered from the iTerm password manager,
+	// in addition to the ssh key and macOS ones.
+	Passwords []Password `yaml:"passwords"`
+	// Notifications post a notification when the matching profiles print a
+	// completion message, ie terraform apply complete.
+	Notifications []Notification `yaml:"notifications"`
+	// VPNs get a profile that brings them up and starts a shell, and are
+	// brought up before the commands of the profiles that need them.
+	VPNs []VPN `yaml:"vpn"`
+	// Pre are the steps run before the commands of the matching profiles,
+	// ie to log in or switch the kube context.
+	Pre []Pre `yaml:"pre"`
+	// Agents generates an agents profile that starts ssh-agent and gpg-agent
+	// and adds the keys.
+	Agents *Agents `yaml:"agents"`
+	// Plugins are executables that print profiles, on top of the
+	// germ-source-* ones in the PATH.
+	Plugins []string `yaml:"plugins"`
+	// RPCPlugins are long running plugins, on top of the germ-rpc-* ones in
+	// the PATH.
+	RPCPlugins []RPCPlugin `yaml:"rpcPlugins"`
+	// Bundles are profiles shared by a team, in the plugin JSON format.
+	Bundles []Bundle `yaml:"bundles"`
+	// Transforms are applied in order to the final profiles document, for
+	// the tweaks germ does not model.
+	Transforms []Transform `yaml:"transforms"`
+}
+
+// Transform is either a JSON Patch, inline or from a file, or a jq filter of
+// the {"Profiles": [...]} document.
+type Transform struct {
+	// Patch is a JSON Patch (RFC 6902) document.
+	Patch []PatchOperation `yaml:"patch"`
+	// PatchFile is a JSON file with a JSON Patch document.
+	PatchFile string `yaml:"patchFile"`
+	// JQ is a filter run with the jq binary, that prints the new document.
+	JQ string `yaml:"jq"`
+}
+
+type PatchOperation struct {
+	// Op is add, remove, replace, move, copy or test.
+	Op string `yaml:"op" json:"op"`
+	// Path is the JSON Pointer of the operation, ie /Profiles/0/Name.
+	Path string `yaml:"path" json:"path"`
+	// From is the source of move and copy.
+	From string `yaml:"from" json:"from,omitempty"`
+	// Value of add, replace and test.
+	Value interface{} `yaml:"value" json:"value,omitempty"`
+}
+
+type Bundle struct {
+	// Name of the bundle source, defaults to the file name.
+	Name string `yaml:"name"`
+	// URL of the bundle file, over HTTPS.
+	URL string `yaml:"url"`
+	// Git is the repository with the bundle, when there is no URL.
+	Git string `yaml:"git"`
+	// Ref is the branch or tag of the repository, defaults to its HEAD.
+	Ref string `yaml:"ref"`
+	// Path of the bundle in the repository, defaults to profiles.json.
+	Path string `yaml:"path"`
+	// Signature verifies the bundle. Unsigned bundles are only merged with
+	// `germ generate --allow-unsigned`.
+	Signature *Signature `yaml:"signature"`
+}
+
+type Signature struct {
+	// Tool is either minisign, with the signature in <bundle>.minisig, or
+	// ssh (ssh-keygen -Y verify) with the signature in <bundle>.sig.
+	Tool string `yaml:"tool"`
+	// Key is the minisign public key or its file, or the ssh allowed signers
+	// file.
+	Key string `yaml:"key"`
+	// Identity is the ssh signer in the allowed signers file.
+	Identity string `yaml:"identity"`
+}
+
+type RPCPlugin struct {
+	Path string `yaml:"path"`
+	// Options are passed to the Generate call of the plugin.
+	Options map[string]string `yaml:"options"`
+}
+
+type Agents struct {
+	// Keys are the ssh keys to add, ie ~/.ssh/id_ed25519.
+	Keys []string `yaml:"keys"`
+	// GPG starts the gpg-agent as well.
+	GPG bool `yaml:"gpg"`
+	// EveryProfile runs the same in every local shell profile.
+	EveryProfile bool `yaml:"everyProfile"`
+}
+
+type HostKeys struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Directory of the known_hosts files, a file per host,
+	// ~/.local/state/germ/known_hosts by default.
+	Directory string `yaml:"directory"`
+}
+
+type Notification struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Patterns are terraform, make, pipeline or regexes.
+	Patterns []string `yaml:"patterns"`
+}
+
+type Password struct {
+	// Regex of the prompt.
+	Regex string `yaml:"regex"`
+	// Account is the name of the iTerm password manager entry.
+	Account string `yaml:"account"`
+	// Profiles are profile names (or globs), defaults to all the profiles.
+	Profiles []string `yaml:"profiles"`
+}
+
+type Names struct {
+	// Prefixes maps germ sources (or globs) to the prefix of their profile
+	// names.
+	Prefixes map[string]string `yaml:"prefixes"`
+	// Collisions is one of warn (default) to keep the first profile with a
+	// name, error, suffix to add the source to the name of the rest, or
+	// priority to keep the profile of the first source in priority.
+	Collisions string `yaml:"collisions"`
+	// Priority are germ sources (or globs), highest first.
+	Priority []string `yaml:"priority"`
+	// Sanitize cleans up the names from the sources, ie instance Name tags
+	// with slashes or emoji.
+	Sanitize *Sanitize `yaml:"sanitize"`
+}
+
+type Sanitize struct {
+	// MaxLength of the names, defaults to 64 characters.
+	MaxLength int `yaml:"maxLength"`
+	// Replace maps regexes to their replacement, applied once slashes,
+	// colons and spaces are replaced with dashes.
+	Replace map[string]string `yaml:"replace"`
+	// Unicode keeps the emoji and symbols in the names.
+	Unicode bool `yaml:"unicode"`
+}
+
+type Logging struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Sources are the germ sources (or globs), ie tfstate or aws-*.
+	Sources []string `yaml:"sources"`
+	// Directory is a template with {{ .Profile }}, {{ .Source }} and
+	// {{ date }}, defaults to ~/.local/state/germ/sessions/{{ .Profile }}.
+	// It is rendered by germ generate, so {{ date }} is the date of the
+	// generation and not of the session.
+	Directory string `yaml:"directory"`
+	// Style is one of raw (default), plain, html or asciicast.
+	Style string `yaml:"style"`
+}
+
+type VPN struct {
+	Name string `yaml:"name"`
+	// Tool is one of openvpn, wireguard or tailscale.
+	Tool string `yaml:"tool"`
+	// Config is the openvpn config file or the wireguard interface.
+	Config string `yaml:"config"`
+	// ExitNode is the tailscale exit node.
+	ExitNode string `yaml:"exitNode"`
+	// Profiles are the profile names (or globs) that need the VPN.
+	Profiles []string `yaml:"profiles"`
+}
+
+type Pre struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	Steps    []Step   `yaml:"steps"`
+}
+
+// Step is one of vpn, awsLogin, kubectx or run.
+type Step struct {
+	// VPN is the name of the vpn to bring up.
+	VPN string `yaml:"vpn"`
+	// AWSLogin is the AWS profile to log in with aws sso login, unless
+	// its credentials are valid.
+	AWSLogin string `yaml:"awsLogin"`
+	// Kubectx is the kube context the command uses, from a KUBECONFIG of
+	// its own.
+	Kubectx string `yaml:"kubectx"`
+	// Run is any shell command.
+	Run string `yaml:"run"`
+}
+
+type History struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Directory of the history files, ~/.history/germ by default.
+	Directory string `yaml:"directory"`
+}
+
+type Idle struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Minutes the session can be idle before it exits.
+	Minutes int `yaml:"minutes"`
+}
+
+type Audit struct {
+	// Profiles are profile names (or globs), defaults to all the profiles
+	// with a custom command.
+	Profiles []string `yaml:"profiles"`
+	// File is the JSONL log, defaults to ~/.local/state/germ/audit.jsonl.
+	File string `yaml:"file"`
+}
+
+type Dangerous struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Tags mark the profiles with any of them as dangerous.
+	Tags []string `yaml:"tags"`
+	// Badge defaults to PROD.
+	Badge string `yaml:"badge"`
+	// Prompt of the confirmation, defaults to 'Production, continue?'.
+	Prompt string `yaml:"prompt"`
+}
+
+type Stack struct {
+	// Profile is the AWS profile the stack is read and accessed with.
+	Profile string `yaml:"profile"`
+	Region  string `yaml:"region"`
+	Stack   string `yaml:"stack"`
+	// Bastion is the output with the instance ID to start SSM sessions to.
+	Bastion string `yaml:"bastion"`
+	// Forwards maps outputs with hosts to the port forwarded through the
+	// bastion.
+	Forwards map[string]int `yaml:"forwards"`
+}
+
+type Buckets struct {
+	// Profile is the AWS profile the buckets are listed and accessed with.
+	Profile string `yaml:"profile"`
+	Region  string `yaml:"region"`
+	// Buckets are the bucket names.
+	Buckets []string `yaml:"buckets"`
+	// Tag adds the buckets with the tag, either key or key=value.
+	Tag string `yaml:"tag"`
+}
+
+type PortForward struct {
+	Context   string `yaml:"context"`
+	Namespace string `yaml:"namespace"`
+	// Services maps the services, or resources like deploy/web, to their
+	// ports, ie 8080:80.
+	Services map[string][]string `yaml:"services"`
+}
+
+type GCPTunnel struct {
+	// Name of the profile, defaults to the instance.
+	Name string `yaml:"name"`
+	// SQL is the Cloud SQL instance, project:region:instance, proxied with
+	// cloud-sql-proxy.
+	SQL string `yaml:"sql"`
+	// Instance is the Compute Engine instance tunnelled with gcloud compute
+	// start-iap-tunnel, in the Zone of the Project.
+	Instance string `yaml:"instance"`
+	Zone     string `yaml:"zone"`
+	Project  string `yaml:"project"`
+	// Port of the instance.
+	Port int `yaml:"port"`
+	// LocalPort defaults to the port of the instance, or to the database
+	// port for Cloud SQL.
+	LocalPort int `yaml:"localPort"`
+}
+
+type HashiCorp struct {
+	Address string `yaml:"address"`
+	// Token is the ACL token used to query the API.
+	Token string `yaml:"token"`
+	// User of the ssh sessions to the Consul nodes.
+	User string `yaml:"user"`
+}
+
+type TFState struct {
+	// Path of a local state file.
+	Path string `yaml:"path"`
+	// Dir of a Terraform configuration with a remote backend, the state is
+	// read with 'terraform state pull'.
+	Dir string `yaml:"dir"`
+	// Profile is the AWS profile of the SSM sessions.
+	Profile string `yaml:"profile"`
+	// User of the bastion and the ssh over SSM sessions, defaults to the
+	// current user.
+	User string `yaml:"user"`
+	// BastionOutputs is a glob for the outputs with bastion hosts, defaults to
+	// *bastion*.
+	BastionOutputs string `yaml:"bastionOutputs"`
+	// Stopped looks up the EC2 state of the instances and skips, or tags,
+	// the ones that are not running.
+	Stopped string `yaml:"stopped"`
+	// SSHOverSSM connects to the instances with ssh, tunnelled over SSM,
+	// instead of an SSM session.
+	SSHOverSSM bool `yaml:"sshOverSSM"`
+	// GroupASGs replaces the instances of an autoscaling group with a
+	// profile that connects to one of its healthy instances.
+	GroupASGs bool `yaml:"groupASGs"`
+	// Ping skips the instances whose SSM agent is not online, or did not
+	// ping within the duration, ie 24h.
+	Ping string `yaml:"ping"`
+}
+
+type Dotfiles struct {
+	// Path of the dotfiles repo.
+	Path string `yaml:"path"`
+	// Dir inside the repo for the germ files, defaults to germ.
+	Dir string `yaml:"dir"`
+	// Commit the copied files in the repo.
+	Commit bool `yaml:"commit"`
+}
+
+type Projects struct {
+	// Dirs contain a project per sub directory.
+	Dirs []string `yaml:"dirs"`
+	// Editor defaults to vim.
+	Editor string `yaml:"editor"`
+	// Session is the vim session file of the project, defaults to
+	// Session.vim. It is loaded when present in the project.
+	Session string `yaml:"session"`
+}
+
+type SemanticHistory struct {
+	// Action is one of 'best editor', 'editor', 'url', 'command',
+	// 'raw command' or 'coprocess'.
+	Action string `yaml:"action"`
+	// Editor is used by the 'editor' action, either a known name like
+	// vscode or an application bundle ID.
+	Editor string `yaml:"editor"`
+	// Text is the command or URL of the other actions. \1 is the
+	// filename, \2 the line number and \5 the working directory.
+	Text string `yaml:"text"`
+}
+
+type Theme struct {
+	Light ColorSet `yaml:"light"`
+	Dark  ColorSet `yaml:"dark"`
+}
+
+// ColorSet are hex colors, ie #1d1f21. Empty values keep the germ defaults.
+type ColorSet struct {
+	Foreground string `yaml:"foreground"`
+	Background string `yaml:"background"`
+	// Prod is the background of the production profiles.
+	Prod string `yaml:"prod"`
+	// K8s is the background of the Kubernetes profiles.
+	K8s string `yaml:"k8s"`
+}
+
+type Arrangement struct {
+	Tabs []Tab `yaml:"tabs" json:"tabs"`
+}
+
+type Bookmarks struct {
+	// Path of the bookmarks file.
+	Path string `yaml:"path"`
+	// Containers opens the links in a Firefox container per account, with
+	// the Granted containers extension.
+	Containers bool `yaml:"containers"`
+}
+
+type Browsers struct {
+	// Default is the browser of the other URLs, Safari by default.
+	Default string `yaml:"default"`
+	// Accounts maps account IDs or aliases (or globs) to browsers.
+	Accounts map[string]Browser `yaml:"accounts"`
+}
+
+type Browser struct {
+	// Name of the browser app, ie Google Chrome.
+	Name string `yaml:"name"`
+	// Profile of the browser, if any.
+	Profile string `yaml:"profile"`
+}
+
+type Title struct {
+	// Allow lets the sessions change their title with escape sequences.
+	Allow *bool `yaml:"allow"`
+	// Template of the tab and window titles, with {{ .Profile }},
+	// {{ .Source }}, {{ .Account }}, {{ .Region }} and {{ .Host }}.
+	Template string `yaml:"template"`
+}
+
+type Snippet struct {
+	Name string `yaml:"name"`
+	// Key is the shortcut that types the snippet, ie ctrl+opt+d.
+	Key string `yaml:"key"`
+	// Text is a template with {{ .Profile }}, {{ .AWSProfile }} and
+	// {{ .Region }}. Without a trailing newline it is typed but not run.
+	Text string `yaml:"text"`
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+}
+
+type Workspace struct {
+	// Profiles are profile names (or globs) to open together.
+	Profiles []string `yaml:"profiles"`
+	// Layout is either tabs (default), a tab per profile, or splits, a
+	// tab with a pane per profile.
+	Layout string `yaml:"layout"`
+	// Vertical splits the panes side by side.
+	Vertical bool `yaml:"vertical"`
+}
+
+type Tab struct {
+	// Panes are the profile names to open, split in the tab.
+	Panes []string `yaml:"panes" json:"panes"`
+	// Vertical splits the panes side by side instead of on top of each
+	// other.
+	Vertical bool `yaml:"vertical" json:"vertical"`
+}
+
+type KeyChain struct {
+	Service string `yaml:"service"`
+	// Prefix of the generated profile names, defaults to the service name.
+	Prefix string `yaml:"prefix"`
+}
+
+// Load reads the configuration file. A missing file results in an empty
+// configuration.
+func Load(path string) *Config {
+	var cfg Config
+
+	path, err := homedir.Expand(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot expand path")
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &cfg
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot read config file")
+	}
+
+	err = yaml.UnmarshalStrict(data, &cfg)
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, e := range typeErr.Errors {
+			log.WithFields(log.Fields{
+				"path": path,
+				"err":  e,
+			}).Warn("Ignoring invalid config, see 'germ config lint'")
+		}
+
+		cfg = Config{}
+		err = yaml.Unmarshal(data, &cfg)
+	}
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot parse config file")
+	}
+
+	return &cfg
+}
+
+// Lookup returns the value for name from a map keyed by profile names or
+// globs. Exact matches win, otherwise the globs are tried in order.
+func Lookup(m map[string]string, name string) (string, bool) {
+	var patterns []string
+	for pattern := range m {
+		patterns = append(patterns, pattern)
+	}
+
+	key, found := MatchKey(patterns, name)
+
+	return m[key], found
+}
+
+// LookupList is the same as Lookup for maps with list values.
+func LookupList(m map[string][]string, name string) ([]string, bool) {
+	var patterns []string
+	for pattern := range m {
+		patterns = append(patterns, pattern)
+	}
+
+	key, found := MatchKey(patterns, name)
+
+	return m[key], found
+}
+
+// MatchKey returns the pattern that matches name, preferring an exact match
+// over the globs.
+func MatchKey(patterns []string, name string) (string, bool) {
+	sorted := append([]string{}, patterns...)
+	sort.Strings(sorted)
+
+	for _, pattern := range sorted {
+		if pattern == name {
+			return pattern, true
+		}
+	}
+
+	for _, pattern := range sorted {
+		if Match(pattern, name) {
+			return pattern, true
+		}
+	}
+
+	return "", false
+}
+
+// Match reports whether name matches the shell glob pattern.
+func Match(pattern, name string) bool {
+	matched, err := filepath.Match(pattern, name)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"pattern": pattern,
+			"err":     err,
+		}).Error("Invalid glob pattern")
+
+		return false
+	}
+
+	return matched
+}
+
+// ParseTTL parses a duration, with d for days on top of the time.Duration
+// units, ie 7d.
+func ParseTTL(ttl string) (time.Duration, error) {
+	if strings.HasSuffix(ttl, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(ttl, "d"))
+		if err != nil {
+			return 0, errors.Wrapf(err, "invalid ttl %s", ttl)
+		}
+
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(ttl)
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup(t *testing.T) {
+	var cases = []struct {
+		name  string
+		m     map[string]string
+		in    string
+		out   string
+		found bool
+	}{
+		{
+			name: "exact match wins over globs",
+			m: map[string]string{
+				"*":    "glob",
+				"prod": "exact",
+			},
+			in:    "prod",
+			out:   "exact",
+			found: true,
+		},
+		{
+			name: "glob match",
+			m: map[string]string{
+				"prod-*": "glob",
+			},
+			in:    "prod-eu",
+			out:   "glob",
+			found: true,
+		},
+		{
+			name: "no match",
+			m: map[string]string{
+				"prod-*": "glob",
+			},
+			in: "dev",
+		},
+	}
+
+	for _, test := range cases {
+		out, found := Lookup(test.m, test.in)
+		assert.Equal(t, test.found, found, test.name)
+		assert.Equal(t, test.out, out, test.name)
+	}
+}
+
+func TestParseTTL(t *testing.T) {
+	ttl, err := ParseTTL("7d")
+	assert.Nil(t, err)
+	assert.Equal(t, 7*24*time.Hour, ttl)
+
+	ttl, err = ParseTTL("36h")
+	assert.Nil(t, err)
+	assert.Equal(t, 36*time.Hour, ttl)
+
+	_, err = ParseTTL("xd")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// OverridesPath is the default location of the overrides file.
+var OverridesPath = "~/.config/germ/overrides.yaml"
+
+// Override patches the generated profiles that match its patterns, after
+// every other step of the generation.
+type Override struct {
+	// Profiles are profile names (or globs).
+	Profiles []string `yaml:"profiles"`
+	// Command replaces the command of the profiles.
+	Command string `yaml:"command,omitempty"`
+	// Triggers are added to the profiles.
+	Triggers []OverrideTrigger `yaml:"triggers,omitempty"`
+	// Set maps iTerm profile keys, ie "Badge Text", to their new value.
+	Set map[string]interface{} `yaml:"set,omitempty"`
+}
+
+type OverrideTrigger struct {
+	Regex     string `yaml:"regex"`
+	Action    string `yaml:"action"`
+	Parameter string `yaml:"parameter,omitempty"`
+	Partial   bool   `yaml:"partial,omitempty"`
+}
+
+// LoadOverrides returns the overrides of the file, in order. A missing file
+// has no overrides.
+func LoadOverrides(path string) ([]Override, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot expand path")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read overrides")
+	}
+
+	var ret []Override
+	err = yaml.UnmarshalStrict(data, &ret)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse overrides")
+	}
+
+	for i, override := range ret {
+		if len(override.Profiles) == 0 {
+			return nil, errors.Errorf("override %d has no profiles", i)
+		}
+	}
+
+	return ret, nil
+}
+
+// AddOverride appends the override to the file, keeping the existing ones as
+// they are.
+func AddOverride(path string, override Override) error {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return errors.Wrap(err, "cannot expand path")
+	}
+
+	data, err := yaml.Marshal([]Override{override})
+	if err != nil {
+		return errors.Wrap(err, "cannot marshal override")
+	}
+
+	current, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "cannot read overrides")
+	}
+	if len(current) > 0 && current[len(current)-1] != '\n' {
+		data = append([]byte("\n"), data...)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return errors.Wrap(err, "cannot create overrides directory")
+	}
+
+	return ioutil.WriteFile(path, append(current, data...), 0644)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "overrides")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var cases = []struct {
+		name string
+		data string
+		exp  []Override
+		err  bool
+	}{
+		{
+			name: "valid",
+			data: heredoc.Doc(`
+				- profiles: [prod-*]
+				  command: ssh -A prod
+				  set:
+				    Badge Text: PROD
+			`),
+			exp: []Override{
+				{
+					Profiles: []string{"prod-*"},
+					Command:  "ssh -A prod",
+					Set:      map[string]interface{}{"Badge Text": "PROD"},
+				},
+			},
+		},
+		{
+			name: "unknown field",
+			data: "- profiles: [prod]\n  comand: ssh\n",
+			err:  true,
+		},
+		{
+			name: "no profiles",
+			data: "- command: ssh\n",
+			err:  true,
+		},
+	}
+
+	for _, test := range cases {
+		path := filepath.Join(dir, "overrides.yaml")
+		assert.Nil(t, ioutil.WriteFile(path, []byte(test.data), 0644))
+
+		overrides, err := LoadOverrides(path)
+		if test.err {
+			assert.NotNil(t, err, test.name)
+			continue
+		}
+
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.exp, overrides, test.name)
+	}
+
+	overrides, err := LoadOverrides(filepath.Join(dir, "missing.yaml"))
+	assert.Nil(t, err)
+	assert.Nil(t, overrides)
+}
+
+func TestAddOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "overrides")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "germ", "overrides.yaml")
+	triggers := []OverrideTrigger{{Regex: "(?i)error", Action: "HighlightTrigger", Parameter: "{#ff0000,}"}}
+
+	assert.Nil(t, AddOverride(path, Override{Profiles: []string{"custom/a"}, Triggers: triggers}))
+	overrides, err := LoadOverrides(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []Override{{Profiles: []string{"custom/a"}, Triggers: triggers}}, overrides)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("# mine\n- profiles: [prod]\n  command: ssh prod"), 0644))
+	assert.Nil(t, AddOverride(path, Override{Profiles: []string{"custom/b"}, Triggers: triggers}))
+
+	overrides, err = LoadOverrides(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []Override{
+		{Profiles: []string{"prod"}, Command: "ssh prod"},
+		{Profiles: []string{"custom/b"}, Triggers: triggers},
+	}, overrides)
+}
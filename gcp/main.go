@@ -0,0 +1,76 @@
+package gcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+)
+
+// Profiles generates a tunnel profile per configured target, running
+// cloud-sql-proxy for the Cloud SQL instances and gcloud compute
+// start-iap-tunnel for the Compute Engine ones.
+func Profiles(tunnels []config.GCPTunnel) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, tunnel := range tunnels {
+		profile := Profile(tunnel)
+		if profile == nil {
+			log.WithFields(log.Fields{
+				"name": tunnel.Name,
+			}).Warn("Tunnel needs either sql or instance, zone and port")
+			continue
+		}
+
+		ret = append(ret, *profile)
+	}
+
+	return ret
+}
+
+// Profile returns the tunnel profile of the target, or nil if it is
+// incomplete.
+func Profile(t config.GCPTunnel) *iterm.Profile {
+	tags := []string{"gcp", "tunnel"}
+
+	var name, command string
+	switch {
+	case t.SQL != "":
+		parts := strings.Split(t.SQL, ":")
+		name = fmt.Sprintf("gcp-sql-%s", parts[len(parts)-1])
+		command = "cloud-sql-proxy"
+		if t.LocalPort > 0 {
+			command = fmt.Sprintf("%s --port %d", command, t.LocalPort)
+		}
+		command = fmt.Sprintf("%s %s", command, t.SQL)
+		if len(parts) == 3 {
+			tags = append(tags, fmt.Sprintf("project=%s", parts[0]), fmt.Sprintf("region=%s", parts[1]))
+		}
+	case t.Instance != "" && t.Zone != "" && t.Port > 0:
+		local := t.LocalPort
+		if local == 0 {
+			local = t.Port
+		}
+
+		name = fmt.Sprintf("gcp-iap-%s-%d", t.Instance, t.Port)
+		command = fmt.Sprintf("gcloud compute start-iap-tunnel %s %d --local-host-port=localhost:%d --zone %s", t.Instance, t.Port, local, t.Zone)
+		if t.Project != "" {
+			command = fmt.Sprintf("%s --project %s", command, t.Project)
+			tags = append(tags, fmt.Sprintf("project=%s", t.Project))
+		}
+		tags = append(tags, fmt.Sprintf("zone=%s", t.Zone))
+	default:
+		return nil
+	}
+
+	if t.Name != "" {
+		name = fmt.Sprintf("gcp-%s", t.Name)
+	}
+
+	return iterm.NewProfile(name, map[string]string{
+		"Command": command,
+		"Tags":    strings.Join(tags, ","),
+	})
+}
@@ -0,0 +1,41 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles(t *testing.T) {
+	var cases = []struct {
+		name    string
+		tunnel  config.GCPTunnel
+		guid    string
+		command string
+		tags    []string
+	}{
+		{
+			name:    "cloud sql",
+			tunnel:  config.GCPTunnel{SQL: "shop:europe-west1:orders", LocalPort: 5433},
+			guid:    "gcp-sql-orders",
+			command: "cloud-sql-proxy --port 5433 shop:europe-west1:orders",
+			tags:    []string{"gcp", "tunnel", "project=shop", "region=europe-west1"},
+		},
+		{
+			name:    "iap tunnel",
+			tunnel:  config.GCPTunnel{Name: "redis", Project: "shop", Instance: "cache-1", Zone: "europe-west1-b", Port: 6379},
+			guid:    "gcp-redis",
+			command: "gcloud compute start-iap-tunnel cache-1 6379 --local-host-port=localhost:6379 --zone europe-west1-b --project shop",
+			tags:    []string{"gcp", "tunnel", "project=shop", "zone=europe-west1-b"},
+		},
+	}
+
+	for _, test := range cases {
+		profiles := Profiles([]config.GCPTunnel{test.tunnel, {Instance: "no-zone"}})
+		assert.Equal(t, 1, len(profiles), test.name)
+		assert.Equal(t, test.guid, profiles[0].GUID, test.name)
+		assert.Equal(t, test.command, profiles[0].Command, test.name)
+		assert.Equal(t, test.tags, profiles[0].Tags, test.name)
+	}
+}
@@ -0,0 +1,68 @@
+package hashicorp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+)
+
+// Node is a node of the Consul catalog.
+type Node struct {
+	Node       string            `json:"Node"`
+	Address    string            `json:"Address"`
+	Datacenter string            `json:"Datacenter"`
+	Meta       map[string]string `json:"Meta"`
+}
+
+// ConsulProfiles generates an ssh profile per node of the Consul catalogs.
+func ConsulProfiles(ctx context.Context, clusters []config.HashiCorp) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, cluster := range clusters {
+		var nodes []Node
+
+		err := get(ctx, cluster.Address, "/v1/catalog/nodes", "X-Consul-Token", cluster.Token, &nodes)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"address": cluster.Address,
+				"err":     err,
+			}).Warn("Cannot list consul nodes")
+			continue
+		}
+
+		for _, node := range nodes {
+			ret = append(ret, *node.Profile(cluster.User))
+		}
+	}
+
+	return ret
+}
+
+func (n *Node) Profile(user string) *iterm.Profile {
+	target := n.Address
+	if user != "" {
+		target = fmt.Sprintf("%s@%s", user, target)
+	}
+
+	tags := []string{"consul", fmt.Sprintf("dc=%s", n.Datacenter)}
+
+	var keys []string
+	for key := range n.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		tags = append(tags, fmt.Sprintf("%s=%s", key, n.Meta[key]))
+	}
+
+	return iterm.NewProfile(fmt.Sprintf("consul-%s", n.Node), map[string]string{
+		"Command": fmt.Sprintf("ssh %s", target),
+		"Tags":    strings.Join(tags, ","),
+	})
+}
@@ -0,0 +1,88 @@
+package hashicorp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var client = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// get decodes the JSON response of the API path. The token is sent in the
+// given header if set.
+func get(ctx context.Context, address, path, header, token string, out interface{}) error {
+	url := fmt.Sprintf("%s%s", strings.TrimSuffix(address, "/"), path)
+
+	body, err := fetch(ctx, url, header, token)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// fetch returns the body of the API url. Fixtures and Record replace it.
+var fetch = func(ctx context.Context, url, header, token string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set(header, token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// fixtureName is the fixture of the API url, ie
+// consul.example.com-8500-v1-catalog-nodes.json for
+// https://consul.example.com:8500/v1/catalog/nodes.
+func fixtureName(url string) string {
+	if index := strings.Index(url, "://"); index >= 0 {
+		url = url[index+3:]
+	}
+
+	return strings.NewReplacer("/", "-", ":", "-", "?", "-", "&", "-", "=", "-", "*", "all").Replace(url) + ".json"
+}
+
+// Fixtures replaces the Consul and Nomad APIs with the JSON files of the
+// directory, named after the url of the call.
+func Fixtures(dir string) {
+	fetch = func(ctx context.Context, url, header, token string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, fixtureName(url)))
+	}
+}
+
+// Record passes the response of every API call to save, with the name of its
+// fixture.
+func Record(save func(name string, data []byte)) {
+	next := fetch
+	fetch = func(ctx context.Context, url, header, token string) ([]byte, error) {
+		body, err := next(ctx, url, header, token)
+		if err == nil {
+			save(fixtureName(url), body)
+		}
+
+		return body, err
+	}
+}
@@ -0,0 +1,96 @@
+package hashicorp
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsulProfiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/catalog/nodes", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+
+		fmt.Fprint(w, `[{"Node": "web01", "Address": "10.0.0.1", "Datacenter": "dc1", "Meta": {"role": "web"}}]`)
+	}))
+	defer server.Close()
+
+	profiles := ConsulProfiles(context.Background(), []config.HashiCorp{
+		{Address: server.URL, Token: "secret", User: "admin"},
+		{Address: "http://127.0.0.1:1"},
+	})
+
+	assert.Equal(t, 1, len(profiles))
+	assert.Equal(t, "consul-web01", profiles[0].GUID)
+	assert.Equal(t, "ssh admin@10.0.0.1", profiles[0].Command)
+	assert.Equal(t, []string{"consul", "dc=dc1", "role=web"}, profiles[0].Tags)
+}
+
+func TestNomadProfiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/allocations":
+			assert.Equal(t, "*", r.URL.Query().Get("namespace"))
+
+			fmt.Fprint(w, `[
+				{"ID": "1234", "Name": "api.web[0]", "Namespace": "payments", "JobID": "api", "TaskGroup": "web",
+				 "NodeID": "n1", "NodeName": "node1", "ClientStatus": "running", "TaskStates": {"server": {}, "sidecar": {}}},
+				{"ID": "5678", "Name": "api.web[1]", "ClientStatus": "complete", "TaskStates": {"server": {}}}
+			]`)
+		case "/v1/nodes":
+			fmt.Fprint(w, `[{"ID": "n1", "Datacenter": "dc1"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	profiles := NomadProfiles(context.Background(), []config.HashiCorp{{Address: server.URL}})
+
+	assert.Equal(t, 2, len(profiles))
+	assert.Equal(t, "nomad-api.web[0]-server", profiles[0].GUID)
+	assert.Equal(t, fmt.Sprintf(
+		"/bin/bash -lc 'NOMAD_ADDR=%s nomad alloc exec -namespace payments -task server 1234 /bin/sh'", server.URL,
+	), profiles[0].Command)
+	assert.Equal(t, []string{"nomad", "job=api", "group=web", "node=node1", "dc=dc1"}, profiles[0].Tags)
+}
+
+func TestRecordFixtures(t *testing.T) {
+	defer func(f func(context.Context, string, string, string) ([]byte, error)) { fetch = f }(fetch)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"Node": "web01", "Address": "10.0.0.1", "Datacenter": "dc1"}]`)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "recording")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	clusters := []config.HashiCorp{{Address: server.URL}}
+
+	Record(func(name string, data []byte) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+	})
+	recorded := ConsulProfiles(context.Background(), clusters)
+	server.Close()
+
+	Fixtures(dir)
+	assert.Equal(t, recorded, ConsulProfiles(context.Background(), clusters))
+	assert.Equal(t, 1, len(recorded))
+}
+
+func TestFixtureName(t *testing.T) {
+	assert.Equal(t, "consul.example.com-8500-v1-catalog-nodes.json", fixtureName("https://consul.example.com:8500/v1/catalog/nodes"))
+	assert.Equal(t, "nomad.example.com-4646-v1-allocations-namespace-all.json", fixtureName("https://nomad.example.com:4646/v1/allocations?namespace=*"))
+}
@@ -0,0 +1,105 @@
+package hashicorp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+)
+
+// Allocation is a Nomad allocation, as returned by the allocations list.
+type Allocation struct {
+	ID        string `json:"ID"`
+	Name      string `json:"Name"`
+	Namespace string `json:"Namespace"`
+	JobID     string `json:"JobID"`
+	TaskGroup string `json:"TaskGroup"`
+	NodeID    string `json:"NodeID"`
+	NodeName  string `json:"NodeName"`
+	// Datacenter is looked up from the node of the allocation.
+	Datacenter   string                 `json:"-"`
+	ClientStatus string                 `json:"ClientStatus"`
+	TaskStates   map[string]interface{} `json:"TaskStates"`
+}
+
+// NomadNode is a Nomad client node, as returned by the nodes list.
+type NomadNode struct {
+	ID         string `json:"ID"`
+	Datacenter string `json:"Datacenter"`
+}
+
+// NomadProfiles generates a `nomad alloc exec` profile per task of the
+// running allocations. The token is only used to query the API, the
+// profiles expect NOMAD_TOKEN to be set by the login shell.
+func NomadProfiles(ctx context.Context, clusters []config.HashiCorp) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, cluster := range clusters {
+		var allocs []Allocation
+
+		err := get(ctx, cluster.Address, "/v1/allocations?namespace=*", "X-Nomad-Token", cluster.Token, &allocs)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"address": cluster.Address,
+				"err":     err,
+			}).Warn("Cannot list nomad allocations")
+			continue
+		}
+
+		var nodes []NomadNode
+		err = get(ctx, cluster.Address, "/v1/nodes", "X-Nomad-Token", cluster.Token, &nodes)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"address": cluster.Address,
+				"err":     err,
+			}).Warn("Cannot list nomad nodes, skipping the datacenter tags")
+		}
+
+		datacenters := map[string]string{}
+		for _, node := range nodes {
+			datacenters[node.ID] = node.Datacenter
+		}
+
+		for _, alloc := range allocs {
+			if alloc.ClientStatus != "running" {
+				continue
+			}
+
+			alloc.Datacenter = datacenters[alloc.NodeID]
+
+			ret = append(ret, alloc.Profiles(cluster.Address)...)
+		}
+	}
+
+	return ret
+}
+
+func (a *Allocation) Profiles(address string) []iterm.Profile {
+	var ret []iterm.Profile
+
+	var tasks []string
+	for task := range a.TaskStates {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+
+	tags := fmt.Sprintf("nomad,job=%s,group=%s,node=%s", a.JobID, a.TaskGroup, a.NodeName)
+	if a.Datacenter != "" {
+		tags = fmt.Sprintf("%s,dc=%s", tags, a.Datacenter)
+	}
+
+	for _, task := range tasks {
+		ret = append(ret, *iterm.NewProfile(fmt.Sprintf("nomad-%s-%s", a.Name, task), map[string]string{
+			"Command": fmt.Sprintf(
+				"/bin/bash -lc 'NOMAD_ADDR=%s nomad alloc exec -namespace %s -task %s %s /bin/sh'",
+				address, a.Namespace, task, a.ID,
+			),
+			"Tags": tags,
+		}))
+	}
+
+	return ret
+}
@@ -0,0 +1,118 @@
+package iterm
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+)
+
+// AgentsProfile is the name of the profile that starts the agents.
+var AgentsProfile = "agents"
+
+// AgentsSnippet returns the shell commands that start ssh-agent (unless
+// one is reachable) and gpg-agent, and add the keys missing from the agent.
+// Shells without a reachable agent use the one listening on the socket in the
+// cache, so all the shells share a single agent.
+func AgentsSnippet(agents *config.Agents) string {
+	sock := cache.Path("ssh-agent.sock")
+
+	commands := []string{
+		fmt.Sprintf(`ssh-add -l >/dev/null 2>&1; [ $? -ne 2 ] || export SSH_AUTH_SOCK=%s`, ShellQuote(sock)),
+		fmt.Sprintf(
+			`ssh-add -l >/dev/null 2>&1; [ $? -ne 2 ] || { mkdir -p %[2]s && rm -f %[1]s && eval "$(ssh-agent -s -a %[1]s)" >/dev/null; }`,
+			ShellQuote(sock), ShellQuote(filepath.Dir(sock)),
+		),
+	}
+
+	for _, key := range agentKeys(agents) {
+		commands = append(commands, fmt.Sprintf(
+			`ssh-add -l | grep -q "$(ssh-keygen -lf %[1]s | cut -d" " -f2)" || ssh-add %[1]s`, ShellQuote(key),
+		))
+	}
+
+	if agents.GPG {
+		commands = append(commands, "gpgconf --launch gpg-agent")
+	}
+
+	return strings.Join(commands, "; ")
+}
+
+// AgentsProfiles returns the agents profile, with the passphrase triggers of
+// the keys.
+func AgentsProfiles(agents *config.Agents) []Profile {
+	if agents == nil {
+		return nil
+	}
+
+	profile := NewProfile(AgentsProfile, map[string]string{
+		"Tags": "agents",
+	})
+	launch := profile.Launch()
+	launch.InitialText = AgentsSnippet(agents)
+	profile.SetLaunch(launch)
+	profile.Triggers = append(profile.Triggers, agentTriggers(agents)...)
+
+	return []Profile{*profile}
+}
+
+// UpdateAgents adds the passphrase triggers of the keys to all the profiles
+// and, with EveryProfile, starts the agents in the local ones.
+func (p *Profiles) UpdateAgents(agents *config.Agents, local func(*Profile) bool) {
+	if agents == nil {
+		return
+	}
+
+	snippet := AgentsSnippet(agents)
+	triggers := agentTriggers(agents)
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if profile.Name == AgentsProfile {
+			continue
+		}
+
+		profile.Triggers = append(profile.Triggers, triggers...)
+
+		if !agents.EveryProfile || !local(profile) {
+			continue
+		}
+
+		profile.AppendInitialText(snippet)
+	}
+}
+
+// agentTriggers answers the passphrase prompts of the keys with an iTerm
+// password manager account named after the key file.
+func agentTriggers(agents *config.Agents) []Trigger {
+	var ret []Trigger
+
+	for _, key := range agentKeys(agents) {
+		ret = append(ret, keyPassphrase(regexp.QuoteMeta(key), filepath.Base(key)))
+	}
+
+	return ret
+}
+
+func agentKeys(agents *config.Agents) []string {
+	var ret []string
+
+	for _, key := range agents.Keys {
+		path, err := homedir.Expand(key)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"key": key,
+				"err": err,
+			}).Fatal("Cannot expand path")
+		}
+
+		ret = append(ret, path)
+	}
+
+	return ret
+}
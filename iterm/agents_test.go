@@ -0,0 +1,144 @@
+package iterm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentsSnippet(t *testing.T) {
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", "/cache")
+
+	agent := `ssh-add -l >/dev/null 2>&1; [ $? -ne 2 ] || export SSH_AUTH_SOCK=/cache/germ/ssh-agent.sock; ` +
+		`ssh-add -l >/dev/null 2>&1; [ $? -ne 2 ] || { mkdir -p /cache/germ && rm -f /cache/germ/ssh-agent.sock && ` +
+		`eval "$(ssh-agent -s -a /cache/germ/ssh-agent.sock)" >/dev/null; }`
+
+	var cases = []struct {
+		name   string
+		agents config.Agents
+		exp    string
+	}{
+		{
+			name: "ssh agent",
+			exp:  agent,
+		},
+		{
+			name: "keys and gpg",
+			agents: config.Agents{
+				Keys: []string{"/keys/work", "/keys/my key"},
+				GPG:  true,
+			},
+			exp: agent + "; " +
+				`ssh-add -l | grep -q "$(ssh-keygen -lf /keys/work | cut -d" " -f2)" || ssh-add /keys/work; ` +
+				`ssh-add -l | grep -q "$(ssh-keygen -lf '/keys/my key' | cut -d" " -f2)" || ssh-add '/keys/my key'; ` +
+				`gpgconf --launch gpg-agent`,
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, AgentsSnippet(&test.agents), test.name)
+	}
+}
+
+func TestUpdateAgents(t *testing.T) {
+	var cases = []struct {
+		name   string
+		agents *config.Agents
+		exp    []string
+	}{
+		{
+			name: "no agents",
+			exp:  []string{"", "", "confirm"},
+		},
+		{
+			name:   "triggers only",
+			agents: &config.Agents{Keys: []string{"/keys/work.pem"}},
+			exp:    []string{"", "", "confirm"},
+		},
+		{
+			name:   "every profile",
+			agents: &config.Agents{Keys: []string{"/keys/work.pem"}, EveryProfile: true},
+			exp: []string{
+				AgentsSnippet(&config.Agents{Keys: []string{"/keys/work.pem"}}),
+				"",
+				fmt.Sprintf("confirm; %s", AgentsSnippet(&config.Agents{Keys: []string{"/keys/work.pem"}})),
+			},
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{
+			Profiles: []Profile{
+				{Name: "local"},
+				{Name: "remote"},
+				{Name: "prod", InitialText: "confirm"},
+			},
+		}
+
+		prof.UpdateAgents(test.agents, func(p *Profile) bool { return p.Name != "remote" })
+
+		var texts []string
+		for _, profile := range prof.Profiles {
+			texts = append(texts, profile.InitialText)
+		}
+
+		assert.Equal(t, test.exp, texts, test.name)
+
+		if test.agents != nil {
+			assert.Equal(t, []Trigger{{
+				Action:    "PasswordTrigger",
+				Parameter: "work.pem",
+				Regex:     `^Enter passphrase for (key ')?/keys/work\.pem`,
+				Partial:   true,
+			}}, prof.Profiles[1].Triggers, test.name)
+		}
+	}
+}
+
+func TestAgentsProfiles(t *testing.T) {
+	assert.Nil(t, AgentsProfiles(nil))
+
+	profiles := AgentsProfiles(&config.Agents{GPG: true})
+	assert.Equal(t, 1, len(profiles))
+	assert.Equal(t, AgentsProfile, profiles[0].Name)
+	assert.Contains(t, profiles[0].InitialText, "gpg-agent")
+}
+
+func TestAgentsSnippetSharesAgent(t *testing.T) {
+	if _, err := exec.LookPath("ssh-agent"); err != nil {
+		t.Skip("ssh-agent is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "agents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", dir)
+
+	snippet := AgentsSnippet(&config.Agents{})
+
+	var pids []string
+	for i := 0; i < 2; i++ {
+		cmd := exec.Command("sh", "-c", snippet+`; echo "$SSH_AGENT_PID"`)
+		cmd.Env = append(os.Environ(), "SSH_AUTH_SOCK=")
+		out, err := cmd.Output()
+		assert.Nil(t, err)
+
+		pids = append(pids, strings.TrimSpace(string(out)))
+	}
+
+	assert.NotEqual(t, "", pids[0])
+	assert.Equal(t, "", pids[1], "the second shell should reuse the agent")
+
+	assert.Nil(t, exec.Command("kill", pids[0]).Run())
+}
@@ -0,0 +1,28 @@
+package iterm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+)
+
+// UpdateAudit wraps the commands of the profiles matching the rule with the
+// `germ exec` shim, so that their start, stop and exit code is logged.
+// Profiles without a custom command get a login shell instead.
+func (p *Profiles) UpdateAudit(rule *config.Audit, germ, shell string) {
+	if rule == nil {
+		return
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if len(rule.Profiles) > 0 {
+			if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+				continue
+			}
+		}
+
+		profile.WrapCommand(fmt.Sprintf("%s exec --profile %s -- %%s", germ, strings.ReplaceAll(profile.GUID, "%", "%%")), shell)
+	}
+}
@@ -0,0 +1,58 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateAudit(t *testing.T) {
+	var cases = []struct {
+		name string
+		rule *config.Audit
+		exp  []string
+	}{
+		{
+			name: "no audit",
+			exp:  []string{"aws s3 ls", "ssh host", ""},
+		},
+		{
+			name: "all custom commands",
+			rule: &config.Audit{},
+			exp: []string{
+				"/bin/germ exec --profile prod-guid -- aws s3 ls",
+				"/bin/germ exec --profile dev-guid -- ssh host",
+				"/bin/germ exec --profile shell-guid -- /bin/zsh -l",
+			},
+		},
+		{
+			name: "matching profiles",
+			rule: &config.Audit{Profiles: []string{"prod*"}},
+			exp: []string{
+				"/bin/germ exec --profile prod-guid -- aws s3 ls",
+				"ssh host",
+				"",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{
+			Profiles: []Profile{
+				{Name: "prod", GUID: "prod-guid", CustomCommand: "Yes", Command: "aws s3 ls"},
+				{Name: "dev", GUID: "dev-guid", CustomCommand: "Yes", Command: "ssh host"},
+				{Name: "shell", GUID: "shell-guid"},
+			},
+		}
+
+		prof.UpdateAudit(test.rule, "/bin/germ", "/bin/zsh")
+
+		var commands []string
+		for _, profile := range prof.Profiles {
+			commands = append(commands, profile.Command)
+		}
+
+		assert.Equal(t, test.exp, commands, test.name)
+	}
+}
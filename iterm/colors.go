@@ -0,0 +1,228 @@
+package iterm
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// ParseColor parses a hex color, ie #1d1f21.
+func ParseColor(hex string) (Color, error) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return Color{}, fmt.Errorf("invalid color %s", hex)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Color{}, fmt.Errorf("invalid color %s", hex)
+	}
+
+	return Color{
+		ColorSpace:     "sRGB",
+		RedComponent:   float64(value>>16&0xff) / 255,
+		GreenComponent: float64(value>>8&0xff) / 255,
+		BlueComponent:  float64(value&0xff) / 255,
+		AlphaComponent: 1,
+	}, nil
+}
+
+// UpdateTheme sets separate light and dark mode colors on all profiles.
+// Missing backgrounds default to the germ colors.
+func (p *Profiles) UpdateTheme(theme *config.Theme) {
+	if theme == nil {
+		return
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+
+		background := profile.BackgroundColor
+		profile.UseSeparateColors = true
+		profile.BackgroundColorDark = themeColor(profile.themeBackground(theme.Dark), &background)
+		profile.BackgroundColorLight = themeColor(profile.themeBackground(theme.Light), &background)
+		profile.ForegroundColorDark = themeColor(theme.Dark.Foreground, nil)
+		profile.ForegroundColorLight = themeColor(theme.Light.Foreground, nil)
+	}
+}
+
+func (p *Profile) themeBackground(colors config.ColorSet) string {
+	switch {
+	case isProd(p.Name), p.HasTag(DangerousTag):
+		return colors.Prod
+	case p.HasTag("k8s"):
+		return colors.K8s
+	}
+
+	return colors.Background
+}
+
+func themeColor(hex string, def *Color) *Color {
+	if hex == "" {
+		return def
+	}
+
+	color, err := ParseColor(hex)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"color": hex,
+			"err":   err,
+		}).Fatal("Cannot parse theme color")
+	}
+
+	return &color
+}
+
+// ParseColorScheme parses the colors of an .itermcolors file.
+func ParseColorScheme(data []byte) (map[string]Color, error) {
+	var ret = map[string]Color{}
+	var key, component string
+	var current Color
+	var depth int
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot parse color scheme")
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "dict":
+				depth++
+				current = Color{
+					ColorSpace:     "Calibrated",
+					AlphaComponent: 1,
+				}
+			case "key":
+				var value string
+				if err := dec.DecodeElement(&value, &t); err != nil {
+					return nil, errors.Wrap(err, "cannot parse color scheme key")
+				}
+
+				if depth == 1 {
+					key = value
+				} else {
+					component = value
+				}
+			case "real", "integer", "string":
+				var value string
+				if err := dec.DecodeElement(&value, &t); err != nil {
+					return nil, errors.Wrap(err, "cannot parse color scheme value")
+				}
+
+				if depth == 2 {
+					if err := current.set(component, value); err != nil {
+						return nil, errors.Wrapf(err, "invalid value for %s", key)
+					}
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "dict" {
+				if depth == 2 {
+					ret[key] = current
+				}
+				depth--
+			}
+		}
+	}
+
+	if len(ret) == 0 {
+		return nil, errors.New("no colors found")
+	}
+
+	return ret, nil
+}
+
+func (c *Color) set(component, value string) error {
+	if component == "Color Space" {
+		c.ColorSpace = value
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return err
+	}
+
+	switch component {
+	case "Alpha Component":
+		c.AlphaComponent = v
+	case "Red Component":
+		c.RedComponent = v
+	case "Green Component":
+		c.GreenComponent = v
+	case "Blue Component":
+		c.BlueComponent = v
+	}
+
+	return nil
+}
+
+// LoadColorScheme reads an .itermcolors file.
+func LoadColorScheme(path string) map[string]Color {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot read color scheme")
+	}
+
+	scheme, err := ParseColorScheme(data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"path": path,
+			"err":  err,
+		}).Fatal("Cannot parse color scheme")
+	}
+
+	return scheme
+}
+
+// UpdateColorSchemes embeds the color scheme mapped to each profile in the
+// config. The schemes are read from dir.
+func (p *Profiles) UpdateColorSchemes(schemes map[string]string, dir string) {
+	var loaded = map[string]map[string]Color{}
+
+	for i := range p.Profiles {
+		name, found := config.Lookup(schemes, p.Profiles[i].Name)
+		if !found {
+			continue
+		}
+
+		if _, ok := loaded[name]; !ok {
+			loaded[name] = LoadColorScheme(filepath.Join(dir, fmt.Sprintf("%s.itermcolors", name)))
+		}
+
+		p.Profiles[i].SetColorScheme(loaded[name])
+	}
+}
+
+// SetColorScheme sets the colors of the scheme on the profile.
+func (p *Profile) SetColorScheme(scheme map[string]Color) {
+	p.ColorScheme = map[string]Color{}
+
+	for key, color := range scheme {
+		if key == "Background Color" {
+			p.BackgroundColor = color
+			continue
+		}
+
+		p.ColorScheme[key] = color
+	}
+}
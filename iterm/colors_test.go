@@ -0,0 +1,134 @@
+package iterm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	color, err := ParseColor("#ff0080")
+	assert.Nil(t, err)
+	assert.Equal(t, Color{
+		ColorSpace:     "sRGB",
+		RedComponent:   1,
+		GreenComponent: 0,
+		BlueComponent:  float64(0x80) / 255,
+		AlphaComponent: 1,
+	}, color)
+
+	_, err = ParseColor("#fff")
+	assert.NotNil(t, err)
+
+	_, err = ParseColor("#zzzzzz")
+	assert.NotNil(t, err)
+}
+
+func TestUpdateTheme(t *testing.T) {
+	theme := &config.Theme{
+		Light: config.ColorSet{
+			Foreground: "#000000",
+			Background: "#ffffff",
+			Prod:       "#ffdddd",
+		},
+	}
+
+	profiles := Profiles{
+		Profiles: []Profile{
+			*NewProfile("dev", map[string]string{}),
+			*NewProfile("prod", map[string]string{}),
+			*NewProfile("cluster", map[string]string{"Tags": "k8s"}),
+		},
+	}
+
+	profiles.UpdateTheme(theme)
+
+	for _, profile := range profiles.Profiles {
+		assert.True(t, profile.UseSeparateColors, profile.Name)
+		assert.Equal(t, profile.BackgroundColor, *profile.BackgroundColorDark, profile.Name)
+		assert.Equal(t, float64(0), profile.ForegroundColorLight.RedComponent, profile.Name)
+	}
+
+	assert.Equal(t, float64(1), profiles.Profiles[0].BackgroundColorLight.BlueComponent)
+	assert.Equal(t, float64(0xdd)/255, profiles.Profiles[1].BackgroundColorLight.BlueComponent)
+	assert.Equal(t, profiles.Profiles[2].BackgroundColor, *profiles.Profiles[2].BackgroundColorLight)
+}
+
+var colorScheme = heredoc.Doc(`
+	<?xml version="1.0" encoding="UTF-8"?>
+	<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+	<plist version="1.0">
+	<dict>
+		<key>Ansi 0 Color</key>
+		<dict>
+			<key>Alpha Component</key>
+			<real>1</real>
+			<key>Blue Component</key>
+			<real>0.5</real>
+			<key>Color Space</key>
+			<string>sRGB</string>
+			<key>Green Component</key>
+			<real>0.25</real>
+			<key>Red Component</key>
+			<real>0</real>
+		</dict>
+		<key>Background Color</key>
+		<dict>
+			<key>Blue Component</key>
+			<real>0.1</real>
+			<key>Green Component</key>
+			<real>0.1</real>
+			<key>Red Component</key>
+			<real>0.1</real>
+		</dict>
+	</dict>
+	</plist>
+`)
+
+func TestParseColorScheme(t *testing.T) {
+	scheme, err := ParseColorScheme([]byte(colorScheme))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]Color{
+		"Ansi 0 Color": {
+			ColorSpace:     "sRGB",
+			AlphaComponent: 1,
+			BlueComponent:  0.5,
+			GreenComponent: 0.25,
+		},
+		"Background Color": {
+			ColorSpace:     "Calibrated",
+			AlphaComponent: 1,
+			BlueComponent:  0.1,
+			GreenComponent: 0.1,
+			RedComponent:   0.1,
+		},
+	}, scheme)
+
+	_, err = ParseColorScheme([]byte("<plist></plist>"))
+	assert.NotNil(t, err)
+
+	_, err = ParseColorScheme([]byte("<plist><dict>"))
+	assert.NotNil(t, err)
+}
+
+func TestSetColorScheme(t *testing.T) {
+	scheme, err := ParseColorScheme([]byte(colorScheme))
+	assert.Nil(t, err)
+
+	profile := NewProfile("dev", map[string]string{})
+	profile.SetColorScheme(scheme)
+
+	assert.Equal(t, scheme["Background Color"], profile.BackgroundColor)
+	assert.Equal(t, map[string]Color{"Ansi 0 Color": scheme["Ansi 0 Color"]}, profile.ColorScheme)
+
+	data, err := json.Marshal(profile)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Ansi 0 Color":`)
+
+	var read Profile
+	assert.Nil(t, json.Unmarshal(data, &read))
+	assert.Equal(t, *profile, read)
+}
@@ -0,0 +1,61 @@
+package iterm
+
+import (
+	"fmt"
+
+	"github.com/mhristof/germ/config"
+)
+
+// DangerousTag is added to the profiles marked as dangerous.
+const DangerousTag = "dangerous"
+
+// IsDangerous reports whether the profile matches the names or the tags of
+// the rule.
+func (p *Profile) IsDangerous(rule *config.Dangerous) bool {
+	if _, found := config.MatchKey(rule.Profiles, p.Name); found {
+		return true
+	}
+
+	for _, tag := range rule.Tags {
+		if p.HasTag(tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UpdateDangerous gives the dangerous profiles the production background, a
+// badge and an initial text that asks for confirmation before the session
+// can be used, ahead of their own initial text.
+func (p *Profiles) UpdateDangerous(rule *config.Dangerous) {
+	if rule == nil {
+		return
+	}
+
+	badge := rule.Badge
+	if badge == "" {
+		badge = "PROD"
+	}
+
+	prompt := rule.Prompt
+	if prompt == "" {
+		prompt = "Production, continue?"
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if !profile.IsDangerous(rule) {
+			continue
+		}
+
+		profile.Tags = append(profile.Tags, DangerousTag)
+		profile.BackgroundColor = prodBackground
+		if profile.BadgeText == "" {
+			profile.BadgeText = badge
+		} else {
+			profile.BadgeText = fmt.Sprintf("%s\n%s", badge, profile.BadgeText)
+		}
+		profile.PrependInitialText(fmt.Sprintf(`printf '%%s' %s; read germ_confirm; [ "$germ_confirm" = y ] || exit`, ShellQuote(prompt+" [y/N] ")))
+	}
+}
@@ -0,0 +1,98 @@
+package iterm
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateDangerous(t *testing.T) {
+	profiles := Profiles{
+		Profiles: []Profile{
+			*NewProfile("config-live", map[string]string{}),
+			*NewProfile("cluster", map[string]string{"Tags": "k8s,env=live"}),
+			*NewProfile("config-dev", map[string]string{}),
+		},
+	}
+
+	profiles.UpdateDangerous(&config.Dangerous{
+		Profiles: []string{"*-live"},
+		Tags:     []string{"env=live"},
+	})
+
+	for _, profile := range profiles.Profiles[:2] {
+		assert.True(t, profile.HasTag(DangerousTag), profile.Name)
+		assert.Equal(t, prodBackground, profile.BackgroundColor, profile.Name)
+		assert.Equal(t, "PROD\n"+profile.Name, profile.BadgeText, profile.Name)
+		assert.Equal(t, `printf '%s' 'Production, continue? [y/N] '; read germ_confirm; [ "$germ_confirm" = y ] || exit`, profile.InitialText, profile.Name)
+	}
+
+	assert.False(t, profiles.Profiles[2].HasTag(DangerousTag))
+	assert.Equal(t, "", profiles.Profiles[2].InitialText)
+
+	profiles.UpdateDangerous(nil)
+}
+
+func TestUpdateDangerousText(t *testing.T) {
+	var cases = []struct {
+		name    string
+		rule    config.Dangerous
+		profile Profile
+		badge   string
+		initial string
+	}{
+		{
+			name:    "no badge or initial text",
+			rule:    config.Dangerous{Profiles: []string{"*"}},
+			profile: Profile{Name: "prod"},
+			badge:   "PROD",
+			initial: `printf '%s' 'Production, continue? [y/N] '; read germ_confirm; [ "$germ_confirm" = y ] || exit`,
+		},
+		{
+			name:    "existing initial text",
+			rule:    config.Dangerous{Profiles: []string{"*"}, Badge: "LIVE"},
+			profile: Profile{Name: "s3-bucket", BadgeText: "bucket", InitialText: "aws s3 ls s3://bucket"},
+			badge:   "LIVE\nbucket",
+			initial: `printf '%s' 'Production, continue? [y/N] '; read germ_confirm; [ "$germ_confirm" = y ] || exit; aws s3 ls s3://bucket`,
+		},
+		{
+			name:    "prompt with shell characters",
+			rule:    config.Dangerous{Profiles: []string{"*"}, Prompt: `"$HOME" isn't ` + "`prod`"},
+			profile: Profile{Name: "prod"},
+			badge:   "PROD",
+			initial: `printf '%s' '"$HOME" isn'\''t ` + "`prod`" + ` [y/N] '; read germ_confirm; [ "$germ_confirm" = y ] || exit`,
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{Profiles: []Profile{test.profile}}
+
+		prof.UpdateDangerous(&test.rule)
+
+		assert.Equal(t, test.badge, prof.Profiles[0].BadgeText, test.name)
+		assert.Equal(t, test.initial, prof.Profiles[0].InitialText, test.name)
+	}
+}
+
+func TestUpdateDangerousShells(t *testing.T) {
+	prof := Profiles{Profiles: []Profile{{Name: "prod"}}}
+	prof.UpdateDangerous(&config.Dangerous{Profiles: []string{"*"}, Prompt: `"$HOME" isn't prod`})
+
+	for _, shell := range []string{"sh", "bash", "zsh"} {
+		if _, err := exec.LookPath(shell); err != nil {
+			continue
+		}
+
+		for answer, exp := range map[string]string{"y": "continued\n", "n": ""} {
+			cmd := exec.Command(shell, "-c", prof.Profiles[0].InitialText+"; echo continued")
+			cmd.Stdin = strings.NewReader(answer + "\n")
+
+			// The session exits with an error without a confirmation.
+			out, _ := cmd.CombinedOutput()
+			assert.Equal(t, `"$HOME" isn't prod [y/N] `+exp, string(out), shell)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package iterm
+
+import (
+	"time"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+)
+
+// Expiry is a profile that outlived its TTL.
+type Expiry struct {
+	GUID    string
+	Name    string
+	Created time.Time
+	TTL     time.Duration
+}
+
+// TTL returns the time to live of the profile, from its ttl= tag or the
+// config.
+func (p *Profile) TTL(ttls map[string]string) (time.Duration, bool, error) {
+	ttl, found := p.FindTag("ttl")
+	if !found {
+		ttl, found = config.Lookup(ttls, p.Name)
+	}
+
+	if !found {
+		return 0, false, nil
+	}
+
+	duration, err := config.ParseTTL(ttl)
+
+	return duration, err == nil, err
+}
+
+// Expire removes the profiles older than their TTL and returns them. The
+// created map records when each profile was first generated, new profiles
+// are added to it and the removed GUIDs are dropped. Profiles missing from
+// this generation only, ie of a source that failed, keep their creation time.
+func (p *Profiles) Expire(created map[string]time.Time, removed []string, ttls map[string]string, now time.Time) ([]Expiry, error) {
+	var ret []Expiry
+	var keep []Profile
+
+	for _, profile := range p.Profiles {
+		if _, found := created[profile.GUID]; !found {
+			created[profile.GUID] = now
+		}
+
+		ttl, found, err := profile.TTL(ttls)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid ttl for %s", profile.Name)
+		}
+
+		if found && now.Sub(created[profile.GUID]) > ttl {
+			ret = append(ret, Expiry{
+				GUID:    profile.GUID,
+				Name:    profile.Name,
+				Created: created[profile.GUID],
+				TTL:     ttl,
+			})
+			continue
+		}
+
+		keep = append(keep, profile)
+	}
+
+	for _, guid := range removed {
+		delete(created, guid)
+	}
+
+	p.Profiles = keep
+
+	return ret, nil
+}
@@ -0,0 +1,47 @@
+package iterm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpire(t *testing.T) {
+	now := time.Date(2021, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	profiles := Profiles{
+		Profiles: []Profile{
+			*NewProfile("incident-1", map[string]string{}),
+			*NewProfile("incident-2", map[string]string{}),
+			*NewProfile("temporary", map[string]string{"Tags": "ttl=1h"}),
+			*NewProfile("prod", map[string]string{}),
+		},
+	}
+
+	created := map[string]time.Time{
+		"incident-1": now.Add(-4 * 24 * time.Hour),
+		"temporary":  now.Add(-2 * time.Hour),
+		"removed":    now.Add(-time.Hour),
+		"failed":     now.Add(-time.Hour),
+	}
+
+	expired, err := profiles.Expire(created, []string{"removed"}, map[string]string{"incident-*": "3d"}, now)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []Expiry{
+		{GUID: "incident-1", Name: "incident-1", Created: now.Add(-4 * 24 * time.Hour), TTL: 3 * 24 * time.Hour},
+		{GUID: "temporary", Name: "temporary", Created: now.Add(-2 * time.Hour), TTL: time.Hour},
+	}, expired)
+
+	assert.Equal(t, 2, len(profiles.Profiles))
+	assert.Equal(t, now, created["incident-2"])
+	_, found := created["removed"]
+	assert.False(t, found)
+	assert.Equal(t, now.Add(-time.Hour), created["failed"], "missing profiles keep their creation time")
+	_, found = created["incident-1"]
+	assert.True(t, found, "expired profiles keep their creation time")
+
+	_, err = profiles.Expire(created, nil, map[string]string{"prod": "soon"}, now)
+	assert.NotNil(t, err)
+}
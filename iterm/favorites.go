@@ -0,0 +1,51 @@
+package iterm
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mhristof/germ/config"
+)
+
+// FavoriteTag is added to the profiles listed in the favorites.
+const FavoriteTag = "favorite"
+
+// Usage orders the profiles with the favorites first and then the most
+// recently used ones.
+type Usage struct {
+	// Favorites are profile names (or globs).
+	Favorites []string
+	// LastUsed maps the profile GUIDs to their last use.
+	LastUsed map[string]time.Time
+}
+
+// IsFavorite reports whether the profile is in the favorites.
+func (u Usage) IsFavorite(p *Profile) bool {
+	_, found := config.MatchKey(u.Favorites, p.Name)
+
+	return found
+}
+
+// Less reports whether a goes before b.
+func (u Usage) Less(a, b *Profile) bool {
+	favoriteA, favoriteB := u.IsFavorite(a), u.IsFavorite(b)
+	if favoriteA != favoriteB {
+		return favoriteA
+	}
+
+	return u.LastUsed[a.GUID].After(u.LastUsed[b.GUID])
+}
+
+// UpdateFavorites tags the favorite profiles and orders the profiles by
+// usage. Profiles that are neither favorites nor used keep their order.
+func (p *Profiles) UpdateFavorites(usage Usage) {
+	for i := range p.Profiles {
+		if usage.IsFavorite(&p.Profiles[i]) {
+			p.Profiles[i].Tags = append(p.Profiles[i].Tags, FavoriteTag)
+		}
+	}
+
+	sort.SliceStable(p.Profiles, func(i, j int) bool {
+		return usage.Less(&p.Profiles[i], &p.Profiles[j])
+	})
+}
@@ -0,0 +1,40 @@
+package iterm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateFavorites(t *testing.T) {
+	now := time.Now()
+
+	prof := Profiles{
+		Profiles: []Profile{
+			{Name: "a", GUID: "a"},
+			{Name: "b", GUID: "b"},
+			{Name: "prod-c", GUID: "c"},
+			{Name: "d", GUID: "d"},
+			{Name: "prod-e", GUID: "e"},
+		},
+	}
+
+	prof.UpdateFavorites(Usage{
+		Favorites: []string{"prod-*"},
+		LastUsed: map[string]time.Time{
+			"d": now,
+			"b": now.Add(-time.Hour),
+			"e": now.Add(-time.Minute),
+		},
+	})
+
+	var names []string
+	for _, profile := range prof.Profiles {
+		names = append(names, profile.Name)
+	}
+
+	assert.Equal(t, []string{"prod-e", "prod-c", "d", "b", "a"}, names)
+	assert.True(t, prof.Profiles[0].HasTag(FavoriteTag))
+	assert.False(t, prof.Profiles[2].HasTag(FavoriteTag))
+}
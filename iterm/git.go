@@ -0,0 +1,93 @@
+package iterm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultWorkspace is where the git remotes are cloned, unless configured.
+const DefaultWorkspace = "~/src"
+
+// gitRemote is a kind of git remote recognised by the smart selection.
+type gitRemote struct {
+	notes string
+	regex string
+	// web is the browser URL of the repository.
+	web string
+	// clone is the remote to clone, and dir the checkout directory under
+	// the workspace.
+	clone string
+	dir   string
+}
+
+var gitRemotes = []gitRemote{
+	{
+		notes: "git ssh remote",
+		regex: `git@([\w.-]+):([\w.-]+)/([\w-]+(?:\.[\w-]+)*?)(?:\.git)?\b`,
+		web:   `https://\1/\2/\3`,
+		clone: `\0`,
+		dir:   `\1/\2/\3`,
+	},
+	{
+		notes: "github and gitlab repository",
+		regex: `https://(github\.com|gitlab\.com)/([\w.-]+)/([\w-]+(?:\.[\w-]+)*?)(?:\.git)?\b`,
+		web:   `https://\1/\2/\3`,
+		clone: `https://\1/\2/\3.git`,
+		dir:   `\1/\2/\3`,
+	},
+	{
+		notes: "codecommit https remote",
+		regex: `https://git-codecommit\.([\w-]+)\.amazonaws\.com/v1/repos/([\w.-]+)`,
+		web:   `https://\1.console.aws.amazon.com/codesuite/codecommit/repositories/\2/browse?region=\1`,
+		clone: `\0`,
+		dir:   `codecommit/\2`,
+	},
+	{
+		notes: "codecommit grc remote",
+		regex: `codecommit::([\w-]+)://(?:[\w-]+@)?([\w.-]+)`,
+		web:   `https://\1.console.aws.amazon.com/codesuite/codecommit/repositories/\2/browse?region=\1`,
+		clone: `\0`,
+		dir:   `codecommit/\2`,
+	},
+}
+
+// GitSmartSelectionRules returns the rules to open the git remotes in the
+// browser or clone them in the workspace.
+func GitSmartSelectionRules(workspace string) []SmartSelectionRule {
+	if workspace == "" {
+		workspace = DefaultWorkspace
+	}
+	workspace = strings.TrimSuffix(workspace, "/")
+
+	var ret []SmartSelectionRule
+	for _, remote := range gitRemotes {
+		ret = append(ret, SmartSelectionRule{
+			Notes:     remote.notes,
+			Precision: "high",
+			Regex:     remote.regex,
+			Actions: []SmartSelectionRuleAction{
+				{
+					Title:     "open webpage",
+					Action:    1,
+					Parameter: remote.web,
+				},
+				{
+					Title:     "clone in the workspace",
+					Action:    5,
+					Parameter: fmt.Sprintf("git clone %s %s/%s", remote.clone, workspace, remote.dir),
+				},
+			},
+		})
+	}
+
+	return ret
+}
+
+// UpdateGitSmartSelectionRules adds the git remote rules to every profile.
+func (p *Profiles) UpdateGitSmartSelectionRules(workspace string) {
+	ssr := GitSmartSelectionRules(workspace)
+
+	for i := range p.Profiles {
+		p.Profiles[i].SmartSelectionRules = append(p.Profiles[i].SmartSelectionRules, ssr...)
+	}
+}
@@ -0,0 +1,74 @@
+package iterm
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// expand replaces the \N references of the parameter with the groups of the
+// first match, like iTerm does.
+func expand(rule SmartSelectionRule, parameter, text string) string {
+	match := regexp.MustCompile(rule.Regex).FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+
+	for i := len(match) - 1; i >= 0; i-- {
+		parameter = strings.ReplaceAll(parameter, `\`+string(rune('0'+i)), match[i])
+	}
+
+	return parameter
+}
+
+func TestGitSmartSelectionRules(t *testing.T) {
+	var cases = []struct {
+		name  string
+		text  string
+		web   string
+		clone string
+	}{
+		{
+			name:  "ssh remote",
+			text:  "git@github.com:mhristof/germ.git",
+			web:   "https://github.com/mhristof/germ",
+			clone: "git clone git@github.com:mhristof/germ.git ~/code/github.com/mhristof/germ",
+		},
+		{
+			name:  "github url",
+			text:  "https://github.com/mhristof/germ",
+			web:   "https://github.com/mhristof/germ",
+			clone: "git clone https://github.com/mhristof/germ.git ~/code/github.com/mhristof/germ",
+		},
+		{
+			name:  "codecommit https",
+			text:  "https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/infra",
+			web:   "https://eu-west-1.console.aws.amazon.com/codesuite/codecommit/repositories/infra/browse?region=eu-west-1",
+			clone: "git clone https://git-codecommit.eu-west-1.amazonaws.com/v1/repos/infra ~/code/codecommit/infra",
+		},
+		{
+			name:  "codecommit grc",
+			text:  "codecommit::eu-west-1://prod@infra",
+			web:   "https://eu-west-1.console.aws.amazon.com/codesuite/codecommit/repositories/infra/browse?region=eu-west-1",
+			clone: "git clone codecommit::eu-west-1://prod@infra ~/code/codecommit/infra",
+		},
+	}
+
+	rules := GitSmartSelectionRules("~/code/")
+	for _, test := range cases {
+		var web, clone string
+		for _, rule := range rules {
+			if web == "" {
+				web = expand(rule, rule.Actions[0].Parameter, test.text)
+				clone = expand(rule, rule.Actions[1].Parameter, test.text)
+			}
+		}
+
+		assert.Equal(t, test.web, web, test.name)
+		assert.Equal(t, test.clone, clone, test.name)
+	}
+
+	assert.True(t, strings.HasSuffix(GitSmartSelectionRules("")[0].Actions[1].Parameter, DefaultWorkspace+`/\1/\2/\3`))
+}
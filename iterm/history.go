@@ -0,0 +1,55 @@
+package iterm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+)
+
+// DefaultHistoryDirectory is used by the history rule without a directory.
+const DefaultHistoryDirectory = "~/.history/germ"
+
+// UpdateHistory gives the sessions of the profiles matching the rule their
+// own HISTFILE, named after the profile, and returns the history directory
+// the shells expect to exist.
+func (p *Profiles) UpdateHistory(rule *config.History, shell string) []string {
+	if rule == nil {
+		return nil
+	}
+
+	directory := rule.Directory
+	if directory == "" {
+		directory = DefaultHistoryDirectory
+	}
+
+	expanded, err := homedir.Expand(directory)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"directory": directory,
+			"err":       err,
+		}).Warn("Cannot expand history directory")
+		return nil
+	}
+
+	var matched bool
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+			continue
+		}
+
+		histfile := filepath.Join(expanded, strings.ReplaceAll(profile.Name, "/", "-"))
+		profile.AddEnv(shell, fmt.Sprintf("HISTFILE=%s", ShellQuote(histfile)))
+		matched = true
+	}
+
+	if !matched {
+		return nil
+	}
+
+	return []string{expanded}
+}
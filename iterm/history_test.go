@@ -0,0 +1,48 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateHistory(t *testing.T) {
+	var cases = []struct {
+		name    string
+		rule    *config.History
+		command []string
+		dirs    []string
+	}{
+		{
+			name:    "no rule",
+			command: []string{"/usr/bin/env AWS_PROFILE=prod ssh prod", ""},
+		},
+		{
+			name:    "matching profiles",
+			rule:    &config.History{Profiles: []string{"prod*"}, Directory: "/tmp/history"},
+			command: []string{"/usr/bin/env HISTFILE=/tmp/history/prod-bastion AWS_PROFILE=prod ssh prod", "/usr/bin/env HISTFILE=/tmp/history/prod-shell /bin/zsh -l"},
+			dirs:    []string{"/tmp/history"},
+		},
+		{
+			name:    "no match",
+			rule:    &config.History{Profiles: []string{"dev"}},
+			command: []string{"/usr/bin/env AWS_PROFILE=prod ssh prod", ""},
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{
+			Profiles: []Profile{
+				{Name: "prod-bastion", CustomCommand: "Yes", Command: "/usr/bin/env AWS_PROFILE=prod ssh prod"},
+				{Name: "prod-shell"},
+			},
+		}
+
+		assert.Equal(t, test.dirs, prof.UpdateHistory(test.rule, "/bin/zsh"), test.name)
+
+		for i, profile := range prof.Profiles {
+			assert.Equal(t, test.command[i], profile.Command, test.name)
+		}
+	}
+}
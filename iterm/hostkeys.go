@@ -0,0 +1,50 @@
+package iterm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+)
+
+// UpdateHostKeys pins the host keys of the ssh profiles matching the rule.
+// known returns the known_hosts file with the keys of the host and port, and
+// false if the keys cannot be fetched, in which case the profile is left as
+// it is.
+func (p *Profiles) UpdateHostKeys(rule *config.HostKeys, known func(host, port string) (string, bool)) {
+	if rule == nil {
+		return
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+			continue
+		}
+
+		if profile.CustomCommand != "Yes" {
+			continue
+		}
+
+		ssh, found := parseSSH(profile.Command)
+		if !found {
+			continue
+		}
+
+		path, found := known(ssh.Host(), ssh.Port)
+		if !found {
+			continue
+		}
+
+		options := []string{
+			fmt.Sprintf("-o UserKnownHostsFile=%s", ShellQuote(path)),
+			"-o StrictHostKeyChecking=yes",
+		}
+
+		fields := append([]string{}, ssh.Fields[:ssh.Index+1]...)
+		fields = append(fields, options...)
+		fields = append(fields, ssh.Fields[ssh.Index+1:]...)
+
+		profile.Command = strings.Join(fields, " ")
+	}
+}
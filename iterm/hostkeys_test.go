@@ -0,0 +1,31 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateHostKeys(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("bastion", map[string]string{"Command": "/usr/bin/env TMOUT=900 ssh -p 2222 admin@bastion"}),
+			*NewProfile("unreachable", map[string]string{"Command": "ssh unreachable"}),
+			*NewProfile("tf-web", map[string]string{"Command": "aws ssm start-session --target i-0abc"}),
+			*NewProfile("other", map[string]string{"Command": "ssh other"}),
+		},
+	}
+
+	var scanned []string
+	prof.UpdateHostKeys(&config.HostKeys{Profiles: []string{"bastion", "unreachable", "tf-*"}}, func(host, port string) (string, bool) {
+		scanned = append(scanned, host+":"+port)
+		return "/keys/" + host, host != "unreachable"
+	})
+
+	assert.Equal(t, []string{"bastion:2222", "unreachable:22"}, scanned)
+	assert.Equal(t, "/usr/bin/env TMOUT=900 ssh -o UserKnownHostsFile=/keys/bastion -o StrictHostKeyChecking=yes -p 2222 admin@bastion", prof.Profiles[0].Command)
+	assert.Equal(t, "ssh unreachable", prof.Profiles[1].Command)
+	assert.Equal(t, "aws ssm start-session --target i-0abc", prof.Profiles[2].Command)
+	assert.Equal(t, "ssh other", prof.Profiles[3].Command)
+}
@@ -0,0 +1,34 @@
+package iterm
+
+import (
+	"fmt"
+
+	"github.com/mhristof/germ/config"
+)
+
+// UpdateIdle makes the sessions of the profiles matching the rule exit once
+// idle. The local shells get TMOUT in their environment, and the profiles
+// with a custom command, ie ssh or SSM sessions, export it in the remote
+// shell with the initial text. It runs before UpdateShellEnv, which gives the
+// local shells a custom command too.
+func (p *Profiles) UpdateIdle(rule *config.Idle, shell string) {
+	if rule == nil || rule.Minutes <= 0 {
+		return
+	}
+
+	tmout := fmt.Sprintf("TMOUT=%d", rule.Minutes*60)
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+			continue
+		}
+
+		remote := profile.CustomCommand == "Yes" && profile.Command != ""
+
+		profile.AddEnv(shell, tmout)
+		if remote {
+			profile.AppendInitialText(fmt.Sprintf("export %s", tmout))
+		}
+	}
+}
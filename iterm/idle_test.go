@@ -0,0 +1,68 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateIdle(t *testing.T) {
+	var cases = []struct {
+		name    string
+		rule    *config.Idle
+		command []string
+		initial []string
+	}{
+		{
+			name:    "no rule",
+			command: []string{"ssh prod", ""},
+			initial: []string{"", ""},
+		},
+		{
+			name:    "matching profiles",
+			rule:    &config.Idle{Profiles: []string{"prod*"}, Minutes: 15},
+			command: []string{"/usr/bin/env TMOUT=900 ssh prod", "/usr/bin/env TMOUT=900 /bin/zsh -l"},
+			initial: []string{"export TMOUT=900", ""},
+		},
+		{
+			name:    "no match",
+			rule:    &config.Idle{Profiles: []string{"dev"}, Minutes: 15},
+			command: []string{"ssh prod", ""},
+			initial: []string{"", ""},
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{
+			Profiles: []Profile{
+				{Name: "prod-bastion", CustomCommand: "Yes", Command: "ssh prod"},
+				{Name: "prod-shell"},
+			},
+		}
+
+		prof.UpdateIdle(test.rule, "/bin/zsh")
+
+		for i, profile := range prof.Profiles {
+			assert.Equal(t, test.command[i], profile.Command, test.name)
+			assert.Equal(t, test.initial[i], profile.InitialText, test.name)
+		}
+	}
+}
+
+func TestUpdateIdleShellEnv(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			{Name: "prod-bastion", CustomCommand: "Yes", Command: "ssh prod"},
+			{Name: "prod-shell"},
+		},
+	}
+
+	prof.UpdateIdle(&config.Idle{Profiles: []string{"prod*"}, Minutes: 15}, "/bin/zsh")
+	prof.UpdateShellEnv("/bin/zsh")
+
+	assert.Equal(t, "/usr/bin/env GERM_PROFILE=prod-bastion TMOUT=900 ssh prod", prof.Profiles[0].Command)
+	assert.Equal(t, "export TMOUT=900", prof.Profiles[0].InitialText)
+	assert.Equal(t, "/usr/bin/env GERM_PROFILE=prod-shell TMOUT=900 /bin/zsh -l", prof.Profiles[1].Command)
+	assert.Equal(t, "", prof.Profiles[1].InitialText)
+}
@@ -0,0 +1,109 @@
+package iterm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// localPackages maps the commands with a not found trigger in local shells
+// to their package, per package manager.
+var localPackages = map[string]map[string]string{
+	"brew": {
+		"aws":       "awscli",
+		"git":       "git",
+		"helm":      "helm",
+		"jq":        "jq",
+		"kubectl":   "kubernetes-cli",
+		"ssh-add":   "openssh",
+		"terraform": "hashicorp/tap/terraform",
+	},
+	"asdf": {
+		"aws":       "awscli",
+		"helm":      "helm",
+		"jq":        "jq",
+		"kubectl":   "kubectl",
+		"terraform": "terraform",
+	},
+	"mise": {
+		"aws":       "awscli",
+		"helm":      "helm",
+		"jq":        "jq",
+		"kubectl":   "kubectl",
+		"terraform": "terraform",
+	},
+}
+
+func localNotFound(name string) string {
+	return fmt.Sprintf("^(zsh: command not found: %[1]s|(bash|/bin/sh): %[1]s: (command )?not found)", name)
+}
+
+func install(manager, pkg string) string {
+	switch manager {
+	case "asdf":
+		return fmt.Sprintf("asdf plugin add %[1]s; asdf install %[1]s latest && asdf global %[1]s latest", pkg)
+	case "mise":
+		return fmt.Sprintf("mise use --global %s@latest", pkg)
+	}
+
+	return fmt.Sprintf("brew install %s", pkg)
+}
+
+// LocalTriggers returns the not found triggers of a local macOS shell, that
+// install the missing command with the package manager.
+func LocalTriggers(manager string) ([]Trigger, error) {
+	packages, found := localPackages[manager]
+	if !found {
+		return nil, errors.Errorf("unknown package manager %s", manager)
+	}
+
+	var commands []string
+	for command := range packages {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	var ret []Trigger
+	for _, command := range commands {
+		ret = append(ret, Trigger{
+			Action:    "SendTextTrigger",
+			Parameter: install(manager, packages[command]),
+			Regex:     localNotFound(command),
+		})
+	}
+
+	return ret, nil
+}
+
+// UpdateLocalTriggers replaces the apt, yum and apk not found triggers of the
+// local profiles with the ones of the package manager.
+func (p *Profiles) UpdateLocalTriggers(manager string, local func(*Profile) bool) error {
+	if manager == "" {
+		return nil
+	}
+
+	triggers, err := LocalTriggers(manager)
+	if err != nil {
+		return err
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if !local(profile) {
+			continue
+		}
+
+		var kept []Trigger
+		for _, trigger := range profile.Triggers {
+			if !strings.HasPrefix(trigger.Regex, notFoundPrefix) {
+				kept = append(kept, trigger)
+			}
+		}
+
+		profile.Triggers = append(kept, triggers...)
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package iterm
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLocalTriggers(t *testing.T) {
+	var cases = []struct {
+		name    string
+		manager string
+		local   bool
+		git     string
+	}{
+		{
+			name:  "no installer",
+			local: true,
+			git:   apt("git"),
+		},
+		{
+			name:    "remote profile",
+			manager: "brew",
+			git:     apt("git"),
+		},
+		{
+			name:    "brew",
+			manager: "brew",
+			local:   true,
+			git:     "brew install git",
+		},
+		{
+			name:    "mise",
+			manager: "mise",
+			local:   true,
+			git:     "",
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{Profiles: []Profile{*NewProfile("shell", map[string]string{})}}
+
+		err := prof.UpdateLocalTriggers(test.manager, func(*Profile) bool { return test.local })
+		assert.Nil(t, err, test.name)
+
+		var git string
+		for _, trigger := range prof.Profiles[0].Triggers {
+			if regexp.MustCompile(trigger.Regex).MatchString("zsh: command not found: git") ||
+				regexp.MustCompile(trigger.Regex).MatchString("bash: git: command not found") {
+				git = trigger.Parameter
+			}
+		}
+
+		assert.Equal(t, test.git, git, test.name)
+		assert.Contains(t, prof.Profiles[0].Triggers[0].Action, "PasswordTrigger", test.name)
+	}
+
+	prof := Profiles{Profiles: []Profile{*NewProfile("shell", map[string]string{})}}
+	assert.NotNil(t, prof.UpdateLocalTriggers("port", func(*Profile) bool { return true }))
+}
+
+func TestLocalTriggers(t *testing.T) {
+	triggers, err := LocalTriggers("asdf")
+	assert.Nil(t, err)
+	assert.Equal(t, Trigger{
+		Action:    "SendTextTrigger",
+		Parameter: "asdf plugin add terraform; asdf install terraform latest && asdf global terraform latest",
+		Regex:     localNotFound("terraform"),
+	}, triggers[len(triggers)-1])
+}
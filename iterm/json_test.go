@@ -0,0 +1,77 @@
+package iterm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTrip reports whether the profile survives the JSON of the profiles.
+func roundTrip(t *testing.T, name, command string) bool {
+	profile := NewProfile(name, map[string]string{"Command": command})
+	profile.ColorScheme = map[string]Color{"Ansi 0 Color": {RedComponent: 1}}
+	profile.Triggers = append(profile.Triggers, Trigger{Regex: name, Parameter: command})
+
+	data, err := Profiles{Profiles: []Profile{*profile}}.JSON()
+	if err != nil {
+		t.Log(err)
+		return false
+	}
+
+	var decoded Profiles
+	err = json.Unmarshal(data, &decoded)
+	if err != nil {
+		t.Log(err)
+		return false
+	}
+
+	got := decoded.Profiles[0]
+	trigger := got.Triggers[len(got.Triggers)-1]
+
+	return got.Name == profile.Name &&
+		got.GUID == profile.GUID &&
+		got.Command == command &&
+		trigger.Regex == name &&
+		trigger.Parameter == command &&
+		got.ColorScheme["Ansi 0 Color"].RedComponent == 1
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	var cases = []string{
+		`prod "eu"`,
+		`C:\Users\prod`,
+		`\u0026 already escaped`,
+		`a && b > /dev/null 2>&1 < in`,
+		"<script>alert('x')</script>",
+		"プロダクション 🚀",
+		"tab\tnew\nline",
+		"\u2028\u2029",
+	}
+
+	for _, name := range cases {
+		assert.True(t, roundTrip(t, name, name), name)
+	}
+
+	err := quick.Check(func(name, command string) bool {
+		return roundTrip(t, name, command)
+	}, nil)
+	assert.Nil(t, err)
+}
+
+func TestJSONEscapes(t *testing.T) {
+	command := `/usr/bin/env FOO='a&b' bash -c "x > y && echo <done>" \u0026`
+
+	profile := NewProfile("prod", map[string]string{"Command": command})
+	profile.ColorScheme = map[string]Color{"Ansi 0 Color": {}}
+
+	data, err := Profiles{Profiles: []Profile{*profile}}.JSON()
+	assert.Nil(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, `FOO='a&b' bash -c \"x > y && echo <done>\" \\u0026`)
+	assert.Equal(t, 1, strings.Count(out, `u0026`), "only the literal \\u0026 of the command")
+	assert.False(t, strings.HasSuffix(out, "\n"))
+}
@@ -0,0 +1,107 @@
+package iterm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// Close policies of the sessions once their command or shell exits.
+const (
+	// CloseAlways closes the session, the iTerm default.
+	CloseAlways = "always"
+	// CloseNever keeps the session open, to read the output of the command.
+	CloseNever = "never"
+	// CloseOnFailure starts a login shell once the command fails, ie a
+	// login that needs the user to read its error.
+	CloseOnFailure = "on-failure"
+)
+
+const (
+	onFailurePrefix = "bash -c '"
+	onFailureSuffix = ` || exec "$SHELL" -l'`
+)
+
+// Launch is how a profile starts its session, either a custom command or a
+// login shell, with the initial text sent once it starts, and what happens
+// once it exits.
+type Launch struct {
+	Command     string
+	InitialText string
+	Close       string
+}
+
+// Launch returns the launch spec of the profile.
+func (p *Profile) Launch() Launch {
+	var ret = Launch{
+		Command:     p.Command,
+		InitialText: p.InitialText,
+		Close:       CloseAlways,
+	}
+
+	if strings.HasPrefix(ret.Command, onFailurePrefix) && strings.HasSuffix(ret.Command, onFailureSuffix) {
+		command := strings.TrimSuffix(strings.TrimPrefix(ret.Command, onFailurePrefix), onFailureSuffix)
+		ret.Command = strings.ReplaceAll(command, `'\''`, "'")
+		ret.Close = CloseOnFailure
+	}
+
+	if p.CloseSessionsOnEnd != nil && !*p.CloseSessionsOnEnd {
+		ret.Close = CloseNever
+	}
+
+	return ret
+}
+
+// SetLaunch updates the profile keys of the launch spec. Unknown close
+// policies are ignored.
+func (p *Profile) SetLaunch(l Launch) {
+	p.Command = l.Command
+	p.CustomCommand = ""
+	if l.Command != "" {
+		p.CustomCommand = "Yes"
+	}
+
+	p.InitialText = l.InitialText
+
+	switch l.Close {
+	case "":
+	case CloseAlways:
+		p.CloseSessionsOnEnd = nil
+	case CloseOnFailure:
+		p.CloseSessionsOnEnd = nil
+		if l.Command != "" {
+			p.Command = fmt.Sprintf("%s%s%s", onFailurePrefix, strings.ReplaceAll(l.Command, "'", `'\''`), onFailureSuffix)
+		}
+	case CloseNever:
+		keep := false
+		p.CloseSessionsOnEnd = &keep
+	default:
+		log.WithFields(log.Fields{
+			"profile": p.Name,
+			"close":   l.Close,
+		}).Warn("Unknown close policy")
+	}
+}
+
+// UpdateClose sets the close policy of the profiles, from a map keyed by
+// profile names, or germ sources, or their globs. Profile names win.
+func (p *Profiles) UpdateClose(rules map[string]string, source func(*Profile) string) {
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+
+		policy, found := config.Lookup(rules, profile.Name)
+		if !found {
+			policy, found = config.Lookup(rules, source(profile))
+		}
+
+		if !found {
+			continue
+		}
+
+		launch := profile.Launch()
+		launch.Close = policy
+		profile.SetLaunch(launch)
+	}
+}
@@ -0,0 +1,70 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLaunch(t *testing.T) {
+	var cases = []struct {
+		name   string
+		config map[string]string
+		exp    Launch
+	}{
+		{
+			name:   "login shell",
+			config: map[string]string{},
+			exp:    Launch{Close: CloseAlways},
+		},
+		{
+			name:   "command with initial text",
+			config: map[string]string{"Command": "aws ssm start-session", "InitialText": "sudo -i"},
+			exp:    Launch{Command: "aws ssm start-session", InitialText: "sudo -i", Close: CloseAlways},
+		},
+		{
+			name:   "kept open",
+			config: map[string]string{"Command": "kubectl logs -f app", "Close": CloseNever},
+			exp:    Launch{Command: "kubectl logs -f app", Close: CloseNever},
+		},
+		{
+			name:   "shell on failure",
+			config: map[string]string{"Command": "bash -c 'aws sso login'", "Close": CloseOnFailure},
+			exp:    Launch{Command: "bash -c 'aws sso login'", Close: CloseOnFailure},
+		},
+		{
+			name:   "unknown policy",
+			config: map[string]string{"Close": "later"},
+			exp:    Launch{Close: CloseAlways},
+		},
+	}
+
+	for _, test := range cases {
+		profile := NewProfile("test", test.config)
+		assert.Equal(t, test.exp, profile.Launch(), test.name)
+		assert.Equal(t, test.exp.Command != "", profile.CustomCommand == "Yes", test.name)
+	}
+}
+
+func TestUpdateClose(t *testing.T) {
+	sources := map[string]string{"logs": "k8s", "bastion": "tfstate", "shell": ""}
+
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("logs", map[string]string{"Command": "kubectl logs -f app"}),
+			*NewProfile("bastion", map[string]string{"Command": "ssh bastion", "Close": CloseNever}),
+			*NewProfile("shell", map[string]string{}),
+		},
+	}
+
+	prof.UpdateClose(map[string]string{"k8s": CloseNever, "tf*": CloseAlways, "bastion": CloseOnFailure}, func(p *Profile) string { return sources[p.Name] })
+
+	var policies []string
+	for _, profile := range prof.Profiles {
+		policies = append(policies, profile.Launch().Close)
+	}
+
+	assert.Equal(t, []string{CloseNever, CloseOnFailure, CloseAlways}, policies)
+	assert.Equal(t, `bash -c 'ssh bastion || exec "$SHELL" -l'`, prof.Profiles[1].Command)
+	assert.Equal(t, "ssh bastion", prof.Profiles[1].Launch().Command)
+}
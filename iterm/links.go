@@ -0,0 +1,43 @@
+package iterm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LinkRules returns a smart selection rule per regex, opening its URL. The
+// URL can refer to the match with \0 and to its groups with \1 and so on.
+func LinkRules(links map[string]string) []SmartSelectionRule {
+	var regexes []string
+	for regex := range links {
+		regexes = append(regexes, regex)
+	}
+	sort.Strings(regexes)
+
+	var ret []SmartSelectionRule
+	for _, regex := range regexes {
+		ret = append(ret, SmartSelectionRule{
+			Notes:     fmt.Sprintf("germ link %s", regex),
+			Precision: "very_high",
+			Regex:     regex,
+			Actions: []SmartSelectionRuleAction{
+				{
+					Title:     "open webpage",
+					Action:    1,
+					Parameter: links[regex],
+				},
+			},
+		})
+	}
+
+	return ret
+}
+
+// UpdateLinks adds the link rules to every profile.
+func (p *Profiles) UpdateLinks(links map[string]string) {
+	ssr := LinkRules(links)
+
+	for i := range p.Profiles {
+		p.Profiles[i].SmartSelectionRules = append(p.Profiles[i].SmartSelectionRules, ssr...)
+	}
+}
@@ -0,0 +1,41 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLinks(t *testing.T) {
+	var cases = []struct {
+		name  string
+		links map[string]string
+		exp   []string
+	}{
+		{
+			name: "sorted by regex",
+			links: map[string]string{
+				`PROJ-\d+`: `https://jira.example.com/browse/\0`,
+				`INC(\d+)`: `https://status.example.com/incidents/\1`,
+			},
+			exp: []string{`https://status.example.com/incidents/\1`, `https://jira.example.com/browse/\0`},
+		},
+		{
+			name: "no links",
+		},
+	}
+
+	for _, test := range cases {
+		profiles := Profiles{Profiles: []Profile{{Name: "one"}, {Name: "two"}}}
+		profiles.UpdateLinks(test.links)
+
+		for _, profile := range profiles.Profiles {
+			var urls []string
+			for _, rule := range profile.SmartSelectionRules {
+				urls = append(urls, rule.Actions[0].Parameter)
+			}
+
+			assert.Equal(t, test.exp, urls, test.name)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package iterm
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+)
+
+// LoggingStyles are the iTerm session log formats, by name.
+var LoggingStyles = map[string]int64{
+	"raw":       0,
+	"plain":     1,
+	"html":      2,
+	"asciicast": 3,
+}
+
+// DefaultLogDirectory is used by the logging rules without a directory.
+const DefaultLogDirectory = "~/.local/state/germ/sessions/{{ .Profile }}"
+
+// LoggingVariables are the values of the log directory template, along with
+// the {{ date }} function. iTerm does not interpolate the log directory, so
+// the template is rendered once, at generation time, and {{ date }} is the
+// date of the generation. The log files iTerm writes in it are named with the
+// creation time of their session.
+type LoggingVariables struct {
+	Profile string
+	Source  string
+}
+
+// UpdateLogging enables the automatic session logging of the profiles that
+// match the first rule by name or by source, and returns the log directories
+// iTerm expects to exist.
+func (p *Profiles) UpdateLogging(rules []config.Logging, source func(*Profile) string, now time.Time) []string {
+	var ret []string
+	var seen = map[string]bool{}
+
+	funcs := template.FuncMap{
+		"date": func() string { return now.Format("2006-01-02") },
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		vars := LoggingVariables{Profile: profile.Name, Source: source(profile)}
+
+		rule, found := matchLogging(rules, vars)
+		if !found {
+			continue
+		}
+
+		directory := rule.Directory
+		if directory == "" {
+			directory = DefaultLogDirectory
+		}
+
+		var out bytes.Buffer
+		t, err := template.New("directory").Funcs(funcs).Parse(directory)
+		if err == nil {
+			err = t.Execute(&out, vars)
+		}
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile":   profile.Name,
+				"directory": directory,
+				"err":       err,
+			}).Warn("Cannot render log directory")
+			continue
+		}
+
+		expanded, err := homedir.Expand(out.String())
+		if err != nil {
+			log.WithFields(log.Fields{
+				"directory": out.String(),
+				"err":       err,
+			}).Warn("Cannot expand log directory")
+			continue
+		}
+
+		profile.AutomaticallyLog = true
+		profile.LogDirectory = expanded
+		profile.LoggingStyle = LoggingStyles[rule.Style]
+
+		if !seen[expanded] {
+			seen[expanded] = true
+			ret = append(ret, expanded)
+		}
+	}
+
+	return ret
+}
+
+func matchLogging(rules []config.Logging, vars LoggingVariables) (config.Logging, bool) {
+	for _, rule := range rules {
+		if _, found := config.MatchKey(rule.Profiles, vars.Profile); found {
+			return rule, true
+		}
+
+		if _, found := config.MatchKey(rule.Sources, vars.Source); found {
+			return rule, true
+		}
+	}
+
+	return config.Logging{}, false
+}
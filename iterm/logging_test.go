@@ -0,0 +1,72 @@
+package iterm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateLogging(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	now := time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC)
+	sources := map[string]string{"bastion": "tfstate", "prod": "aws-config", "dev": "aws-config"}
+
+	var cases = []struct {
+		name  string
+		rules []config.Logging
+		exp   map[string]string
+		style int64
+	}{
+		{
+			name: "by source with a date",
+			rules: []config.Logging{
+				{Sources: []string{"tfstate"}, Directory: "/logs/{{ .Source }}/{{ .Profile }}/{{ date }}", Style: "plain"},
+			},
+			exp:   map[string]string{"bastion": "/logs/tfstate/bastion/2021-03-04"},
+			style: 1,
+		},
+		{
+			name: "by name with the default directory",
+			rules: []config.Logging{
+				{Profiles: []string{"prod"}},
+			},
+			exp: map[string]string{"prod": filepath.Join(home, ".local/state/germ/sessions/prod")},
+		},
+		{
+			name: "first rule wins",
+			rules: []config.Logging{
+				{Profiles: []string{"prod"}, Directory: "/prod"},
+				{Sources: []string{"aws-*"}, Directory: "/aws"},
+			},
+			exp: map[string]string{"prod": "/prod", "dev": "/aws"},
+		},
+		{
+			name: "invalid template",
+			rules: []config.Logging{
+				{Sources: []string{"*"}, Directory: "/logs/{{ .Missing }}"},
+			},
+			exp: map[string]string{},
+		},
+	}
+
+	for _, test := range cases {
+		profiles := Profiles{Profiles: []Profile{{Name: "bastion"}, {Name: "prod"}, {Name: "dev"}}}
+		dirs := profiles.UpdateLogging(test.rules, func(p *Profile) string { return sources[p.Name] }, now)
+
+		var logged = map[string]string{}
+		for _, profile := range profiles.Profiles {
+			assert.Equal(t, profile.AutomaticallyLog, profile.LogDirectory != "", test.name)
+			if profile.AutomaticallyLog {
+				logged[profile.Name] = profile.LogDirectory
+				assert.Equal(t, test.style, profile.LoggingStyle, test.name)
+			}
+		}
+
+		assert.Equal(t, test.exp, logged, test.name)
+		assert.Equal(t, len(test.exp), len(dirs), test.name)
+	}
+}
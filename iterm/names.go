@@ -0,0 +1,93 @@
+package iterm
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+var accountID = regexp.MustCompile(`^\d{12}$`)
+
+// AccountID returns the AWS account ID from the tags of the profile.
+func (p *Profile) AccountID() string {
+	if id, found := p.FindTag("account"); found {
+		return id
+	}
+
+	for _, tag := range p.Tags {
+		if accountID.MatchString(tag) {
+			return tag
+		}
+	}
+
+	return ""
+}
+
+// HierarchicalName returns the name of the profile grouped in folders, ie
+// k8s/cluster/namespace or aws/account/region/name. The aliases map account
+// IDs to friendlier folder names. Profiles that cannot be grouped keep their
+// name.
+func (p *Profile) HierarchicalName(aliases map[string]string) string {
+	if p.HasTag("k8s") {
+		cluster, found := p.FindTag("cluster")
+		if !found {
+			return p.Name
+		}
+
+		namespace, _ := p.FindTag("namespace")
+
+		return strings.TrimSuffix(path.Join("k8s", cluster, namespace), "/")
+	}
+
+	account := p.AccountID()
+	if account == "" {
+		return p.Name
+	}
+
+	if alias, found := aliases[account]; found {
+		account = alias
+	}
+
+	region, _ := p.FindTag("region")
+
+	return path.Join("aws", account, region, p.Name)
+}
+
+// UpdateHierarchicalNames renames the profiles to their hierarchical name, so
+// that iTerm groups them in folders. The GUIDs are kept as they are.
+func (p *Profiles) UpdateHierarchicalNames(aliases map[string]string) {
+	for i := range p.Profiles {
+		p.Profiles[i].Name = p.Profiles[i].HierarchicalName(aliases)
+	}
+}
+
+// Rename renames the profiles, keyed by their GUID, along with their GUID,
+// window title and badge, and the keyboard maps that open them.
+func (p *Profiles) Rename(names map[string]string) {
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+
+		for key, keyMap := range profile.KeyboardMap {
+			if name, found := names[keyMap.Text]; found {
+				keyMap.Text = name
+				profile.KeyboardMap[key] = keyMap
+			}
+		}
+
+		name, found := names[profile.GUID]
+		if !found {
+			continue
+		}
+
+		if strings.HasPrefix(profile.BadgeText, profile.Name) {
+			profile.BadgeText = name + strings.TrimPrefix(profile.BadgeText, profile.Name)
+		}
+
+		if profile.CustomWindowTitle == profile.Name {
+			profile.CustomWindowTitle = name
+		}
+
+		profile.Name = name
+		profile.GUID = name
+	}
+}
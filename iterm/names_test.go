@@ -0,0 +1,86 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHierarchicalName(t *testing.T) {
+	var cases = []struct {
+		name    string
+		profile *Profile
+		aliases map[string]string
+		out     string
+	}{
+		{
+			name:    "k8s cluster and namespace",
+			profile: NewProfile("k8s-prod", map[string]string{"Tags": "k8s,cluster=prod,namespace=monitoring"}),
+			out:     "k8s/prod/monitoring",
+		},
+		{
+			name:    "k8s cluster",
+			profile: NewProfile("k8s-prod", map[string]string{"Tags": "k8s,cluster=prod"}),
+			out:     "k8s/prod",
+		},
+		{
+			name: "aws region profile",
+			profile: NewProfile("config-prod-eu-west-1", map[string]string{
+				"sso_account_id": "123456789012",
+				"Tags":           "region=eu-west-1",
+			}),
+			out: "aws/123456789012/eu-west-1/config-prod-eu-west-1",
+		},
+		{
+			name: "aws profile with alias",
+			profile: NewProfile("config-prod", map[string]string{
+				"role_arn": "arn:aws:iam::123456789012:role/admin",
+			}),
+			aliases: map[string]string{"123456789012": "prod"},
+			out:     "aws/prod/config-prod",
+		},
+		{
+			name:    "unknown profile",
+			profile: NewProfile("default-profile", map[string]string{}),
+			out:     "default-profile",
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.out, test.profile.HierarchicalName(test.aliases), test.name)
+		})
+	}
+}
+
+func TestUpdateHierarchicalNames(t *testing.T) {
+	profiles := Profiles{
+		Profiles: []Profile{
+			*NewProfile("k8s-prod", map[string]string{"Tags": "k8s,cluster=prod"}),
+		},
+	}
+
+	profiles.UpdateHierarchicalNames(nil)
+
+	assert.Equal(t, "k8s/prod", profiles.Profiles[0].Name)
+	assert.Equal(t, "k8s-prod", profiles.Profiles[0].GUID)
+}
+
+func TestRename(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("prod", map[string]string{"BadgeText": "prod\n\\(user.ttl)"}),
+			*NewProfile("login-prod", map[string]string{}),
+			*NewProfile("dev", map[string]string{"source_profile": "prod"}),
+		},
+	}
+	prof.Profiles[2].KeyboardMap["0x61-0x80000"] = KeyboardMap{Action: 28, Text: "login-prod"}
+
+	prof.Rename(map[string]string{"prod": "aws-prod", "login-prod": "aws-login-prod"})
+
+	assert.Equal(t, "aws-prod", prof.Profiles[0].GUID)
+	assert.Equal(t, "aws-prod\n\\(user.ttl)", prof.Profiles[0].BadgeText)
+	assert.Equal(t, "aws-login-prod", prof.Profiles[1].CustomWindowTitle)
+	assert.Equal(t, "aws-login-prod", prof.Profiles[2].KeyboardMap["0x61-0x80000"].Text)
+	assert.Equal(t, "dev", prof.Profiles[2].Name)
+}
@@ -0,0 +1,75 @@
+package iterm
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+)
+
+// NotificationPatterns are the regexes of the common completion messages, by
+// name.
+var NotificationPatterns = map[string][]string{
+	"terraform": {
+		`^(Apply|Destroy) complete! Resources: .*`,
+		`^Error: .*`,
+	},
+	"make": {
+		`^make(\[\d+\])?: \*\*\* .*`,
+	},
+	"pipeline": {
+		`completed with '(success|failure|cancelled)'`,
+		`^Pipeline #\d+ (passed|failed|canceled)`,
+	},
+}
+
+// notificationRegexes expands the names of the NotificationPatterns, the
+// other patterns are regexes.
+func notificationRegexes(patterns []string) ([]string, error) {
+	var ret []string
+	for _, pattern := range patterns {
+		if regexes, found := NotificationPatterns[pattern]; found {
+			ret = append(ret, regexes...)
+			continue
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, errors.Wrapf(err, "invalid notification pattern %s", pattern)
+		}
+		ret = append(ret, pattern)
+	}
+
+	sort.Strings(ret)
+
+	return ret, nil
+}
+
+// UpdateNotifications adds a trigger that posts a notification with the
+// matched line, for every pattern of the rules, to the profiles matching
+// them.
+func (p *Profiles) UpdateNotifications(rules []config.Notification) error {
+	for _, rule := range rules {
+		regexes, err := notificationRegexes(rule.Patterns)
+		if err != nil {
+			return err
+		}
+
+		for i := range p.Profiles {
+			profile := &p.Profiles[i]
+			if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+				continue
+			}
+
+			for _, regex := range regexes {
+				profile.Triggers = append(profile.Triggers, Trigger{
+					Action:    "GrowlTrigger",
+					Parameter: `\0`,
+					Regex:     regex,
+				})
+			}
+		}
+	}
+
+	return nil
+}
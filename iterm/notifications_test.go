@@ -0,0 +1,55 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateNotifications(t *testing.T) {
+	var cases = []struct {
+		name  string
+		rules []config.Notification
+		exp   [][]string
+		err   bool
+	}{
+		{
+			name:  "named patterns",
+			rules: []config.Notification{{Profiles: []string{"tf-*"}, Patterns: []string{"make"}}},
+			exp:   [][]string{{`^make(\[\d+\])?: \*\*\* .*`}, nil},
+		},
+		{
+			name:  "custom regex",
+			rules: []config.Notification{{Profiles: []string{"*"}, Patterns: []string{"^Done in .*"}}},
+			exp:   [][]string{{"^Done in .*"}, {"^Done in .*"}},
+		},
+		{
+			name:  "invalid regex",
+			rules: []config.Notification{{Profiles: []string{"*"}, Patterns: []string{"(oops"}}},
+			exp:   [][]string{nil, nil},
+			err:   true,
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{
+			Profiles: []Profile{
+				{Name: "tf-prod"},
+				{Name: "dev"},
+			},
+		}
+
+		err := prof.UpdateNotifications(test.rules)
+		assert.Equal(t, test.err, err != nil, test.name)
+
+		for i, profile := range prof.Profiles {
+			var regexes []string
+			for _, trigger := range profile.Triggers {
+				assert.Equal(t, "GrowlTrigger", trigger.Action, test.name)
+				regexes = append(regexes, trigger.Regex)
+			}
+			assert.Equal(t, test.exp[i], regexes, test.name)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package iterm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// osDetection prints the ID of the remote OS, for the detection triggers.
+const osDetection = "grep -h '^ID=' /etc/os-release 2>/dev/null"
+
+// remotePackages maps the commands with a not found trigger in remote shells
+// to their apt package, same as Triggers.
+var remotePackages = map[string]string{
+	"git":     "git",
+	"ping":    "iputils-ping",
+	"ssh-add": "openssh-client",
+}
+
+// osFamily is a set of distributions sharing a package manager.
+type osFamily struct {
+	ids     []string
+	install func(string) string
+	// renames are the apt package names that differ in the family.
+	renames map[string]string
+}
+
+var osFamilies = []osFamily{
+	{
+		ids: []string{"debian", "ubuntu"},
+		install: func(pkg string) string {
+			return fmt.Sprintf("apt-get update && apt-get --yes --no-install-recommends install %s", pkg)
+		},
+	},
+	{
+		ids: []string{"almalinux", "amzn", "centos", "fedora", "rhel", "rocky"},
+		install: func(pkg string) string {
+			return fmt.Sprintf("yum install --assumeyes %s", pkg)
+		},
+		renames: map[string]string{
+			"iputils-ping":   "iputils",
+			"openssh-client": "openssh-clients",
+		},
+	},
+	{
+		ids:     []string{"alpine"},
+		install: apk,
+		renames: map[string]string{
+			"iputils-ping": "iputils",
+		},
+	},
+}
+
+// installVariable is the iTerm user variable with the install command of a
+// missing command, ie germInstall_ssh_add.
+func installVariable(command string) string {
+	return fmt.Sprintf("germInstall_%s", strings.ReplaceAll(command, "-", "_"))
+}
+
+// OSTriggers returns the triggers that set the install command of every
+// package in a user variable once the OS ID is printed, and the not found
+// triggers that send them.
+func OSTriggers() []Trigger {
+	var commands []string
+	for command := range remotePackages {
+		commands = append(commands, command)
+	}
+	sort.Strings(commands)
+
+	var ret []Trigger
+	for _, family := range osFamilies {
+		for _, command := range commands {
+			pkg := remotePackages[command]
+			if rename, found := family.renames[pkg]; found {
+				pkg = rename
+			}
+
+			ret = append(ret, Trigger{
+				Action:    "SetUserVariableTrigger",
+				Parameter: fmt.Sprintf("%s=%s", installVariable(command), family.install(pkg)),
+				Regex:     fmt.Sprintf(`^ID="?(%s)"?$`, strings.Join(family.ids, "|")),
+			})
+		}
+	}
+
+	for _, command := range commands {
+		ret = append(ret, Trigger{
+			Action:    "SendTextTrigger",
+			Parameter: fmt.Sprintf(`\(user.%s)`, installVariable(command)),
+			Regex:     notFound(command),
+		})
+	}
+
+	return ret
+}
+
+// UpdateOSTriggers replaces the apt, yum and apk not found triggers of the
+// non local profiles with the OS triggers. The profiles whose command opens
+// a remote session print the OS ID when the session starts, in the others
+// (ie kubectl exec) it is detected once /etc/os-release is printed.
+func (p *Profiles) UpdateOSTriggers(local, session func(*Profile) bool) {
+	triggers := OSTriggers()
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		if local(profile) {
+			continue
+		}
+
+		var kept []Trigger
+		for _, trigger := range profile.Triggers {
+			if !strings.HasPrefix(trigger.Regex, notFoundPrefix) {
+				kept = append(kept, trigger)
+			}
+		}
+
+		profile.Triggers = append(kept, triggers...)
+
+		if !session(profile) {
+			continue
+		}
+
+		profile.AppendInitialText(osDetection)
+	}
+}
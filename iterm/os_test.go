@@ -0,0 +1,97 @@
+package iterm
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOSTriggers(t *testing.T) {
+	var cases = []struct {
+		name string
+		line string
+		exp  []string
+	}{
+		{
+			name: "ubuntu",
+			line: `ID=ubuntu`,
+			exp: []string{
+				"germInstall_git=apt-get update && apt-get --yes --no-install-recommends install git",
+				"germInstall_ping=apt-get update && apt-get --yes --no-install-recommends install iputils-ping",
+				"germInstall_ssh_add=apt-get update && apt-get --yes --no-install-recommends install openssh-client",
+			},
+		},
+		{
+			name: "amazon linux",
+			line: `ID="amzn"`,
+			exp: []string{
+				"germInstall_git=yum install --assumeyes git",
+				"germInstall_ping=yum install --assumeyes iputils",
+				"germInstall_ssh_add=yum install --assumeyes openssh-clients",
+			},
+		},
+		{
+			name: "alpine",
+			line: `ID=alpine`,
+			exp: []string{
+				"germInstall_git=apk add --no-cache git",
+				"germInstall_ping=apk add --no-cache iputils",
+				"germInstall_ssh_add=apk add --no-cache openssh-client",
+			},
+		},
+		{
+			name: "unknown",
+			line: `ID=arch`,
+		},
+	}
+
+	triggers := OSTriggers()
+
+	for _, test := range cases {
+		var parameters []string
+		for _, trigger := range triggers {
+			if trigger.Action != "SetUserVariableTrigger" {
+				continue
+			}
+
+			if regexp.MustCompile(trigger.Regex).MatchString(test.line) {
+				parameters = append(parameters, trigger.Parameter)
+			}
+		}
+
+		assert.Equal(t, test.exp, parameters, test.name)
+	}
+}
+
+func TestUpdateOSTriggers(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("local", map[string]string{}),
+			*NewProfile("k8s", map[string]string{"Command": "/usr/bin/env KUBECONFIG=k8s /usr/bin/login -fp user"}),
+			*NewProfile("ssh", map[string]string{"Command": "ssh host"}),
+			*NewProfile("prod", map[string]string{"Command": "ssh prod"}),
+		},
+	}
+	prof.Profiles[3].InitialText = "read confirm"
+
+	prof.UpdateOSTriggers(
+		func(p *Profile) bool { return p.Name == "local" },
+		func(p *Profile) bool { return p.Name != "k8s" },
+	)
+
+	assert.Equal(t, Triggers(), prof.Profiles[0].Triggers)
+	assert.Equal(t, "", prof.Profiles[1].InitialText)
+	assert.Contains(t, prof.Profiles[1].Triggers, Trigger{
+		Action:    "SendTextTrigger",
+		Parameter: `\(user.germInstall_git)`,
+		Regex:     notFound("git"),
+	})
+	assert.NotContains(t, prof.Profiles[2].Triggers, Trigger{
+		Action:    "SendTextTrigger",
+		Parameter: apt("git"),
+		Regex:     notFound("git"),
+	})
+	assert.Equal(t, osDetection, prof.Profiles[2].InitialText)
+	assert.Equal(t, "read confirm; "+osDetection, prof.Profiles[3].InitialText)
+}
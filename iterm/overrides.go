@@ -0,0 +1,142 @@
+package iterm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+)
+
+// UpdateOverrides applies the overrides, in order, to the profiles whose name
+// or GUID matches their patterns. The GUID is the name before any renaming,
+// ie by the hierarchical names.
+func (p *Profiles) UpdateOverrides(overrides []config.Override) error {
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+
+		for j, override := range overrides {
+			_, byName := config.MatchKey(override.Profiles, profile.Name)
+			_, byGUID := config.MatchKey(override.Profiles, profile.GUID)
+			if !byName && !byGUID {
+				continue
+			}
+
+			err := profile.override(override)
+			if err != nil {
+				return errors.Wrapf(err, "override %d of %s", j, profile.GUID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Profile) override(override config.Override) error {
+	if override.Command != "" {
+		p.Command = override.Command
+		p.CustomCommand = "Yes"
+	}
+
+	for _, trigger := range override.Triggers {
+		p.Triggers = append(p.Triggers, Trigger{
+			Regex:     trigger.Regex,
+			Action:    trigger.Action,
+			Parameter: trigger.Parameter,
+			Partial:   trigger.Partial,
+		})
+	}
+
+	if len(override.Set) == 0 {
+		return nil
+	}
+
+	return p.set(override.Set)
+}
+
+// set replaces the keys of the profile JSON. Keys that are not part of the
+// profile, other than the colors, are an error as they would be dropped.
+func (p *Profile) set(values map[string]interface{}) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var keys map[string]interface{}
+	err = json.Unmarshal(data, &keys)
+	if err != nil {
+		return err
+	}
+
+	known := profileKeys()
+
+	var names []string
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !known[name] && !strings.HasSuffix(name, " Color") {
+			return errors.Errorf("unknown profile key %q", name)
+		}
+
+		keys[name] = jsonValue(values[name])
+	}
+
+	data, err = json.Marshal(keys)
+	if err != nil {
+		return err
+	}
+
+	var patched Profile
+	err = json.Unmarshal(data, &patched)
+	if err != nil {
+		return errors.Wrap(err, "invalid value")
+	}
+
+	*p = patched
+
+	return nil
+}
+
+// profileKeys returns the JSON keys of the profile fields.
+func profileKeys() map[string]bool {
+	var ret = map[string]bool{}
+
+	t := reflect.TypeOf(Profile{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			ret[name] = true
+		}
+	}
+
+	return ret
+}
+
+// jsonValue converts the maps of the yaml values to maps with string keys,
+// which JSON can encode.
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		ret := map[string]interface{}{}
+		for key, item := range v {
+			ret[fmt.Sprintf("%v", key)] = jsonValue(item)
+		}
+
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = jsonValue(item)
+		}
+
+		return ret
+	}
+
+	return value
+}
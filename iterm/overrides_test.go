@@ -0,0 +1,58 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateOverrides(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("prod", map[string]string{"Command": "ssh prod"}),
+			*NewProfile("dev", map[string]string{"Command": "ssh dev"}),
+		},
+	}
+	prof.Profiles[0].Name = "aws/prod"
+
+	err := prof.UpdateOverrides([]config.Override{
+		{
+			Profiles: []string{"prod"},
+			Command:  "ssh -A prod",
+			Triggers: []config.OverrideTrigger{{Regex: "^done$", Action: "BellTrigger"}},
+			Set: map[string]interface{}{
+				"Badge Text":   "PROD",
+				"Ansi 0 Color": map[interface{}]interface{}{"Red Component": 1},
+			},
+		},
+		{
+			Profiles: []string{"aws/*"},
+			Set:      map[string]interface{}{"Unlimited Scrollback": true},
+		},
+	})
+	assert.Nil(t, err)
+
+	profile := prof.Profiles[0]
+	assert.Equal(t, "ssh -A prod", profile.Command)
+	assert.Equal(t, "PROD", profile.BadgeText)
+	assert.Equal(t, 1.0, profile.ColorScheme["Ansi 0 Color"].RedComponent)
+	assert.True(t, profile.UnlimitedScrollback)
+	assert.Equal(t, Trigger{Regex: "^done$", Action: "BellTrigger"}, profile.Triggers[len(profile.Triggers)-1])
+	assert.Equal(t, "aws/prod", profile.Name)
+
+	assert.Equal(t, "ssh dev", prof.Profiles[1].Command)
+
+	var cases = []struct {
+		name string
+		set  map[string]interface{}
+	}{
+		{name: "unknown key", set: map[string]interface{}{"Bagde Text": "typo"}},
+		{name: "invalid value", set: map[string]interface{}{"Tags": "not a list"}},
+	}
+
+	for _, test := range cases {
+		err := prof.UpdateOverrides([]config.Override{{Profiles: []string{"dev"}, Set: test.set}})
+		assert.NotNil(t, err, test.name)
+	}
+}
@@ -0,0 +1,40 @@
+package iterm
+
+import (
+	"regexp"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+)
+
+// UpdatePasswordTriggers adds a PasswordTrigger for every password rule to
+// the profiles matching it.
+func (p *Profiles) UpdatePasswordTriggers(rules []config.Password) error {
+	for _, rule := range rules {
+		if rule.Account == "" {
+			return errors.Errorf("missing account for password regex %s", rule.Regex)
+		}
+
+		if _, err := regexp.Compile(rule.Regex); err != nil {
+			return errors.Wrapf(err, "invalid password regex for account %s", rule.Account)
+		}
+
+		for i := range p.Profiles {
+			profile := &p.Profiles[i]
+			if len(rule.Profiles) > 0 {
+				if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+					continue
+				}
+			}
+
+			profile.Triggers = append(profile.Triggers, Trigger{
+				Action:    "PasswordTrigger",
+				Parameter: rule.Account,
+				Regex:     rule.Regex,
+				Partial:   true,
+			})
+		}
+	}
+
+	return nil
+}
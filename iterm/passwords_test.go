@@ -0,0 +1,84 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatePasswordTriggers(t *testing.T) {
+	var cases = []struct {
+		name  string
+		rules []config.Password
+		exp   []int
+		err   bool
+	}{
+		{
+			name: "no rules",
+			exp:  []int{0, 0},
+		},
+		{
+			name: "all profiles",
+			rules: []config.Password{
+				{Regex: "^Enter VPN password", Account: "vpn"},
+			},
+			exp: []int{1, 1},
+		},
+		{
+			name: "matching profiles",
+			rules: []config.Password{
+				{Regex: `^\[sudo\] password for`, Account: "sudo-web", Profiles: []string{"ssh-web*"}},
+				{Regex: "^Enter VPN password", Account: "vpn"},
+			},
+			exp: []int{1, 2},
+		},
+		{
+			name: "missing account",
+			rules: []config.Password{
+				{Regex: "^Password"},
+			},
+			err: true,
+		},
+		{
+			name: "invalid regex",
+			rules: []config.Password{
+				{Regex: "^(Password", Account: "broken"},
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{
+			Profiles: []Profile{
+				{Name: "ssh-db"},
+				{Name: "ssh-web-1"},
+			},
+		}
+
+		err := prof.UpdatePasswordTriggers(test.rules)
+		if test.err {
+			assert.NotNil(t, err, test.name)
+			continue
+		}
+
+		assert.Nil(t, err, test.name)
+
+		var triggers []int
+		for _, profile := range prof.Profiles {
+			triggers = append(triggers, len(profile.Triggers))
+		}
+
+		assert.Equal(t, test.exp, triggers, test.name)
+	}
+
+	prof := Profiles{Profiles: []Profile{{Name: "ssh-web-1"}}}
+	assert.Nil(t, prof.UpdatePasswordTriggers([]config.Password{{Regex: "^Password", Account: "web"}}))
+	assert.Equal(t, Trigger{
+		Action:    "PasswordTrigger",
+		Parameter: "web",
+		Regex:     "^Password",
+		Partial:   true,
+	}, prof.Profiles[0].Triggers[0])
+}
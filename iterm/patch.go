@@ -0,0 +1,244 @@
+package iterm
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+)
+
+// pointerEscapes decodes the ~1 and ~0 escapes of the JSON Pointer tokens.
+var pointerEscapes = strings.NewReplacer("~1", "/", "~0", "~")
+
+// applyPatch applies the JSON Patch (RFC 6902) operations, in order, to the
+// decoded JSON document and returns the new document. The document is left
+// as it is if an operation fails.
+func applyPatch(doc interface{}, operations []config.PatchOperation) (interface{}, error) {
+	doc, err := normalize(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, operation := range operations {
+		doc, err = applyOperation(doc, operation)
+		if err != nil {
+			return nil, errors.Wrapf(err, "operation %d, %s %s", i, operation.Op, operation.Path)
+		}
+	}
+
+	return doc, nil
+}
+
+func applyOperation(doc interface{}, operation config.PatchOperation) (interface{}, error) {
+	path, err := pointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := normalize(jsonValue(operation.Value))
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add":
+		return pointerAdd(doc, path, value)
+	case "remove":
+		return pointerRemove(doc, path)
+	case "replace":
+		doc, err = pointerRemove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, value)
+	case "test":
+		current, err := pointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(current, value) {
+			return nil, errors.New("test failed")
+		}
+
+		return doc, nil
+	case "move", "copy":
+		from, err := pointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := pointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if operation.Op == "move" {
+			doc, err = pointerRemove(doc, from)
+		} else {
+			value, err = normalize(value)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return pointerAdd(doc, path, value)
+	}
+
+	return nil, errors.Errorf("unknown op %s", operation.Op)
+}
+
+// pointer returns the tokens of the JSON Pointer.
+func pointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.Errorf("invalid pointer %s", path)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i := range tokens {
+		tokens[i] = pointerEscapes.Replace(tokens[i])
+	}
+
+	return tokens, nil
+}
+
+// normalize returns a deep copy of the value with the types of a decoded
+// JSON document, ie float64 numbers, so that the values can be compared.
+func normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret interface{}
+	err = json.Unmarshal(data, &ret)
+
+	return ret, err
+}
+
+func pointerGet(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]interface{}:
+			value, found := container[token]
+			if !found {
+				return nil, errors.Errorf("missing key %s", token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+			doc = container[i]
+		default:
+			return nil, errors.Errorf("cannot index a value with %s", token)
+		}
+	}
+
+	return doc, nil
+}
+
+// pointerUpdate replaces the container of the last token of the path with the
+// result of fn.
+func pointerUpdate(doc interface{}, path []string, fn func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := pointerGet(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+
+	child, err = pointerUpdate(child, path[1:], fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := doc.(type) {
+	case map[string]interface{}:
+		container[path[0]] = child
+	case []interface{}:
+		i, _ := arrayIndex(path[0], len(container))
+		container[i] = child
+	}
+
+	return doc, nil
+}
+
+func pointerAdd(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			c[token] = value
+			return c, nil
+		case []interface{}:
+			if token == "-" {
+				return append(c, value), nil
+			}
+
+			i, err := arrayIndex(token, len(c)+1)
+			if err != nil {
+				return nil, err
+			}
+
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+
+			return c, nil
+		}
+
+		return nil, errors.Errorf("cannot add %s to a value", token)
+	})
+}
+
+func pointerRemove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the document")
+	}
+
+	return pointerUpdate(doc, path, func(container interface{}, token string) (interface{}, error) {
+		switch c := container.(type) {
+		case map[string]interface{}:
+			if _, found := c[token]; !found {
+				return nil, errors.Errorf("missing key %s", token)
+			}
+
+			delete(c, token)
+			return c, nil
+		case []interface{}:
+			i, err := arrayIndex(token, len(c))
+			if err != nil {
+				return nil, err
+			}
+
+			return append(c[:i], c[i+1:]...), nil
+		}
+
+		return nil, errors.Errorf("cannot remove %s from a value", token)
+	})
+}
+
+// arrayIndex returns the array index of the token, lower than max.
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i >= max || (len(token) > 1 && token[0] == '0') {
+		return 0, errors.Errorf("invalid index %s", token)
+	}
+
+	return i, nil
+}
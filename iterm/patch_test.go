@@ -0,0 +1,97 @@
+package iterm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPatch(t *testing.T) {
+	var cases = []struct {
+		name       string
+		doc        string
+		operations []config.PatchOperation
+		exp        string
+		err        bool
+	}{
+		{
+			name:       "add key",
+			doc:        `{"a": 1}`,
+			operations: []config.PatchOperation{{Op: "add", Path: "/b", Value: map[interface{}]interface{}{"c": 2}}},
+			exp:        `{"a": 1, "b": {"c": 2}}`,
+		},
+		{
+			name:       "insert and append",
+			doc:        `{"a": [1, 3]}`,
+			operations: []config.PatchOperation{{Op: "add", Path: "/a/1", Value: 2}, {Op: "add", Path: "/a/-", Value: 4}},
+			exp:        `{"a": [1, 2, 3, 4]}`,
+		},
+		{
+			name:       "remove",
+			doc:        `{"a": [1, 2, 3], "b": 1}`,
+			operations: []config.PatchOperation{{Op: "remove", Path: "/a/1"}, {Op: "remove", Path: "/b"}},
+			exp:        `{"a": [1, 3]}`,
+		},
+		{
+			name:       "replace escaped key",
+			doc:        `{"a/b": {"~c": 1}}`,
+			operations: []config.PatchOperation{{Op: "replace", Path: "/a~1b/~0c", Value: "x"}},
+			exp:        `{"a/b": {"~c": "x"}}`,
+		},
+		{
+			name:       "move and copy",
+			doc:        `{"a": {"b": 1}, "c": []}`,
+			operations: []config.PatchOperation{{Op: "copy", From: "/a/b", Path: "/c/0"}, {Op: "move", From: "/a", Path: "/d"}},
+			exp:        `{"c": [1], "d": {"b": 1}}`,
+		},
+		{
+			name:       "test",
+			doc:        `{"a": 1}`,
+			operations: []config.PatchOperation{{Op: "test", Path: "/a", Value: 1}},
+			exp:        `{"a": 1}`,
+		},
+		{
+			name:       "failed test",
+			doc:        `{"a": 1}`,
+			operations: []config.PatchOperation{{Op: "test", Path: "/a", Value: 2}},
+			err:        true,
+		},
+		{
+			name:       "missing key",
+			doc:        `{"a": 1}`,
+			operations: []config.PatchOperation{{Op: "replace", Path: "/b", Value: 2}},
+			err:        true,
+		},
+		{
+			name:       "out of range",
+			doc:        `{"a": [1]}`,
+			operations: []config.PatchOperation{{Op: "add", Path: "/a/2", Value: 2}},
+			err:        true,
+		},
+		{
+			name:       "invalid pointer",
+			doc:        `{"a": 1}`,
+			operations: []config.PatchOperation{{Op: "remove", Path: "a"}},
+			err:        true,
+		},
+	}
+
+	for _, test := range cases {
+		var doc interface{}
+		assert.Nil(t, json.Unmarshal([]byte(test.doc), &doc), test.name)
+
+		out, err := applyPatch(doc, test.operations)
+		if test.err {
+			assert.NotNil(t, err, test.name)
+			continue
+		}
+
+		assert.Nil(t, err, test.name)
+
+		var exp interface{}
+		assert.Nil(t, json.Unmarshal([]byte(test.exp), &exp), test.name)
+		assert.Equal(t, exp, out, test.name)
+	}
+}
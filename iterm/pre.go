@@ -0,0 +1,119 @@
+package iterm
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// Step is a command run before the command of a profile.
+type Step struct {
+	// Name is shown when the step fails.
+	Name    string
+	Command string
+}
+
+// NewStep returns the step of the config, or false if it is invalid or
+// refers to an unknown VPN.
+func NewStep(step config.Step, vpns []config.VPN) (Step, bool) {
+	switch {
+	case step.VPN != "":
+		for _, vpn := range vpns {
+			if vpn.Name == step.VPN {
+				command := VPNCommand(vpn)
+				return Step{Name: fmt.Sprintf("vpn %s", vpn.Name), Command: command}, command != ""
+			}
+		}
+	case step.AWSLogin != "":
+		return Step{
+			Name:    fmt.Sprintf("aws sso login %s", step.AWSLogin),
+			Command: fmt.Sprintf("aws sts get-caller-identity --profile %[1]s >/dev/null 2>&1 || aws sso login --profile %[1]s", step.AWSLogin),
+		}, true
+	case step.Kubectx != "":
+		return Step{
+			Name:    fmt.Sprintf("kubectx %s", step.Kubectx),
+			Command: kubectxCommand(step.Kubectx),
+		}, true
+	case step.Run != "":
+		return Step{Name: step.Run, Command: step.Run}, true
+	}
+
+	return Step{}, false
+}
+
+// kubectxCommand exports a KUBECONFIG with only the context, so the profile
+// does not switch the current context of the shared kubeconfig.
+func kubectxCommand(context string) string {
+	path := cache.Path(fmt.Sprintf("kubeconfig-%s", strings.ReplaceAll(context, "/", "-")))
+
+	return fmt.Sprintf(
+		"mkdir -p %[1]s && (umask 077 && kubectl config view --minify --flatten --context %[2]s > %[3]s) && export KUBECONFIG=%[3]s",
+		ShellQuote(filepath.Dir(path)), ShellQuote(context), ShellQuote(path),
+	)
+}
+
+// StepsCommand runs the steps in order and then the command. A failed step
+// prints its name and starts a login shell instead, to read the error.
+func StepsCommand(steps []Step, command string) string {
+	var lines []string
+	for _, step := range steps {
+		lines = append(lines, fmt.Sprintf(`{ %s; } || { echo "germ: %s failed" >&2; exec "$SHELL" -l; }`, step.Command, step.Name))
+	}
+	lines = append(lines, fmt.Sprintf("exec %s", command))
+
+	return fmt.Sprintf("bash -c '%s'", strings.ReplaceAll(strings.Join(lines, "; "), "'", `'\''`))
+}
+
+// UpdatePre runs the steps of the matching rules, and brings up the VPNs the
+// profiles need, before their command. Profiles without a command run the
+// steps before a login shell.
+func (p *Profiles) UpdatePre(rules []config.Pre, vpns []config.VPN, shell string) {
+	for _, vpn := range vpns {
+		if len(vpn.Profiles) > 0 {
+			rules = append([]config.Pre{{Profiles: vpn.Profiles, Steps: []config.Step{{VPN: vpn.Name}}}}, rules...)
+		}
+	}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+
+		var steps []Step
+		for _, rule := range rules {
+			if _, found := config.MatchKey(rule.Profiles, profile.Name); !found {
+				continue
+			}
+
+			for _, s := range rule.Steps {
+				step, valid := NewStep(s, vpns)
+				if !valid {
+					log.WithFields(log.Fields{
+						"profile": profile.Name,
+						"step":    fmt.Sprintf("%+v", s),
+					}).Warn("Invalid pre step")
+					continue
+				}
+
+				if s.VPN != "" {
+					profile.Tags = append(profile.Tags, fmt.Sprintf("vpn=%s", s.VPN))
+				}
+				steps = append(steps, step)
+			}
+		}
+
+		if len(steps) == 0 {
+			continue
+		}
+
+		launch := profile.Launch()
+		if profile.CustomCommand != "Yes" || launch.Command == "" {
+			launch.Command = fmt.Sprintf("%s -l", shell)
+		}
+
+		launch.Command = StepsCommand(steps, launch.Command)
+		profile.SetLaunch(launch)
+	}
+}
@@ -0,0 +1,62 @@
+package iterm
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatePre(t *testing.T) {
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", "/cache")
+
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("prod", map[string]string{"Command": "aws ssm start-session --target 'i-0123'"}),
+			*NewProfile("prod-shell", map[string]string{}),
+			*NewProfile("login-prod", map[string]string{"Command": "bash -c 'aws sso login'", "Close": CloseOnFailure}),
+			*NewProfile("dev", map[string]string{"Command": "ssh dev"}),
+		},
+	}
+
+	vpns := []config.VPN{{Name: "exit", Tool: "tailscale", ExitNode: "eu", Profiles: []string{"*prod*"}}}
+	rules := []config.Pre{
+		{
+			Profiles: []string{"prod"},
+			Steps: []config.Step{
+				{AWSLogin: "prod"},
+				{Kubectx: "arn:aws:eks:eu-west-1:123456789012:cluster/prod"},
+				{VPN: "missing"},
+			},
+		},
+		{
+			Profiles: []string{"dev"},
+			Steps:    []config.Step{{Run: "echo 'hi'"}},
+		},
+	}
+
+	prof.UpdatePre(rules, vpns, "/bin/zsh")
+
+	var commands []string
+	for _, profile := range prof.Profiles {
+		commands = append(commands, profile.Launch().Command)
+	}
+
+	vpn := `{ tailscale set --exit-node=eu; } || { echo "germ: vpn exit failed" >&2; exec "$SHELL" -l; }; `
+	assert.Equal(t, []string{
+		`bash -c '` + vpn +
+			`{ aws sts get-caller-identity --profile prod >/dev/null 2>&1 || aws sso login --profile prod; } || { echo "germ: aws sso login prod failed" >&2; exec "$SHELL" -l; }; ` +
+			`{ mkdir -p /cache/germ && (umask 077 && kubectl config view --minify --flatten --context arn:aws:eks:eu-west-1:123456789012:cluster/prod > ` +
+			`/cache/germ/kubeconfig-arn:aws:eks:eu-west-1:123456789012:cluster-prod) && ` +
+			`export KUBECONFIG=/cache/germ/kubeconfig-arn:aws:eks:eu-west-1:123456789012:cluster-prod; } || ` +
+			`{ echo "germ: kubectx arn:aws:eks:eu-west-1:123456789012:cluster/prod failed" >&2; exec "$SHELL" -l; }; ` +
+			`exec aws ssm start-session --target '\''i-0123'\'''`,
+		`bash -c '` + vpn + `exec /bin/zsh -l'`,
+		`bash -c '` + vpn + `exec bash -c '\''aws sso login'\'''`,
+		`bash -c '{ echo '\''hi'\''; } || { echo "germ: echo '\''hi'\'' failed" >&2; exec "$SHELL" -l; }; exec ssh dev'`,
+	}, commands)
+	assert.Equal(t, CloseOnFailure, prof.Profiles[2].Launch().Close)
+	assert.True(t, prof.Profiles[0].HasTag("vpn=exit"))
+}
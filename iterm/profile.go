@@ -1,6 +1,7 @@
 package iterm
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -17,23 +18,150 @@ type Profiles struct {
 }
 
 type Profile struct {
-	AllowTitleSetting   bool                   `json:"Allow Title Setting"`
-	BadgeText           string                 `json:"Badge Text"`
-	Command             string                 `json:"Command"`
-	CustomCommand       string                 `json:"Custom Command"`
-	CustomDirectory     string                 `json:"Custom Directory"`
-	CustomWindowTitle   string                 `json:"Custom Window Title"`
-	FlashingBell        bool                   `json:"Flashing Bell"`
-	GUID                string                 `json:"Guid"`
-	KeyboardMap         map[string]KeyboardMap `json:"Keyboard Map"`
-	Name                string                 `json:"Name"`
-	SilenceBell         bool                   `json:"Silence Bell"`
-	SmartSelectionRules []SmartSelectionRule   `json:"Smart Selection Rules"`
-	Tags                []string               `json:"Tags"`
-	TitleComponents     int64                  `json:"Title Components"`
-	Triggers            []Trigger              `json:"Triggers"`
-	UnlimitedScrollback bool                   `json:"Unlimited Scrollback"`
-	BackgroundColor     Color                  `json:"Background Color"`
+	AllowTitleSetting    bool                   `json:"Allow Title Setting"`
+	BadgeText            string                 `json:"Badge Text"`
+	Command              string                 `json:"Command"`
+	CustomCommand        string                 `json:"Custom Command"`
+	CustomDirectory      string                 `json:"Custom Directory"`
+	CustomWindowTitle    string                 `json:"Custom Window Title"`
+	CustomTabTitle       string                 `json:"Custom Tab Title,omitempty"`
+	WorkingDirectory     string                 `json:"Working Directory,omitempty"`
+	FlashingBell         bool                   `json:"Flashing Bell"`
+	GUID                 string                 `json:"Guid"`
+	KeyboardMap          map[string]KeyboardMap `json:"Keyboard Map"`
+	Name                 string                 `json:"Name"`
+	SilenceBell          bool                   `json:"Silence Bell"`
+	SmartSelectionRules  []SmartSelectionRule   `json:"Smart Selection Rules"`
+	Tags                 []string               `json:"Tags"`
+	TitleComponents      int64                  `json:"Title Components"`
+	Triggers             []Trigger              `json:"Triggers"`
+	UnlimitedScrollback  bool                   `json:"Unlimited Scrollback"`
+	BackgroundColor      Color                  `json:"Background Color"`
+	UseSeparateColors    bool                   `json:"Use Separate Colors for Light and Dark Mode,omitempty"`
+	BackgroundColorDark  *Color                 `json:"Background Color (Dark),omitempty"`
+	BackgroundColorLight *Color                 `json:"Background Color (Light),omitempty"`
+	ForegroundColorDark  *Color                 `json:"Foreground Color (Dark),omitempty"`
+	ForegroundColorLight *Color                 `json:"Foreground Color (Light),omitempty"`
+	SemanticHistory      *SemanticHistory       `json:"Semantic History,omitempty"`
+	InitialText          string                 `json:"Initial Text,omitempty"`
+	CloseSessionsOnEnd   *bool                  `json:"Close Sessions On End,omitempty"`
+	Shortcut             string                 `json:"Shortcut,omitempty"`
+	BoundHosts           []string               `json:"Bound Hosts,omitempty"`
+	AutomaticallyLog     bool                   `json:"Automatically Log,omitempty"`
+	LogDirectory         string                 `json:"Log Directory,omitempty"`
+	LoggingStyle         int64                  `json:"Logging Style,omitempty"`
+	// ColorScheme are extra color keys, ie "Ansi 0 Color", merged into the
+	// profile JSON.
+	ColorScheme map[string]Color `json:"-"`
+	// Extra are the other keys germ does not model, ie from the transforms,
+	// kept as they are in the profile JSON.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type profile Profile
+
+// JSON returns the profiles as indented JSON. The &, < and > of the commands
+// and the triggers are kept as they are, instead of the \u0026 escapes of
+// json.Marshal.
+func (p Profiles) JSON() ([]byte, error) {
+	var out bytes.Buffer
+
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "    ")
+
+	err := enc.Encode(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// marshal is json.Marshal without the HTML escaping.
+func marshal(v interface{}) ([]byte, error) {
+	var out bytes.Buffer
+
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// MarshalJSON merges the color scheme and the extra keys into the profile.
+func (p Profile) MarshalJSON() ([]byte, error) {
+	data, err := marshal(profile(p))
+	if err != nil || (len(p.ColorScheme) == 0 && len(p.Extra) == 0) {
+		return data, err
+	}
+
+	var keys map[string]json.RawMessage
+	err = json.Unmarshal(data, &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.Extra {
+		keys[key] = value
+	}
+
+	for key, color := range p.ColorScheme {
+		keys[key], err = marshal(color)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return marshal(keys)
+}
+
+// UnmarshalJSON reads the color keys that are not part of the profile
+// struct into the color scheme, and the rest of them into the extra keys.
+func (p *Profile) UnmarshalJSON(data []byte) error {
+	err := json.Unmarshal(data, (*profile)(p))
+	if err != nil {
+		return err
+	}
+
+	var keys map[string]json.RawMessage
+	err = json.Unmarshal(data, &keys)
+	if err != nil {
+		return err
+	}
+
+	known := profileKeys()
+
+	for key, value := range keys {
+		if known[key] {
+			continue
+		}
+
+		if !strings.HasSuffix(key, " Color") {
+			if p.Extra == nil {
+				p.Extra = map[string]json.RawMessage{}
+			}
+			p.Extra[key] = value
+			continue
+		}
+
+		var color Color
+		err = json.Unmarshal(value, &color)
+		if err != nil {
+			return err
+		}
+
+		if p.ColorScheme == nil {
+			p.ColorScheme = map[string]Color{}
+		}
+		p.ColorScheme[key] = color
+	}
+
+	return nil
 }
 
 type Color struct {
@@ -127,13 +255,13 @@ func NewProfile(name string, config map[string]string) *Profile {
 		UnlimitedScrollback: true,
 	}
 
-	v, found := config["Command"]
-	if found {
-		prof.Command = v
-		prof.CustomCommand = "Yes"
-	}
+	prof.SetLaunch(Launch{
+		Command:     config["Command"],
+		InitialText: config["InitialText"],
+		Close:       config["Close"],
+	})
 
-	v, found = config["BadgeText"]
+	v, found := config["BadgeText"]
 	if found {
 		prof.BadgeText = v
 	}
@@ -393,7 +521,10 @@ func (p *Profiles) UpdateKeyboardMaps() {
 			continue
 		}
 
-		fmt.Println(fmt.Sprintf("awsProfile: %+v", awsProfile))
+		log.WithFields(log.Fields{
+			"awsProfile": awsProfile,
+			"k8s":        profile.GUID,
+		}).Debug("Updating keyboard map")
 
 		sourceProfile, found := p.FindGUID(awsProfile)
 
@@ -428,26 +559,79 @@ func (p *Profiles) SourceProfiles() []string {
 	return ret
 }
 
+// ProfileTree groups the profile GUIDs by their source profile, for example
+//
+//	{
+//		"root": {"child1", "child2"},
+//		"sso":  {"sso"},
+//	}
+//
+// Profiles without a source profile that are not the source of any other
+// profile (ie SSO profiles) are their own source. Login profiles are not
+// part of the tree.
 func (p *Profiles) ProfileTree() map[string][]string {
 	var ret = map[string][]string{}
 
 	for _, profile := range p.Profiles {
-		for _, tag := range profile.Tags {
-			if strings.HasPrefix(tag, "source-profile=") {
-				parts := strings.Split(tag, "=")
-				if _, ok := ret[parts[1]]; ok != true {
-					ret[parts[1]] = make([]string, 1)
-					ret[parts[1]][0] = profile.GUID
-				} else {
-					ret[parts[1]] = append(ret[parts[1]], profile.GUID)
-				}
-			}
+		if source, found := profile.SourceProfile(); found {
+			ret[source] = append(ret[source], profile.GUID)
+		}
+	}
+
+	for _, profile := range p.Profiles {
+		if strings.HasPrefix(profile.GUID, "login-") {
+			continue
+		}
+
+		if _, found := profile.SourceProfile(); found {
+			continue
+		}
+
+		if _, found := ret[profile.GUID]; !found {
+			ret[profile.GUID] = []string{profile.GUID}
+		}
+	}
+
+	return ret
+}
+
+// SourceProfile returns the source profile of the profile, from its
+// source-profile tag.
+func (p *Profile) SourceProfile() (string, bool) {
+	return p.FindTag("source-profile")
+}
+
+// Orphans returns the GUIDs of the profiles whose source profile cannot be
+// found.
+func (p *Profiles) Orphans() []string {
+	var ret []string
+
+	for _, profile := range p.Profiles {
+		source, found := profile.SourceProfile()
+		if !found {
+			continue
+		}
+
+		if _, found := p.FindGUID(source); !found {
+			ret = append(ret, profile.GUID)
 		}
 	}
 
 	return ret
 }
 
+// LoginProfile returns the login profile of the given source profile.
+func (p *Profiles) LoginProfile(source string) (Profile, error) {
+	guid := fmt.Sprintf("login-%s", source)
+
+	profile, found := p.FindGUID(guid)
+	if !found {
+		return Profile{}, fmt.Errorf("login profile %s not found", guid)
+	}
+
+	return profile, nil
+}
+
 func isProd(name string) bool {
 	if strings.Contains(name, "nonprd") {
 		return false
@@ -467,13 +651,16 @@ func isProd(name string) bool {
 	return false
 }
 
+// prodBackground is the red background of the production profiles.
+var prodBackground = Color{
+	ColorSpace:     "sRGB",
+	RedComponent:   0.217376708984375,
+	AlphaComponent: 1,
+}
+
 func (p *Profile) Colors() {
 	if isProd(p.Name) {
-		p.BackgroundColor.ColorSpace = "sRGB"
-		p.BackgroundColor.RedComponent = 0.217376708984375
-		p.BackgroundColor.GreenComponent = 0
-		p.BackgroundColor.BlueComponent = 0
-		p.BackgroundColor.AlphaComponent = 1
+		p.BackgroundColor = prodBackground
 		return
 	}
 	if p.HasTag("k8s") {
@@ -444,9 +444,69 @@ func TestProfileTree(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "profiles without source profile and login profiles",
+			profiles: Profiles{
+				Profiles: []Profile{
+					Profile{
+						GUID: "sso",
+					},
+					Profile{
+						GUID: "login-sso",
+					},
+				},
+			},
+			out: map[string][]string{
+				"sso": {
+					"sso",
+				},
+			},
+		},
 	}
 
 	for _, test := range cases {
 		assert.Equal(t, test.profiles.ProfileTree(), test.out, test.name)
 	}
 }
+
+func TestOrphans(t *testing.T) {
+	profiles := Profiles{
+		Profiles: []Profile{
+			{
+				GUID: "parent",
+			},
+			{
+				GUID: "child",
+				Tags: []string{
+					"source-profile=parent",
+				},
+			},
+			{
+				GUID: "orphan",
+				Tags: []string{
+					"source-profile=missing",
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"orphan"}, profiles.Orphans())
+}
+
+func TestLoginProfile(t *testing.T) {
+	profiles := Profiles{
+		Profiles: []Profile{
+			{
+				GUID:    "login-parent",
+				Command: "login",
+			},
+		},
+	}
+
+	login, err := profiles.LoginProfile("parent")
+	assert.Nil(t, err)
+	assert.Equal(t, "login", login.Command)
+
+	_, err = profiles.LoginProfile("missing")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,97 @@
+package iterm
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// DefaultMaxNameLength is the length of the sanitized names, unless
+// configured.
+const DefaultMaxNameLength = 64
+
+var (
+	// nameSeparators break the iTerm folders and the shell completion.
+	nameSeparators = regexp.MustCompile(`[/:\s]+`)
+	repeatedDashes = regexp.MustCompile(`-{2,}`)
+)
+
+// SanitizeName replaces the separators of the name with dashes, drops the
+// control characters and, unless allowed, the emoji and symbols, applies the
+// replacements and truncates the result.
+func SanitizeName(name string, s *config.Sanitize) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return -1
+		case !s.Unicode && (unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) || unicode.In(r, unicode.Variation_Selector, unicode.Join_Control)):
+			return -1
+		}
+
+		return r
+	}, name)
+
+	name = nameSeparators.ReplaceAllString(strings.TrimSpace(name), "-")
+
+	var regexes []string
+	for regex := range s.Replace {
+		regexes = append(regexes, regex)
+	}
+	sort.Strings(regexes)
+
+	for _, regex := range regexes {
+		re, err := regexp.Compile(regex)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"regex": regex,
+				"err":   err,
+			}).Warn("Invalid name replacement")
+			continue
+		}
+
+		name = re.ReplaceAllString(name, s.Replace[regex])
+	}
+
+	name = strings.Trim(repeatedDashes.ReplaceAllString(name, "-"), "-")
+
+	max := s.MaxLength
+	if max <= 0 {
+		max = DefaultMaxNameLength
+	}
+
+	if runes := []rune(name); len(runes) > max {
+		name = strings.TrimRight(string(runes[:max]), "-")
+	}
+
+	return name
+}
+
+// Sanitize renames the profiles to their sanitized name. Profiles that end
+// up with the same name get a -2, -3 and so on suffix.
+func (p *Profiles) Sanitize(s *config.Sanitize) {
+	if s == nil {
+		return
+	}
+
+	var renames = map[string]string{}
+	var taken = map[string]bool{}
+
+	for _, profile := range p.Profiles {
+		name := SanitizeName(profile.Name, s)
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s-%d", SanitizeName(profile.Name, s), i)
+		}
+		taken[name] = true
+
+		if name != profile.Name {
+			renames[profile.GUID] = name
+		}
+	}
+
+	p.Rename(renames)
+}
@@ -0,0 +1,73 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeName(t *testing.T) {
+	var cases = []struct {
+		name     string
+		in       string
+		sanitize config.Sanitize
+		exp      string
+	}{
+		{
+			name: "separators",
+			in:   "k8s-arn:aws:eks:eu-west-1:123456789012:cluster/prod",
+			exp:  "k8s-arn-aws-eks-eu-west-1-123456789012-cluster-prod",
+		},
+		{
+			name: "emoji and spaces",
+			in:   "tf-🚀 web  server ✨",
+			exp:  "tf-web-server",
+		},
+		{
+			name:     "emoji allowed",
+			in:       "tf-🚀web",
+			sanitize: config.Sanitize{Unicode: true},
+			exp:      "tf-🚀web",
+		},
+		{
+			name: "unicode letters are kept",
+			in:   "tf-διακομιστής",
+			exp:  "tf-διακομιστής",
+		},
+		{
+			name:     "max length",
+			in:       "tf-a-very-long-name",
+			sanitize: config.Sanitize{MaxLength: 10},
+			exp:      "tf-a-very",
+		},
+		{
+			name:     "replacements",
+			in:       "tf-production-web",
+			sanitize: config.Sanitize{Replace: map[string]string{"production": "prod"}},
+			exp:      "tf-prod-web",
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, SanitizeName(test.in, &test.sanitize), test.name)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("tf-web/1", map[string]string{}),
+			*NewProfile("tf-web:1", map[string]string{}),
+			*NewProfile("tf-db", map[string]string{}),
+		},
+	}
+
+	prof.Sanitize(&config.Sanitize{})
+
+	var names []string
+	for _, profile := range prof.Profiles {
+		names = append(names, profile.GUID)
+	}
+	assert.Equal(t, []string{"tf-web-1", "tf-web-1-2", "tf-db"}, names)
+}
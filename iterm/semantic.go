@@ -0,0 +1,84 @@
+package iterm
+
+import (
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// SemanticHistory is what iTerm does when a file or URL is cmd+clicked.
+type SemanticHistory struct {
+	Action string `json:"action"`
+	Editor string `json:"editor,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+var semanticActions = map[string]bool{
+	"best editor": true,
+	"editor":      true,
+	"url":         true,
+	"command":     true,
+	"raw command": true,
+	"coprocess":   true,
+}
+
+// semanticEditors are the bundle IDs of the editors iTerm knows about.
+var semanticEditors = map[string]string{
+	"atom":     "com.github.atom",
+	"bbedit":   "com.barebones.bbedit",
+	"emacs":    "org.gnu.Emacs",
+	"intellij": "com.jetbrains.intellij",
+	"macvim":   "org.vim.MacVim",
+	"sublime":  "com.sublimetext.3",
+	"textmate": "com.macromates.textmate",
+	"vscode":   "com.microsoft.VSCode",
+}
+
+// NewSemanticHistory validates the config settings and resolves the editor
+// names to bundle IDs.
+func NewSemanticHistory(cfg config.SemanticHistory) (SemanticHistory, bool) {
+	if !semanticActions[cfg.Action] {
+		return SemanticHistory{}, false
+	}
+
+	editor := cfg.Editor
+	if id, found := semanticEditors[editor]; found {
+		editor = id
+	}
+
+	if cfg.Action == "editor" && editor == "" {
+		return SemanticHistory{}, false
+	}
+
+	return SemanticHistory{
+		Action: cfg.Action,
+		Editor: editor,
+		Text:   cfg.Text,
+	}, true
+}
+
+// UpdateSemanticHistory sets the semantic history of the profiles that match
+// the config.
+func (p *Profiles) UpdateSemanticHistory(rules map[string]config.SemanticHistory) {
+	var patterns []string
+	for pattern := range rules {
+		patterns = append(patterns, pattern)
+	}
+
+	for i := range p.Profiles {
+		pattern, found := config.MatchKey(patterns, p.Profiles[i].Name)
+		if !found {
+			continue
+		}
+
+		history, valid := NewSemanticHistory(rules[pattern])
+		if !valid {
+			log.WithFields(log.Fields{
+				"pattern": pattern,
+				"action":  rules[pattern].Action,
+				"editor":  rules[pattern].Editor,
+			}).Fatal("Invalid semantic history")
+		}
+
+		p.Profiles[i].SemanticHistory = &history
+	}
+}
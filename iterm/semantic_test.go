@@ -0,0 +1,93 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSemanticHistory(t *testing.T) {
+	var cases = []struct {
+		name    string
+		cfg     config.SemanticHistory
+		history SemanticHistory
+		valid   bool
+	}{
+		{
+			name: "known editor",
+			cfg: config.SemanticHistory{
+				Action: "editor",
+				Editor: "vscode",
+			},
+			history: SemanticHistory{
+				Action: "editor",
+				Editor: "com.microsoft.VSCode",
+			},
+			valid: true,
+		},
+		{
+			name: "bundle id",
+			cfg: config.SemanticHistory{
+				Action: "editor",
+				Editor: "com.example.Editor",
+			},
+			history: SemanticHistory{
+				Action: "editor",
+				Editor: "com.example.Editor",
+			},
+			valid: true,
+		},
+		{
+			name: "command",
+			cfg: config.SemanticHistory{
+				Action: "raw command",
+				Text:   `vim \1 +\2`,
+			},
+			history: SemanticHistory{
+				Action: "raw command",
+				Text:   `vim \1 +\2`,
+			},
+			valid: true,
+		},
+		{
+			name: "editor without editor",
+			cfg: config.SemanticHistory{
+				Action: "editor",
+			},
+		},
+		{
+			name: "unknown action",
+			cfg: config.SemanticHistory{
+				Action: "open",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			history, valid := NewSemanticHistory(test.cfg)
+			assert.Equal(t, test.valid, valid, test.name)
+			assert.Equal(t, test.history, history, test.name)
+		})
+	}
+}
+
+func TestUpdateSemanticHistory(t *testing.T) {
+	profiles := Profiles{
+		Profiles: []Profile{
+			*NewProfile("repo-germ", map[string]string{}),
+			*NewProfile("server", map[string]string{}),
+		},
+	}
+
+	profiles.UpdateSemanticHistory(map[string]config.SemanticHistory{
+		"repo-*": {
+			Action: "editor",
+			Editor: "vscode",
+		},
+	})
+
+	assert.Equal(t, "com.microsoft.VSCode", profiles.Profiles[0].SemanticHistory.Editor)
+	assert.Nil(t, profiles.Profiles[1].SemanticHistory)
+}
@@ -0,0 +1,44 @@
+package iterm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var shellSafe = regexp.MustCompile(`^[\w@%+=:,./-]*$`)
+
+// ShellQuote quotes the value for the shell, if it needs it.
+func ShellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
+
+// ShellEnv returns the GERM_ variables describing the context of the
+// profile, for prompts and tmux status lines.
+func (p *Profile) ShellEnv() []string {
+	vars := p.TriggerVariables()
+	ret := []string{fmt.Sprintf("GERM_PROFILE=%s", ShellQuote(p.Name))}
+
+	if account, found := p.FindTag("account"); found {
+		ret = append(ret, fmt.Sprintf("GERM_ACCOUNT=%s", ShellQuote(account)))
+	}
+
+	if vars.Region != "" {
+		ret = append(ret, fmt.Sprintf("GERM_REGION=%s", ShellQuote(vars.Region)))
+	}
+
+	return ret
+}
+
+// UpdateShellEnv exports the GERM_ variables in the sessions of every
+// profile.
+func (p *Profiles) UpdateShellEnv(shell string) {
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		profile.AddEnv(shell, profile.ShellEnv()...)
+	}
+}
@@ -0,0 +1,43 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShellEnv(t *testing.T) {
+	var cases = []struct {
+		name    string
+		profile *Profile
+		exp     []string
+	}{
+		{
+			name:    "aws profile",
+			profile: NewProfile("prod", map[string]string{"Tags": "account=123456789012,region=eu-west-1"}),
+			exp:     []string{"GERM_PROFILE=prod", "GERM_ACCOUNT=123456789012", "GERM_REGION=eu-west-1"},
+		},
+		{
+			name:    "quoted name",
+			profile: NewProfile("bob's host", map[string]string{}),
+			exp:     []string{`GERM_PROFILE='bob'\''s host'`},
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, test.profile.ShellEnv(), test.name)
+	}
+}
+
+func TestUpdateShellEnv(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("local", map[string]string{}),
+			*NewProfile("ssh", map[string]string{"Command": "ssh host"}),
+		},
+	}
+
+	prof.UpdateShellEnv("/bin/zsh")
+	assert.Equal(t, "/usr/bin/env GERM_PROFILE=local /bin/zsh -l", prof.Profiles[0].Command)
+	assert.Equal(t, "/usr/bin/env GERM_PROFILE=ssh ssh host", prof.Profiles[1].Command)
+}
@@ -0,0 +1,51 @@
+package iterm
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+)
+
+// UpdateShortcuts sets the ⌃⌘ shortcut letters of the profiles, from a map
+// keyed by profile names (or globs). Letters that would open more than one
+// profile are not assigned and returned as an error.
+func (p *Profiles) UpdateShortcuts(shortcuts map[string]string) error {
+	var patterns []string
+	for pattern := range shortcuts {
+		patterns = append(patterns, pattern)
+	}
+
+	var owners = map[string][]int{}
+	for i := range p.Profiles {
+		pattern, found := config.MatchKey(patterns, p.Profiles[i].Name)
+		if !found {
+			continue
+		}
+
+		letter := strings.ToUpper(shortcuts[pattern])
+		owners[letter] = append(owners[letter], i)
+	}
+
+	var conflicts []string
+	for letter, profiles := range owners {
+		if len(profiles) > 1 {
+			var names []string
+			for _, i := range profiles {
+				names = append(names, p.Profiles[i].Name)
+			}
+			conflicts = append(conflicts, letter+": "+strings.Join(names, ", "))
+			continue
+		}
+
+		p.Profiles[profiles[0]].Shortcut = letter
+	}
+
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return errors.Errorf("shortcuts used by more than one profile, %s", strings.Join(conflicts, "; "))
+	}
+
+	return nil
+}
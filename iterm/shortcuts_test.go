@@ -0,0 +1,45 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateShortcuts(t *testing.T) {
+	var cases = []struct {
+		name      string
+		shortcuts map[string]string
+		exp       []string
+		err       bool
+	}{
+		{
+			name:      "letters per profile",
+			shortcuts: map[string]string{"prod": "p", "dev": "D"},
+			exp:       []string{"P", "", "D"},
+		},
+		{
+			name:      "glob with more than one profile",
+			shortcuts: map[string]string{"prod*": "p", "dev": "d"},
+			exp:       []string{"", "", "D"},
+			err:       true,
+		},
+		{
+			name: "no shortcuts",
+			exp:  []string{"", "", ""},
+		},
+	}
+
+	for _, test := range cases {
+		prof := Profiles{Profiles: []Profile{{Name: "prod"}, {Name: "prod-eu"}, {Name: "dev"}}}
+
+		err := prof.UpdateShortcuts(test.shortcuts)
+		assert.Equal(t, test.err, err != nil, test.name)
+
+		var letters []string
+		for _, profile := range prof.Profiles {
+			letters = append(letters, profile.Shortcut)
+		}
+		assert.Equal(t, test.exp, letters, test.name)
+	}
+}
@@ -0,0 +1,99 @@
+package iterm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/pkg/errors"
+)
+
+// sendTextAction is the keyboard map action that types the text.
+const sendTextAction = 12
+
+// keyModifiers are the iTerm keyboard map modifier flags.
+var keyModifiers = map[string]int{
+	"shift": 0x20000,
+	"ctrl":  0x40000,
+	"opt":   0x80000,
+	"alt":   0x80000,
+	"cmd":   0x100000,
+}
+
+// KeyCode returns the keyboard map key of a shortcut like ctrl+opt+d.
+func KeyCode(key string) (string, error) {
+	parts := strings.Split(strings.ToLower(key), "+")
+	char := parts[len(parts)-1]
+	if len([]rune(char)) != 1 {
+		return "", errors.Errorf("key %s does not end with a single character", key)
+	}
+
+	var flags int
+	for _, modifier := range parts[:len(parts)-1] {
+		flag, found := keyModifiers[modifier]
+		if !found {
+			return "", errors.Errorf("unknown modifier %s in %s", modifier, key)
+		}
+		flags |= flag
+	}
+
+	if flags == 0 {
+		return "", errors.Errorf("key %s needs a modifier", key)
+	}
+
+	return fmt.Sprintf("0x%x-0x%x", []rune(char)[0], flags), nil
+}
+
+// UpdateSnippets binds the snippets to their key in the matching profiles.
+// The text is a template with the trigger variables, ie {{ .AWSProfile }}.
+// Keys that are already bound in a profile are left as they are.
+func (p *Profiles) UpdateSnippets(snippets []config.Snippet) error {
+	for _, snippet := range snippets {
+		code, err := KeyCode(snippet.Key)
+		if err != nil {
+			return errors.Wrapf(err, "snippet %s", snippet.Name)
+		}
+
+		t, err := template.New(snippet.Name).Parse(snippet.Text)
+		if err != nil {
+			return errors.Wrapf(err, "snippet %s", snippet.Name)
+		}
+
+		for i := range p.Profiles {
+			profile := &p.Profiles[i]
+			if _, found := config.MatchKey(snippet.Profiles, profile.Name); !found {
+				continue
+			}
+
+			if existing, found := profile.KeyboardMap[code]; found {
+				log.WithFields(log.Fields{
+					"profile": profile.Name,
+					"snippet": snippet.Name,
+					"key":     snippet.Key,
+					"bound":   existing.Text,
+				}).Warn("Snippet key is already bound")
+				continue
+			}
+
+			var text bytes.Buffer
+			err = t.Execute(&text, profile.TriggerVariables())
+			if err != nil {
+				return errors.Wrapf(err, "snippet %s", snippet.Name)
+			}
+
+			if profile.KeyboardMap == nil {
+				profile.KeyboardMap = map[string]KeyboardMap{}
+			}
+
+			profile.KeyboardMap[code] = KeyboardMap{
+				Action: sendTextAction,
+				Text:   text.String(),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyCode(t *testing.T) {
+	var cases = []struct {
+		key string
+		exp string
+		err bool
+	}{
+		{key: "ctrl+opt+d", exp: "0x64-0xc0000"},
+		{key: "Cmd+Shift+F", exp: "0x66-0x120000"},
+		{key: "d", err: true},
+		{key: "hyper+d", err: true},
+		{key: "ctrl+dd", err: true},
+	}
+
+	for _, test := range cases {
+		code, err := KeyCode(test.key)
+		assert.Equal(t, test.err, err != nil, test.key)
+		assert.Equal(t, test.exp, code, test.key)
+	}
+}
+
+func TestUpdateSnippets(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("k8s-prod", map[string]string{"Tags": "aws-profile=prod"}),
+			*NewProfile("dev", map[string]string{}),
+		},
+	}
+
+	err := prof.UpdateSnippets([]config.Snippet{
+		{Name: "drain", Key: "ctrl+opt+d", Text: "AWS_PROFILE={{ .AWSProfile }} kubectl drain ", Profiles: []string{"k8s-*"}},
+		{Name: "split", Key: "cmd+shift+_", Text: "ignored", Profiles: []string{"*"}},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, KeyboardMap{Action: sendTextAction, Text: "AWS_PROFILE=prod kubectl drain "}, prof.Profiles[0].KeyboardMap["0x64-0xc0000"])
+	assert.NotContains(t, prof.Profiles[1].KeyboardMap, "0x64-0xc0000")
+	assert.Equal(t, 25, int(prof.Profiles[1].KeyboardMap["0x5f-0x120000"].Action), "existing bindings are kept")
+
+	assert.NotNil(t, prof.UpdateSnippets([]config.Snippet{{Name: "bad", Key: "ctrl+opt+x", Text: "{{ .Missing"}}))
+}
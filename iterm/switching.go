@@ -0,0 +1,153 @@
+package iterm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/mhristof/germ/log"
+)
+
+// sshArgFlags are the ssh options that take an argument.
+const sshArgFlags = "BbcDEeFIiJLlmOoPpQRSWw"
+
+// sshCommand is the ssh invocation in the command of a profile.
+type sshCommand struct {
+	// Fields of the command, split on whitespace outside quotes.
+	Fields []string
+	// Index of the ssh executable in the fields.
+	Index  int
+	Target string
+	Port   string
+}
+
+// Host returns the target without the user.
+func (s *sshCommand) Host() string {
+	parts := strings.Split(s.Target, "@")
+	return parts[len(parts)-1]
+}
+
+// shellFields splits the command on whitespace, except inside quotes. The
+// fields keep their quotes.
+func shellFields(command string) []string {
+	var ret []string
+	var field strings.Builder
+	var quote rune
+
+	for _, r := range command {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case unicode.IsSpace(r):
+			if field.Len() > 0 {
+				ret = append(ret, field.String())
+				field.Reset()
+			}
+			continue
+		}
+
+		field.WriteRune(r)
+	}
+
+	if field.Len() > 0 {
+		ret = append(ret, field.String())
+	}
+
+	return ret
+}
+
+// parseSSH finds the ssh invocation of the command, if any.
+func parseSSH(command string) (*sshCommand, bool) {
+	fields := shellFields(command)
+
+	for i, field := range fields {
+		if field != "ssh" && !strings.HasSuffix(field, "/ssh") {
+			continue
+		}
+
+		ret := sshCommand{Fields: fields, Index: i, Port: "22"}
+		for j := i + 1; j < len(fields); j++ {
+			arg := fields[j]
+			if !strings.HasPrefix(arg, "-") {
+				ret.Target = arg
+				return &ret, true
+			}
+
+			if len(arg) == 2 && strings.ContainsRune(sshArgFlags, rune(arg[1])) {
+				j++
+				if arg == "-p" && j < len(fields) {
+					ret.Port = fields[j]
+				}
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// SSHTarget returns the user, host and port the ssh command of the profile
+// connects to.
+func (p *Profile) SSHTarget() (user, host, port string, found bool) {
+	if p.CustomCommand != "Yes" {
+		return "", "", "", false
+	}
+
+	ssh, found := parseSSH(p.Command)
+	if !found {
+		return "", "", "", false
+	}
+
+	if i := strings.LastIndex(ssh.Target, "@"); i >= 0 {
+		user = ssh.Target[:i]
+	}
+
+	return user, ssh.Host(), ssh.Port, true
+}
+
+// SwitchingHosts returns the hosts iTerm switches to the profile for, from
+// the ssh command and the private IP of the instance.
+func (p *Profile) SwitchingHosts() []string {
+	var ret []string
+
+	if p.CustomCommand == "Yes" {
+		if ssh, found := parseSSH(p.Command); found {
+			ret = append(ret, ssh.Target)
+		}
+	}
+
+	if ip, found := p.FindTag("ip"); found {
+		ret = append(ret, ip, fmt.Sprintf("ip-%s*", strings.ReplaceAll(ip, ".", "-")))
+	}
+
+	return ret
+}
+
+// UpdateBoundHosts adds the automatic profile switching rules of the ssh and
+// SSM profiles, so iTerm switches a session to the profile when it connects
+// to the host manually. A host bound to more than one profile stays with the
+// first one.
+func (p *Profiles) UpdateBoundHosts() {
+	var owners = map[string]string{}
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+
+		for _, host := range profile.SwitchingHosts() {
+			if owner, found := owners[host]; found {
+				log.WithFields(log.Fields{
+					"host":    host,
+					"profile": profile.Name,
+					"owner":   owner,
+				}).Debug("Host is already bound")
+				continue
+			}
+
+			owners[host] = profile.Name
+			profile.BoundHosts = append(profile.BoundHosts, host)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSSH(t *testing.T) {
+	var cases = []struct {
+		command string
+		target  string
+		host    string
+		port    string
+		found   bool
+	}{
+		{command: "ssh host", target: "host", host: "host", port: "22", found: true},
+		{command: "/usr/bin/env TMOUT=900 ssh -p 2222 -A user@host uptime", target: "user@host", host: "host", port: "2222", found: true},
+		{command: "/usr/bin/ssh -o StrictHostKeyChecking=no host", target: "host", host: "host", port: "22", found: true},
+		{command: `ssh -o ProxyCommand="aws ssm start-session --target %h" ec2-user@i-0abc`, target: "ec2-user@i-0abc", host: "i-0abc", port: "22", found: true},
+		{command: "aws ssm start-session --target i-0abc"},
+	}
+
+	for _, test := range cases {
+		ssh, found := parseSSH(test.command)
+		assert.Equal(t, test.found, found, test.command)
+		if !found {
+			continue
+		}
+
+		assert.Equal(t, test.target, ssh.Target, test.command)
+		assert.Equal(t, test.host, ssh.Host(), test.command)
+		assert.Equal(t, test.port, ssh.Port, test.command)
+	}
+}
+
+func TestUpdateBoundHosts(t *testing.T) {
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("web", map[string]string{"Command": "ssh admin@web"}),
+			*NewProfile("tf-web", map[string]string{"Command": "aws ssm start-session --target i-0abc", "Tags": "ip=10.0.0.1"}),
+			*NewProfile("web-again", map[string]string{"Command": "ssh admin@web"}),
+			*NewProfile("local", map[string]string{}),
+		},
+	}
+
+	prof.UpdateBoundHosts()
+
+	assert.Equal(t, []string{"admin@web"}, prof.Profiles[0].BoundHosts)
+	assert.Equal(t, []string{"10.0.0.1", "ip-10-0-0-1*"}, prof.Profiles[1].BoundHosts)
+	assert.Nil(t, prof.Profiles[2].BoundHosts)
+	assert.Nil(t, prof.Profiles[3].BoundHosts)
+}
@@ -0,0 +1,80 @@
+package iterm
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+// TitleVariables are the values of the title templates.
+type TitleVariables struct {
+	Profile string
+	Source  string
+	Account string
+	Region  string
+	Host    string
+}
+
+// TitleVariables returns the title template values of the profile.
+func (p *Profile) TitleVariables(source string) TitleVariables {
+	vars := TitleVariables{
+		Profile: p.Name,
+		Source:  source,
+		Region:  p.TriggerVariables().Region,
+	}
+
+	vars.Account, _ = p.FindTag("account")
+
+	if host, found := p.FindTag("host"); found {
+		vars.Host = host
+	} else {
+		vars.Host, _ = p.FindTag("instance")
+	}
+
+	return vars
+}
+
+// UpdateTitles sets the tab and window titles of the profiles, from the rule
+// of their source.
+func (p *Profiles) UpdateTitles(rules map[string]config.Title, source func(*Profile) string) {
+	patterns := config.Keys(rules)
+
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		vars := profile.TitleVariables(source(profile))
+
+		pattern, found := config.MatchKey(patterns, vars.Source)
+		if !found {
+			continue
+		}
+		rule := rules[pattern]
+
+		if rule.Allow != nil {
+			profile.AllowTitleSetting = *rule.Allow
+		}
+
+		if rule.Template == "" {
+			continue
+		}
+
+		var out bytes.Buffer
+		t, err := template.New("title").Parse(rule.Template)
+		if err == nil {
+			err = t.Execute(&out, vars)
+		}
+
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile":  profile.Name,
+				"template": rule.Template,
+				"err":      err,
+			}).Warn("Cannot render title")
+			continue
+		}
+
+		profile.CustomWindowTitle = out.String()
+		profile.CustomTabTitle = out.String()
+	}
+}
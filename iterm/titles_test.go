@@ -0,0 +1,37 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateTitles(t *testing.T) {
+	allow := true
+
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("ssm-web", map[string]string{"Tags": "account=123456789012,region=eu-west-1,instance=i-0abc"}),
+			*NewProfile("dev", map[string]string{}),
+			*NewProfile("broken", map[string]string{}),
+		},
+	}
+
+	sources := map[string]string{"ssm-web": "tfstate", "dev": "aws-config", "broken": "consul"}
+	prof.UpdateTitles(map[string]config.Title{
+		"tf*":        {Template: "{{ .Account }} {{ .Region }} {{ .Host }}"},
+		"aws-config": {Allow: &allow},
+		"consul":     {Template: "{{ .Missing }}"},
+	}, func(p *Profile) string { return sources[p.Name] })
+
+	assert.Equal(t, "123456789012 eu-west-1 i-0abc", prof.Profiles[0].CustomTabTitle)
+	assert.Equal(t, "123456789012 eu-west-1 i-0abc", prof.Profiles[0].CustomWindowTitle)
+	assert.False(t, prof.Profiles[0].AllowTitleSetting)
+
+	assert.True(t, prof.Profiles[1].AllowTitleSetting)
+	assert.Equal(t, "dev", prof.Profiles[1].CustomWindowTitle)
+	assert.Equal(t, "", prof.Profiles[1].CustomTabTitle)
+
+	assert.Equal(t, "broken", prof.Profiles[2].CustomWindowTitle)
+}
@@ -0,0 +1,105 @@
+package iterm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// Transform applies the transforms, in order, to the {"Profiles": [...]}
+// document of the profiles. The jq filters are run with jq. The profile keys
+// germ does not model are kept in the extra keys of the profiles, while other
+// keys of the document are an error as they would be dropped. The profiles
+// are left as they are if a transform fails.
+func (p *Profiles) Transform(transforms []config.Transform, jq func(filter string, data []byte) ([]byte, error)) error {
+	if len(transforms) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	var doc interface{}
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return err
+	}
+
+	for i, transform := range transforms {
+		doc, err = applyTransform(doc, transform, jq)
+		if err != nil {
+			return errors.Wrapf(err, "transform %d", i)
+		}
+	}
+
+	if keys, ok := doc.(map[string]interface{}); ok {
+		for key := range keys {
+			if key != "Profiles" {
+				return errors.Errorf("unknown key %q", key)
+			}
+		}
+	}
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var ret Profiles
+	err = json.Unmarshal(data, &ret)
+	if err != nil {
+		return errors.Wrap(err, "invalid profiles")
+	}
+
+	*p = ret
+
+	return nil
+}
+
+func applyTransform(doc interface{}, transform config.Transform, jq func(string, []byte) ([]byte, error)) (interface{}, error) {
+	switch {
+	case transform.JQ != "":
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := jq(transform.JQ, data)
+		if err != nil {
+			return nil, err
+		}
+
+		var ret interface{}
+		err = json.Unmarshal(out, &ret)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid jq output")
+		}
+
+		return ret, nil
+	case transform.PatchFile != "":
+		path, err := homedir.Expand(transform.PatchFile)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var operations []config.PatchOperation
+		err = json.Unmarshal(data, &operations)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid patch %s", path)
+		}
+
+		return applyPatch(doc, operations)
+	}
+
+	return applyPatch(doc, transform.Patch)
+}
@@ -0,0 +1,69 @@
+package iterm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	patchFile := filepath.Join(dir, "patch.json")
+	assert.Nil(t, ioutil.WriteFile(patchFile, []byte(`[{"op": "replace", "path": "/Profiles/1/Badge Text", "value": "DEV"}]`), 0644))
+
+	prof := Profiles{
+		Profiles: []Profile{
+			*NewProfile("prod", map[string]string{}),
+			*NewProfile("dev", map[string]string{}),
+		},
+	}
+
+	var filters []string
+	jq := func(filter string, data []byte) ([]byte, error) {
+		filters = append(filters, filter)
+		return data, nil
+	}
+
+	err = prof.Transform([]config.Transform{
+		{Patch: []config.PatchOperation{{Op: "replace", Path: "/Profiles/0/Name", Value: "production"}}},
+		{PatchFile: patchFile},
+		{JQ: ".Profiles |= map(select(.Name != \"missing\"))"},
+	}, jq)
+	assert.Nil(t, err)
+	assert.Equal(t, "production", prof.Profiles[0].Name)
+	assert.Equal(t, "prod", prof.Profiles[0].GUID)
+	assert.Equal(t, "DEV", prof.Profiles[1].BadgeText)
+	assert.Equal(t, []string{".Profiles |= map(select(.Name != \"missing\"))"}, filters)
+
+	err = prof.Transform([]config.Transform{
+		{Patch: []config.PatchOperation{{Op: "add", Path: "/Profiles/0/Normal Font", Value: "Monaco 12"}}},
+	}, jq)
+	assert.Nil(t, err)
+	data, err := prof.Profiles[0].MarshalJSON()
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"Normal Font":"Monaco 12"`)
+
+	err = prof.Transform([]config.Transform{
+		{Patch: []config.PatchOperation{{Op: "add", Path: "/Version", Value: 1}}},
+	}, jq)
+	assert.NotNil(t, err)
+	assert.Equal(t, "production", prof.Profiles[0].Name)
+
+	failing := func(string, []byte) ([]byte, error) { return nil, errors.New("jq failed") }
+	err = prof.Transform([]config.Transform{
+		{Patch: []config.PatchOperation{{Op: "remove", Path: "/Profiles/0"}}},
+		{JQ: "."},
+	}, failing)
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, len(prof.Profiles), "the profiles are left as they are")
+}
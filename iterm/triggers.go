@@ -1,17 +1,39 @@
 package iterm
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
 	"strings"
 
 	"github.com/mhristof/germ/log"
 	"github.com/mitchellh/go-homedir"
 )
 
+// notFoundPrefix starts the regex of the not found triggers of the remote
+// shells.
+const notFoundPrefix = "^(bash|/bin/sh): "
+
 func notFound(name string) string {
-	return fmt.Sprintf("^(bash|/bin/sh): %s: (command )?not found", name)
+	return fmt.Sprintf("%s%s: (command )?not found", notFoundPrefix, name)
 }
 
+// keyPassphrase answers the passphrase prompt of the ssh key with the iTerm
+// password manager account.
+func keyPassphrase(path, account string) Trigger {
+	return Trigger{
+		Partial:   true,
+		Parameter: account,
+		Regex:     fmt.Sprintf(`^Enter passphrase for (key ')?%s`, path),
+		Action:    "PasswordTrigger",
+	}
+}
+
+// UserTriggers is the file with the user defined triggers.
+var UserTriggers = "~/.germ.triggers.json"
+
 func Triggers() []Trigger {
 	idRsa, err := homedir.Expand("~/.ssh/id_rsa")
 	if err != nil {
@@ -20,13 +42,8 @@ func Triggers() []Trigger {
 		}).Panic("Cannot expand ~/.ssh/id_rsa")
 	}
 
-	return []Trigger{
-		{
-			Partial:   true,
-			Parameter: "id_rsa",
-			Regex:     fmt.Sprintf(`^Enter passphrase for (key ')?%s`, idRsa),
-			Action:    "PasswordTrigger",
-		},
+	return append([]Trigger{
+		keyPassphrase(idRsa, "id_rsa"),
 		{
 			Action:    "PasswordTrigger",
 			Parameter: "macos",
@@ -58,7 +75,69 @@ func Triggers() []Trigger {
 			Parameter: "chmod +x !:0 && !!",
 			Regex:     `^zsh: permission denied: .*`,
 		},
+	}, loadUserTriggers(UserTriggers)...)
+}
+
+func loadUserTriggers(path string) []Trigger {
+	userTriggers, err := homedir.Expand(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot expand path")
+	}
+
+	if _, err := os.Stat(userTriggers); os.IsNotExist(err) {
+		return []Trigger{}
+	}
+
+	bytes, err := ioutil.ReadFile(userTriggers)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"userTriggers": userTriggers,
+			"err":          err,
+		}).Fatal("Cannot read file")
+	}
+
+	var triggers []Trigger
+
+	err = json.Unmarshal(bytes, &triggers)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"userTriggers": userTriggers,
+			"err":          err,
+		}).Fatal("Cannot parse json file")
+	}
+
+	return triggers
+}
+
+// SessionManagerTrigger installs the AWS Session Manager plugin when
+// `aws ssm start-session` cannot find it, with brew on macOS and the deb
+// package on Linux.
+func SessionManagerTrigger() Trigger {
+	return Trigger{
+		Action:    "SendTextTrigger",
+		Parameter: sessionManagerInstall(runtime.GOOS, runtime.GOARCH),
+		Regex:     "^SessionManagerPlugin is not found",
+	}
+}
+
+func sessionManagerInstall(goos, goarch string) string {
+	if goos != "linux" {
+		return "brew install --cask session-manager-plugin"
 	}
+
+	arch := "64bit"
+	if goarch == "arm64" {
+		arch = "arm64"
+	}
+
+	deb := "/tmp/session-manager-plugin.deb"
+
+	return fmt.Sprintf(
+		"curl -fsSL -o %[1]s https://s3.amazonaws.com/session-manager-downloads/plugin/latest/ubuntu_%[2]s/session-manager-plugin.deb && sudo dpkg -i %[1]s",
+		deb, arch,
+	)
 }
 
 func yum(name string) string {
@@ -0,0 +1,39 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionManagerInstall(t *testing.T) {
+	var cases = []struct {
+		name   string
+		goos   string
+		goarch string
+		exp    string
+	}{
+		{
+			name:   "macOS",
+			goos:   "darwin",
+			goarch: "arm64",
+			exp:    "brew install --cask session-manager-plugin",
+		},
+		{
+			name:   "linux",
+			goos:   "linux",
+			goarch: "amd64",
+			exp:    "curl -fsSL -o /tmp/session-manager-plugin.deb https://s3.amazonaws.com/session-manager-downloads/plugin/latest/ubuntu_64bit/session-manager-plugin.deb && sudo dpkg -i /tmp/session-manager-plugin.deb",
+		},
+		{
+			name:   "linux on arm",
+			goos:   "linux",
+			goarch: "arm64",
+			exp:    "curl -fsSL -o /tmp/session-manager-plugin.deb https://s3.amazonaws.com/session-manager-downloads/plugin/latest/ubuntu_arm64/session-manager-plugin.deb && sudo dpkg -i /tmp/session-manager-plugin.deb",
+		},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, sessionManagerInstall(test.goos, test.goarch), test.name)
+	}
+}
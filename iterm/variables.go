@@ -0,0 +1,82 @@
+package iterm
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/mhristof/germ/log"
+)
+
+var (
+	awsProfileEnv = regexp.MustCompile(`AWS_PROFILE=([^\s'"]+)`)
+	awsRegionEnv  = regexp.MustCompile(`AWS_REGION=([^\s'"]+)`)
+)
+
+// TriggerVariables are the values of the templates in the trigger
+// parameters, ie {{ .AWSProfile }}.
+type TriggerVariables struct {
+	Profile    string
+	AWSProfile string
+	Region     string
+}
+
+// TriggerVariables returns the template values of the profile, from its tags
+// or the environment of its command.
+func (p *Profile) TriggerVariables() TriggerVariables {
+	vars := TriggerVariables{Profile: p.Name}
+
+	if v, found := p.FindTag("aws-profile"); found {
+		vars.AWSProfile = v
+	} else if match := awsProfileEnv.FindStringSubmatch(p.Command); match != nil {
+		vars.AWSProfile = match[1]
+	}
+
+	if v, found := p.FindTag("region"); found {
+		vars.Region = v
+	} else if match := awsRegionEnv.FindStringSubmatch(p.Command); match != nil {
+		vars.Region = match[1]
+	}
+
+	return vars
+}
+
+// UpdateTriggerVariables renders the templates in the trigger parameters of
+// the profiles. Invalid templates are left as they are.
+func (p *Profiles) UpdateTriggerVariables() {
+	for i := range p.Profiles {
+		profile := &p.Profiles[i]
+		vars := profile.TriggerVariables()
+
+		for j := range profile.Triggers {
+			trigger := &profile.Triggers[j]
+			if !strings.Contains(trigger.Parameter, "{{") {
+				continue
+			}
+
+			t, err := template.New("parameter").Parse(trigger.Parameter)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"profile":   profile.Name,
+					"parameter": trigger.Parameter,
+					"err":       err,
+				}).Warn("Cannot parse trigger parameter")
+				continue
+			}
+
+			var parameter bytes.Buffer
+			err = t.Execute(&parameter, vars)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"profile":   profile.Name,
+					"parameter": trigger.Parameter,
+					"err":       err,
+				}).Warn("Cannot render trigger parameter")
+				continue
+			}
+
+			trigger.Parameter = parameter.String()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package iterm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateTriggerVariables(t *testing.T) {
+	var cases = []struct {
+		name      string
+		profile   Profile
+		parameter string
+		exp       string
+	}{
+		{
+			name: "environment of the command",
+			profile: Profile{
+				Name:    "prod-eu-west-1",
+				Command: "/usr/bin/env AWS_PROFILE=prod AWS_REGION=eu-west-1 /usr/bin/login -fp user",
+			},
+			parameter: "aws sso login --profile {{ .AWSProfile }} --region {{ .Region }}",
+			exp:       "aws sso login --profile prod --region eu-west-1",
+		},
+		{
+			name: "tags",
+			profile: Profile{
+				Name:    "cfn-bastion-db",
+				Command: "bash -c 'AWS_PROFILE=ignored aws ssm start-session'",
+				Tags:    []string{"aws-profile=prod", "region=us-east-1"},
+			},
+			parameter: "{{ .Profile }} {{ .AWSProfile }} {{ .Region }}",
+			exp:       "cfn-bastion-db prod us-east-1",
+		},
+		{
+			name: "quoted command",
+			profile: Profile{
+				Name:    "login-prod",
+				Command: "bash -c 'AWS_PROFILE=prod'",
+			},
+			parameter: "{{ .AWSProfile }}",
+			exp:       "prod",
+		},
+		{
+			name:      "no template",
+			profile:   Profile{Name: "shell"},
+			parameter: "chmod +x !:0 && !!",
+			exp:       "chmod +x !:0 && !!",
+		},
+		{
+			name:      "invalid template",
+			profile:   Profile{Name: "shell"},
+			parameter: "{{ .Missing",
+			exp:       "{{ .Missing",
+		},
+	}
+
+	for _, test := range cases {
+		test.profile.Triggers = []Trigger{{Action: "SendTextTrigger", Parameter: test.parameter}}
+		prof := Profiles{Profiles: []Profile{test.profile}}
+
+		prof.UpdateTriggerVariables()
+		assert.Equal(t, test.exp, prof.Profiles[0].Triggers[0].Parameter, test.name)
+	}
+}
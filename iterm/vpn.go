@@ -0,0 +1,63 @@
+package iterm
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+)
+
+// VPNCommand returns the shell command that brings the VPN up, or an empty
+// string for unknown tools. Connected VPNs are left as they are, openvpn is
+// tracked with a pid file in the cache as its command line can not tell it
+// apart from the shell that starts it.
+func VPNCommand(vpn config.VPN) string {
+	switch vpn.Tool {
+	case "openvpn":
+		path, err := homedir.Expand(vpn.Config)
+		if err != nil {
+			path = vpn.Config
+		}
+
+		pid := cache.Path(fmt.Sprintf("vpn-%s.pid", vpn.Name))
+
+		return fmt.Sprintf(
+			`ps -p "$(cat %[1]s 2>/dev/null)" >/dev/null 2>&1 || { mkdir -p %[2]s && sudo openvpn --daemon --writepid %[1]s --config %[3]s; }`,
+			ShellQuote(pid), ShellQuote(filepath.Dir(pid)), ShellQuote(path),
+		)
+	case "wireguard":
+		return fmt.Sprintf("sudo wg show %[1]s >/dev/null 2>&1 || sudo wg-quick up %[1]s", vpn.Config)
+	case "tailscale":
+		return fmt.Sprintf("tailscale set --exit-node=%s", vpn.ExitNode)
+	}
+
+	return ""
+}
+
+// VPNProfiles returns a profile per VPN that brings it up and starts a login
+// shell.
+func VPNProfiles(vpns []config.VPN, shell string) []Profile {
+	var ret []Profile
+
+	for _, vpn := range vpns {
+		connect := VPNCommand(vpn)
+		if connect == "" {
+			log.WithFields(log.Fields{
+				"vpn":  vpn.Name,
+				"tool": vpn.Tool,
+			}).Warn("Unknown VPN tool")
+			continue
+		}
+
+		step := Step{Name: fmt.Sprintf("vpn %s", vpn.Name), Command: connect}
+		ret = append(ret, *NewProfile(fmt.Sprintf("vpn-%s", vpn.Name), map[string]string{
+			"Command": StepsCommand([]Step{step}, fmt.Sprintf("%s -l", shell)),
+			"Tags":    fmt.Sprintf("vpn,tool=%s", vpn.Tool),
+		}))
+	}
+
+	return ret
+}
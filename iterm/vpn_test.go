@@ -0,0 +1,73 @@
+package iterm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVPNProfiles(t *testing.T) {
+	profiles := VPNProfiles([]config.VPN{
+		{Name: "office", Tool: "wireguard", Config: "wg0"},
+		{Name: "exit", Tool: "tailscale", ExitNode: "eu-exit"},
+		{Name: "unknown", Tool: "pptp"},
+	}, "/bin/zsh")
+
+	assert.Equal(t, 2, len(profiles))
+	assert.Equal(t, "vpn-office", profiles[0].GUID)
+	assert.Equal(t, ""+
+		`bash -c '{ sudo wg show wg0 >/dev/null 2>&1 || sudo wg-quick up wg0; } || `+
+		`{ echo "germ: vpn office failed" >&2; exec "$SHELL" -l; }; exec /bin/zsh -l'`, profiles[0].Command)
+	assert.Equal(t, ""+
+		`bash -c '{ tailscale set --exit-node=eu-exit; } || `+
+		`{ echo "germ: vpn exit failed" >&2; exec "$SHELL" -l; }; exec /bin/zsh -l'`, profiles[1].Command)
+}
+
+func TestVPNCommandOpenVPN(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "vpn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("XDG_CACHE_HOME", os.Getenv("XDG_CACHE_HOME"))
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	// openvpn records its calls and writes the pid of the test, which
+	// keeps running, as the pid of the daemon.
+	bin := filepath.Join(dir, "bin")
+	started := filepath.Join(dir, "started")
+	assert.Nil(t, os.Mkdir(bin, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(bin, "sudo"), []byte("#!/bin/sh\nexec \"$@\"\n"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(bin, "openvpn"), []byte(fmt.Sprintf(""+
+		"#!/bin/sh\n"+
+		"echo \"$@\" >> %s\n"+
+		"while [ $# -gt 0 ]; do [ \"$1\" = --writepid ] && echo %d > \"$2\"; shift; done\n",
+		started, os.Getpid())), 0755))
+
+	command := StepsCommand([]Step{{Name: "vpn dc", Command: VPNCommand(config.VPN{Name: "dc", Tool: "openvpn", Config: "/vpn/dc.ovpn"})}}, "true")
+
+	for i := 0; i < 2; i++ {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("PATH=%s:%s", bin, os.Getenv("PATH")))
+		out, err := cmd.CombinedOutput()
+		assert.Nil(t, err, string(out))
+	}
+
+	calls, err := ioutil.ReadFile(started)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		fmt.Sprintf("--daemon --writepid %s --config /vpn/dc.ovpn", filepath.Join(dir, "cache", "germ", "vpn-dc.pid")),
+	}, strings.Split(strings.TrimSpace(string(calls)), "\n"), "openvpn is started once")
+}
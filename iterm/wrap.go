@@ -0,0 +1,56 @@
+package iterm
+
+import (
+	"fmt"
+	"strings"
+)
+
+const envPrefix = "/usr/bin/env "
+
+// WrapCommand replaces the command of the profile with the format, where %s
+// is the previous command. Profiles without a custom command wrap a login
+// shell instead.
+func (p *Profile) WrapCommand(format, shell string) {
+	command := p.Command
+	if p.CustomCommand != "Yes" || command == "" {
+		command = fmt.Sprintf("%s -l", shell)
+	}
+
+	p.Command = fmt.Sprintf(format, command)
+	p.CustomCommand = "Yes"
+}
+
+// AppendInitialText adds the text after the initial text of the profile, if
+// any.
+func (p *Profile) AppendInitialText(text string) {
+	if p.InitialText == "" {
+		p.InitialText = text
+		return
+	}
+
+	p.InitialText = fmt.Sprintf("%s; %s", p.InitialText, text)
+}
+
+// PrependInitialText adds the text before the initial text of the profile, if
+// any.
+func (p *Profile) PrependInitialText(text string) {
+	if p.InitialText == "" {
+		p.InitialText = text
+		return
+	}
+
+	p.InitialText = fmt.Sprintf("%s; %s", text, p.InitialText)
+}
+
+// AddEnv sets the variables in the environment of the command of the
+// profile, next to the ones the command already sets with /usr/bin/env.
+func (p *Profile) AddEnv(shell string, vars ...string) {
+	env := strings.Join(vars, " ")
+
+	if p.CustomCommand == "Yes" && strings.HasPrefix(p.Command, envPrefix) {
+		p.Command = fmt.Sprintf("%s%s %s", envPrefix, env, strings.TrimPrefix(p.Command, envPrefix))
+		return
+	}
+
+	p.WrapCommand(fmt.Sprintf("%s%s %%s", envPrefix, strings.ReplaceAll(env, "%", "%%")), shell)
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os/user"
 	"path/filepath"
+	"strings"
 
 	"github.com/mhristof/germ/iterm"
 	"github.com/mhristof/germ/log"
@@ -46,10 +47,74 @@ func (k *KubeConfig) GetCluster(name string) (*KubeConfig, bool) {
 	return &config, found
 }
 
-func Profiles(config string, dry bool) []iterm.Profile {
-	clusters := Load(config)
+// Profiles generates the profiles of the clusters in the kubeconfigs, merged
+// as with a KUBECONFIG path list. The per cluster configs are written next to
+// the first kubeconfig.
+func Profiles(configs []string, dry bool) []iterm.Profile {
+	if len(configs) == 0 {
+		return nil
+	}
+
+	clusters := LoadAll(configs)
+
+	return clusters.Profiles(filepath.Dir(configs[0]), dry)
+}
+
+// LoadAll loads and merges the kubeconfigs.
+func LoadAll(configs []string) *KubeConfig {
+	var ret KubeConfig
+
+	for _, config := range configs {
+		ret.Merge(Load(config))
+	}
+
+	return &ret
+}
+
+// Merge adds the clusters, contexts and users of other that are not already
+// defined. As with kubectl, the first definition of a name wins.
+func (k *KubeConfig) Merge(other *KubeConfig) {
+	if k.APIVersion == "" {
+		k.APIVersion = other.APIVersion
+	}
+
+	if k.Kind == "" {
+		k.Kind = other.Kind
+	}
+
+	if k.CurrentContext == "" {
+		k.CurrentContext = other.CurrentContext
+	}
+
+	for _, cluster := range other.Clusters {
+		if _, found := k.GetCluster(cluster.Name); !found {
+			k.Clusters = append(k.Clusters, cluster)
+		}
+	}
+
+	contexts := map[string]bool{}
+	for _, context := range k.Contexts {
+		contexts[context.Name] = true
+	}
+
+	for _, context := range other.Contexts {
+		if !contexts[context.Name] {
+			k.Contexts = append(k.Contexts, context)
+			contexts[context.Name] = true
+		}
+	}
+
+	users := map[string]bool{}
+	for _, user := range k.Users {
+		users[user.Name] = true
+	}
 
-	return clusters.Profiles(filepath.Dir(config), dry)
+	for _, user := range other.Users {
+		if !users[user.Name] {
+			k.Users = append(k.Users, user)
+			users[user.Name] = true
+		}
+	}
 }
 
 func (k *KubeConfig) Profiles(dest string, dry bool) []iterm.Profile {
@@ -69,6 +134,10 @@ func (k *KubeConfig) Profiles(dest string, dry bool) []iterm.Profile {
 		}
 		profile := this.Profile(path)
 		ret = append(ret, *profile)
+
+		if login := this.LoginProfile(path); login != nil {
+			ret = append(ret, *login)
+		}
 	}
 
 	return ret
@@ -97,6 +166,11 @@ func (k *KubeConfig) Profile(path string) *iterm.Profile {
 	cmd := fmt.Sprintf("/usr/bin/env KUBECONFIG=%s", path)
 
 	name := k.Clusters[0].Name
+	tags["Tags"] += ",cluster=" + name
+	if len(k.Contexts) == 1 && k.Contexts[0].Context.Namespace != "" {
+		tags["Tags"] += ",namespace=" + k.Contexts[0].Context.Namespace
+	}
+
 	awsProfile := k.AWSProfile()
 	if awsProfile != "" {
 		cmd = fmt.Sprintf("%s AWS_PROFILE=%s", cmd, awsProfile)
@@ -117,6 +191,63 @@ func (k *KubeConfig) Profile(path string) *iterm.Profile {
 	return prof
 }
 
+// LoginCommand returns the command that refreshes the credentials of the
+// cluster interactively, for the exec plugins and the oidc/gcp auth
+// providers. Clusters with static credentials have no login command.
+func (k *KubeConfig) LoginCommand() string {
+	if len(k.Users) != 1 {
+		return ""
+	}
+
+	user := k.Users[0].User
+
+	switch {
+	case user.Exec.Command == "gke-gcloud-auth-plugin":
+		return "gcloud auth login"
+	case user.Exec.Command != "":
+		var args []string
+		for _, arg := range append([]string{user.Exec.Command}, user.Exec.Args...) {
+			args = append(args, iterm.ShellQuote(arg))
+		}
+
+		return strings.Join(args, " ")
+	case user.AuthProvider == nil:
+		return ""
+	case user.AuthProvider.Name == "gcp":
+		return "gcloud auth login"
+	case user.AuthProvider.Name == "oidc":
+		return fmt.Sprintf(
+			"kubectl oidc-login get-token --oidc-issuer-url=%s --oidc-client-id=%s",
+			iterm.ShellQuote(user.AuthProvider.Config["idp-issuer-url"]),
+			iterm.ShellQuote(user.AuthProvider.Config["client-id"]),
+		)
+	}
+
+	return ""
+}
+
+// LoginProfile returns the login-k8s-<cluster> profile that runs the login
+// command of the cluster, or nil if it does not need one.
+func (k *KubeConfig) LoginProfile(path string) *iterm.Profile {
+	command := k.LoginCommand()
+	if command == "" {
+		return nil
+	}
+
+	env := []string{fmt.Sprintf("KUBECONFIG=%s", iterm.ShellQuote(path))}
+	for _, item := range k.Users[0].User.Exec.Env {
+		env = append(env, fmt.Sprintf("%s=%s", item.Name, iterm.ShellQuote(item.Value)))
+	}
+
+	name := k.Clusters[0].Name
+
+	return iterm.NewProfile(fmt.Sprintf("login-k8s-%s", name), map[string]string{
+		"Command": fmt.Sprintf("bash -c %s", iterm.ShellQuote(fmt.Sprintf("%s %s", strings.Join(env, " "), command))),
+		"Close":   iterm.CloseOnFailure,
+		"Tags":    fmt.Sprintf("k8s-login,cluster=%s", name),
+	})
+}
+
 func (k *KubeConfig) AWSProfile() string {
 	if len(k.Clusters) != 1 {
 		log.WithFields(log.Fields{
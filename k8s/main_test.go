@@ -57,7 +57,7 @@ func TestProfile(t *testing.T) {
 			},
 			out:     &iterm.Profile{},
 			command: "/usr/bin/env KUBECONFIG=path AWS_PROFILE=profile /usr/bin/login -fp " + getUser(t),
-			tags:    []string{"k8s", "aws-profile=profile"},
+			tags:    []string{"k8s", "cluster=test", "aws-profile=profile"},
 		},
 		{
 			name: "k8s profile without AWS, ie minikube",
@@ -75,10 +75,35 @@ func TestProfile(t *testing.T) {
 			},
 			out:     &iterm.Profile{},
 			command: "/usr/bin/env KUBECONFIG=path /usr/bin/login -fp " + getUser(t),
-			tags:    []string{"k8s"},
+			tags:    []string{"k8s", "cluster=test"},
+		},
+		{
+			name: "k8s profile with a namespace",
+			in: &KubeConfig{
+				Clusters: []Cluster{
+					Cluster{
+						Name: "test",
+					},
+				},
+				Contexts: []Context{
+					Context{
+						Name: "test",
+					},
+				},
+				Users: []User{
+					User{
+						Name: "test",
+					},
+				},
+			},
+			out:     &iterm.Profile{},
+			command: "/usr/bin/env KUBECONFIG=path /usr/bin/login -fp " + getUser(t),
+			tags:    []string{"k8s", "cluster=test", "namespace=monitoring"},
 		},
 	}
 
+	cases[2].in.Contexts[0].Context.Namespace = "monitoring"
+
 	for _, test := range cases {
 		prof := test.in.Profile("path")
 		assert.Equal(t, test.command, prof.Command, test.name)
@@ -209,3 +234,147 @@ func TestLoadAndSplit(t *testing.T) {
 func noTabs(in string) string {
 	return strings.Replace(in, "\t", "  ", -1)
 }
+
+func TestLoadAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfigs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.yml": heredoc.Doc(`
+			apiVersion: v1
+			kind: Config
+			clusters:
+			- cluster:
+			    server: https://a
+			  name: a
+			contexts:
+			- context:
+			    cluster: a
+			    user: a
+			  name: a
+			users:
+			- name: a
+		`),
+		"b.yml": heredoc.Doc(`
+			apiVersion: v1
+			kind: Config
+			clusters:
+			- cluster:
+			    server: https://duplicate
+			  name: a
+			- cluster:
+			    server: https://b
+			  name: b
+			contexts:
+			- context:
+			    cluster: b
+			    user: b
+			  name: b
+			users:
+			- name: b
+		`),
+	}
+
+	var configs []string
+	for _, name := range []string{"a.yml", "b.yml"} {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		configs = append(configs, path)
+	}
+
+	kConfig := LoadAll(configs)
+
+	assert.Equal(t, 2, len(kConfig.Clusters))
+	assert.Equal(t, "https://a", kConfig.Clusters[0].Cluster.Server)
+	assert.Equal(t, "b", kConfig.Clusters[1].Name)
+	assert.Equal(t, 2, len(kConfig.Contexts))
+	assert.Equal(t, 2, len(kConfig.Users))
+	assert.Equal(t, "v1", kConfig.APIVersion)
+
+	profiles := Profiles(configs, true)
+	assert.Equal(t, 2, len(profiles))
+	assert.Equal(t, "k8s-b", profiles[1].GUID)
+}
+
+func TestLoginProfile(t *testing.T) {
+	var cases = []struct {
+		name    string
+		user    UserT
+		command string
+	}{
+		{
+			name: "aws eks exec plugin",
+			user: UserT{
+				Exec: Exec{
+					Command: "aws",
+					Args:    []string{"eks", "get-token", "--cluster-name", "test"},
+					Env:     []Env{{Name: "AWS_PROFILE", Value: "prod"}},
+				},
+			},
+			command: "bash -c 'KUBECONFIG=path AWS_PROFILE=prod aws eks get-token --cluster-name test'",
+		},
+		{
+			name: "gke plugin",
+			user: UserT{
+				Exec: Exec{
+					Command: "gke-gcloud-auth-plugin",
+				},
+			},
+			command: "bash -c 'KUBECONFIG=path gcloud auth login'",
+		},
+		{
+			name: "oidc auth provider",
+			user: UserT{
+				AuthProvider: &AuthProvider{
+					Name: "oidc",
+					Config: map[string]string{
+						"idp-issuer-url": "https://issuer",
+						"client-id":      "germ",
+					},
+				},
+			},
+			command: "bash -c 'KUBECONFIG=path kubectl oidc-login get-token --oidc-issuer-url=https://issuer --oidc-client-id=germ'",
+		},
+		{
+			name: "values that need quoting",
+			user: UserT{
+				Exec: Exec{
+					Command: "get-token",
+					Args:    []string{"--scope", "a b"},
+					Env:     []Env{{Name: "TOKEN_NAME", Value: "my token"}},
+				},
+			},
+			command: `bash -c 'KUBECONFIG=path TOKEN_NAME='\''my token'\'' get-token --scope '\''a b'\'''`,
+		},
+		{
+			name: "client certificates",
+			user: UserT{
+				ClientCertificate: "/path/client.crt",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			kConfig := &KubeConfig{
+				Clusters: []Cluster{{Name: "test"}},
+				Users:    []User{{Name: "test", User: test.user}},
+			}
+
+			profile := kConfig.LoginProfile("path")
+			if test.command == "" {
+				assert.Nil(t, profile, test.name)
+				return
+			}
+
+			assert.Equal(t, "login-k8s-test", profile.GUID, test.name)
+			assert.Equal(t, iterm.Launch{Command: test.command, Close: iterm.CloseOnFailure}, profile.Launch(), test.name)
+			assert.False(t, profile.HasTag("k8s"), test.name)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+)
+
+// PortForwardProfiles generates a kubectl port-forward profile per service of
+// the configured contexts and namespaces.
+func PortForwardProfiles(forwards []config.PortForward) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, forward := range forwards {
+		var services []string
+		for service := range forward.Services {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+
+		for _, service := range services {
+			ret = append(ret, *portForwardProfile(forward, service))
+		}
+	}
+
+	return ret
+}
+
+func portForwardProfile(forward config.PortForward, service string) *iterm.Profile {
+	args := []string{"kubectl", "--context", forward.Context}
+	name := []string{"pf", forward.Context}
+	tags := []string{"k8s-port-forward", fmt.Sprintf("context=%s", forward.Context)}
+
+	if forward.Namespace != "" {
+		args = append(args, "--namespace", forward.Namespace)
+		name = append(name, forward.Namespace)
+		tags = append(tags, fmt.Sprintf("namespace=%s", forward.Namespace))
+	}
+
+	resource := service
+	if !strings.Contains(resource, "/") {
+		resource = fmt.Sprintf("svc/%s", service)
+	}
+
+	args = append(args, "port-forward", resource)
+	args = append(args, forward.Services[service]...)
+	name = append(name, strings.ReplaceAll(service, "/", "-"))
+	tags = append(tags, fmt.Sprintf("service=%s", service))
+
+	return iterm.NewProfile(strings.Join(name, "-"), map[string]string{
+		"Command": strings.Join(args, " "),
+		"Tags":    strings.Join(tags, ","),
+	})
+}
@@ -0,0 +1,37 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortForwardProfiles(t *testing.T) {
+	profiles := PortForwardProfiles([]config.PortForward{
+		{
+			Context:   "prod",
+			Namespace: "shop",
+			Services: map[string][]string{
+				"web":            {"8080:80"},
+				"deploy/billing": {"9000", "9090:90"},
+			},
+		},
+		{
+			Context:  "dev",
+			Services: map[string][]string{"grafana": {"3000"}},
+		},
+	})
+
+	var got [][]string
+	for _, profile := range profiles {
+		got = append(got, []string{profile.GUID, profile.Command})
+	}
+
+	assert.Equal(t, [][]string{
+		{"pf-prod-shop-deploy-billing", "kubectl --context prod --namespace shop port-forward deploy/billing 9000 9090:90"},
+		{"pf-prod-shop-web", "kubectl --context prod --namespace shop port-forward svc/web 8080:80"},
+		{"pf-dev-grafana", "kubectl --context dev port-forward svc/grafana 3000"},
+	}, got)
+	assert.True(t, profiles[1].HasTag("service=web"))
+}
@@ -50,11 +50,17 @@ type User struct {
 }
 
 type UserT struct {
-	ClientCertificate     string `yaml:"client-certificate,omitempty"`
-	ClientCertificateData string `yaml:"client-certificate-data,omitempty"`
-	ClientKey             string `yaml:"client-key,omitempty"`
-	ClientKeyData         string `yaml:"client-key-data,omitempty"`
-	Exec                  Exec   `yaml:"exec,omitempty"`
+	ClientCertificate     string        `yaml:"client-certificate,omitempty"`
+	ClientCertificateData string        `yaml:"client-certificate-data,omitempty"`
+	ClientKey             string        `yaml:"client-key,omitempty"`
+	ClientKeyData         string        `yaml:"client-key-data,omitempty"`
+	Exec                  Exec          `yaml:"exec,omitempty"`
+	AuthProvider          *AuthProvider `yaml:"auth-provider,omitempty"`
+}
+
+type AuthProvider struct {
+	Name   string            `yaml:"name"`
+	Config map[string]string `yaml:"config,omitempty"`
 }
 
 type Exec struct {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/keybase/go-keychain"
+	"github.com/mhristof/germ/config"
 	"github.com/mhristof/germ/iterm"
 	"github.com/mhristof/germ/log"
 )
@@ -11,6 +12,8 @@ import (
 type KeyChain struct {
 	Service     string
 	AccessGroup string
+	// Prefix of the generated profile names.
+	Prefix string
 }
 
 func (k *KeyChain) Add(name, value string) {
@@ -27,6 +30,18 @@ func (k *KeyChain) Add(name, value string) {
 
 }
 
+func (k *KeyChain) Get(name string) string {
+	value, err := keychain.GetGenericPassword(k.Service, name, name, k.AccessGroup)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"name": name,
+			"err":  err,
+		}).Fatal("Cannot retrieve the secret")
+	}
+
+	return string(value)
+}
+
 func (k *KeyChain) List() []string {
 	accounts, err := keychain.GetGenericPasswordAccounts(k.Service)
 	if err != nil {
@@ -54,15 +69,17 @@ func (k *KeyChain) Delete(name string) {
 	}
 }
 
-func (k *KeyChain) Profiles() []iterm.Profile {
+func (k *KeyChain) Profiles(cfg *config.Config) []iterm.Profile {
 
 	var ret []iterm.Profile
 	for _, account := range k.List() {
-		prof := iterm.NewProfile(fmt.Sprintf("custom/%s", account), map[string]string{})
+		name := fmt.Sprintf("%s/%s", k.Prefix, account)
+		prof := iterm.NewProfile(name, map[string]string{})
 
+		injection, _ := config.Lookup(cfg.SecretInjection, name)
 		prof.KeyboardMap["0x61-0x80000"] = iterm.KeyboardMap{
 			Action: 12,
-			Text:   fmt.Sprintf("eval $(/usr/bin/security find-generic-password  -s %s -w -a %s)", k.Service, account),
+			Text:   k.inject(injection, account),
 		}
 
 		ret = append(ret, *prof)
@@ -71,3 +88,14 @@ func (k *KeyChain) Profiles() []iterm.Profile {
 
 	return ret
 }
+
+// inject returns the text that loads the secret in the current shell. With
+// 'germ' the secret is loaded via `germ secret env`, prefixed with a space
+// so that it is not stored in the shell history.
+func (k *KeyChain) inject(injection, account string) string {
+	if injection == "germ" {
+		return fmt.Sprintf(` eval "$(germ secret env --service %s %s)"`, k.Service, account)
+	}
+
+	return fmt.Sprintf("eval $(/usr/bin/security find-generic-password  -s %s -w -a %s)", k.Service, account)
+}
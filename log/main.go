@@ -1,7 +1,9 @@
 package log
 
 import (
+	"fmt"
 	"io"
+	"os"
 
 	"github.com/sirupsen/logrus"
 )
@@ -38,4 +40,47 @@ func SetLevel(level logrus.Level) {
 
 type Fields = logrus.Fields
 
+// RegisterExitHandler runs the handler before a Fatal exits the program.
+func RegisterExitHandler(handler func()) {
+	logrus.RegisterExitHandler(handler)
+}
+
 var DebugLevel = logrus.DebugLevel
+
+// SetFormat selects the output format, either console (the default) or json.
+func SetFormat(format string) error {
+	switch format {
+	case "console", "":
+		logger.SetFormatter(&logrus.TextFormatter{})
+	case "json":
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("unknown log format %s", format)
+	}
+
+	return nil
+}
+
+// SetFile appends the logs to the given file instead of stderr.
+func SetFile(path string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	SetOutput(f)
+
+	return nil
+}
+
+// SetLevelName sets the level by name, ie debug, info, warn or error.
+func SetLevelName(name string) error {
+	level, err := logrus.ParseLevel(name)
+	if err != nil {
+		return err
+	}
+
+	SetLevel(level)
+
+	return nil
+}
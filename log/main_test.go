@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetFormat(t *testing.T) {
+	defer SetOutput(logger.Out)
+	defer logger.SetFormatter(logger.Formatter)
+
+	var out bytes.Buffer
+	SetOutput(&out)
+
+	assert.Nil(t, SetFormat("json"))
+	WithFields(Fields{"profile": "prod"}).Error("Cannot login")
+
+	var entry map[string]string
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "prod", entry["profile"])
+	assert.Equal(t, "error", entry["level"])
+
+	assert.NotNil(t, SetFormat("xml"))
+}
+
+func TestSetLevelName(t *testing.T) {
+	defer SetLevel(logger.Level)
+
+	assert.Nil(t, SetLevelName("warn"))
+	assert.Equal(t, logrus.WarnLevel, logger.Level)
+
+	assert.NotNil(t, SetLevelName("loud"))
+}
+
+func TestRegisterExitHandler(t *testing.T) {
+	defer SetOutput(logger.Out)
+	defer func(exit func(int)) { logger.ExitFunc = exit }(logger.ExitFunc)
+
+	var out bytes.Buffer
+	SetOutput(&out)
+
+	var code int
+	logger.ExitFunc = func(c int) { code = c }
+
+	var called bool
+	RegisterExitHandler(func() { called = true })
+
+	WithFields(Fields{"profile": "prod"}).Fatal("Cannot generate")
+	assert.True(t, called)
+	assert.Equal(t, 1, code)
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Timing is the duration of a step of the generation, ie a source or an AWS
+// profile.
+type Timing struct {
+	Group    string        `json:"group"`
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Registry collects the timings and the counters, ie API calls or cache hits.
+type Registry struct {
+	mu       sync.Mutex
+	Timings  []Timing       `json:"timings"`
+	Counters map[string]int `json:"counters"`
+}
+
+// Default is the registry used by the package functions.
+var Default = New()
+
+func New() *Registry {
+	return &Registry{
+		Counters: map[string]int{},
+	}
+}
+
+// Since records the time elapsed since start.
+func Since(group, name string, start time.Time) {
+	Default.Since(group, name, start)
+}
+
+// Call counts a call to the API, by name if any, ie the aws cli calls by
+// profile.
+func Call(api, name string) {
+	if name == "" {
+		Default.Add(fmt.Sprintf("%s_calls", api), 1)
+		return
+	}
+
+	Default.Add(fmt.Sprintf("%s_calls_%s", api, name), 1)
+}
+
+// CacheHit counts a hit or a miss of the named cache.
+func CacheHit(cache string, hit bool) {
+	if hit {
+		Default.Add(fmt.Sprintf("%s_cache_hits", cache), 1)
+		return
+	}
+
+	Default.Add(fmt.Sprintf("%s_cache_misses", cache), 1)
+}
+
+func (r *Registry) Since(group, name string, start time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Timings = append(r.Timings, Timing{
+		Group:    group,
+		Name:     name,
+		Duration: time.Since(start),
+	})
+}
+
+func (r *Registry) Add(counter string, value int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Counters[counter] += value
+}
+
+// HitRates returns the hit rate of every cache with counted hits or misses.
+func (r *Registry) HitRates() map[string]float64 {
+	var ret = map[string]float64{}
+
+	for counter := range r.Counters {
+		if !strings.HasSuffix(counter, "_cache_hits") && !strings.HasSuffix(counter, "_cache_misses") {
+			continue
+		}
+
+		cache := strings.TrimSuffix(strings.TrimSuffix(counter, "_cache_hits"), "_cache_misses")
+		hits := r.Counters[fmt.Sprintf("%s_cache_hits", cache)]
+		misses := r.Counters[fmt.Sprintf("%s_cache_misses", cache)]
+
+		ret[cache] = float64(hits) / float64(hits+misses)
+	}
+
+	return ret
+}
+
+// Report writes the timings, slowest first within each group, followed by the
+// counters and the cache hit rates.
+func (r *Registry) Report(out io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	timings := append([]Timing{}, r.Timings...)
+	sort.SliceStable(timings, func(i, j int) bool {
+		if timings[i].Group != timings[j].Group {
+			return timings[i].Group < timings[j].Group
+		}
+		return timings[i].Duration > timings[j].Duration
+	})
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, timing := range timings {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", timing.Group, timing.Name, timing.Duration.Round(time.Microsecond))
+	}
+
+	var counters []string
+	for counter := range r.Counters {
+		counters = append(counters, counter)
+	}
+	sort.Strings(counters)
+
+	for _, counter := range counters {
+		fmt.Fprintf(w, "counter\t%s\t%d\n", counter, r.Counters[counter])
+	}
+
+	rates := r.HitRates()
+	var caches []string
+	for cache := range rates {
+		caches = append(caches, cache)
+	}
+	sort.Strings(caches)
+
+	for _, cache := range caches {
+		fmt.Fprintf(w, "cache\t%s\t%.0f%%\n", cache, rates[cache]*100)
+	}
+
+	w.Flush()
+}
+
+// Write saves the registry as JSON.
+func (r *Registry) Write(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	r := New()
+	r.Timings = []Timing{
+		{Group: "source", Name: "k8s", Duration: time.Millisecond},
+		{Group: "source", Name: "aws-config", Duration: time.Second},
+		{Group: "aws-profile", Name: "prod", Duration: time.Second},
+	}
+	r.Add("api_calls", 2)
+	r.Add("regions_cache_hits", 3)
+	r.Add("regions_cache_misses", 1)
+
+	var out bytes.Buffer
+	r.Report(&out)
+
+	assert.Equal(t, ""+
+		"aws-profile  prod                  1s\n"+
+		"source       aws-config            1s\n"+
+		"source       k8s                   1ms\n"+
+		"counter      api_calls             2\n"+
+		"counter      regions_cache_hits    3\n"+
+		"counter      regions_cache_misses  1\n"+
+		"cache        regions               75%\n", out.String())
+}
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := New()
+	r.Since("source", "k8s", time.Now())
+	r.Add("api_calls", 1)
+
+	path := filepath.Join(dir, "timings.json")
+	assert.Nil(t, r.Write(path))
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+
+	var read Registry
+	assert.Nil(t, json.Unmarshal(data, &read))
+	assert.Equal(t, "k8s", read.Timings[0].Name)
+	assert.Equal(t, map[string]int{"api_calls": 1}, read.Counters)
+}
+
+func TestCall(t *testing.T) {
+	defer func(r *Registry) { Default = r }(Default)
+	Default = New()
+
+	Call("aws", "prod")
+	Call("aws", "prod")
+	Call("aws", "")
+
+	assert.Equal(t, map[string]int{"aws_calls_prod": 2, "aws_calls": 1}, Default.Counters)
+}
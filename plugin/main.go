@@ -0,0 +1,167 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// Prefix of the plugin executables found in the PATH.
+const Prefix = "germ-source-"
+
+// RPCPrefix is the prefix of the long running plugins, see Serve.
+const RPCPrefix = "germ-rpc-"
+
+// Output is what the plugins print, ie
+//
+//	{"profiles": [{"name": "cmdb-web01", "command": "ssh web01", "tags": ["cmdb"]}]}
+type Output struct {
+	Profiles []Spec `json:"profiles"`
+}
+
+// Spec is a profile generated by a plugin.
+type Spec struct {
+	Name    string   `json:"name"`
+	Command string   `json:"command"`
+	Tags    []string `json:"tags"`
+	// Badge defaults to the name.
+	Badge string `json:"badge"`
+	// WorkingDirectory of the profile.
+	WorkingDirectory string `json:"workingDirectory"`
+}
+
+// Plugin is an executable that prints profiles.
+type Plugin struct {
+	Name string
+	Path string
+	// Options are sent to the long running plugins.
+	Options map[string]string
+}
+
+// Discover returns the executables of the PATH starting with the prefix and
+// the configured ones. The first plugin with a name wins.
+func Discover(prefix, path string, configured []string) []Plugin {
+	var ret []Plugin
+	var seen = map[string]bool{}
+
+	add := func(file string) {
+		name := strings.TrimPrefix(filepath.Base(file), prefix)
+		if seen[name] {
+			return
+		}
+
+		info, err := os.Stat(file)
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+			return
+		}
+
+		seen[name] = true
+		ret = append(ret, Plugin{Name: name, Path: file})
+	}
+
+	for _, file := range configured {
+		expanded, err := homedir.Expand(file)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"plugin": file,
+				"err":    err,
+			}).Fatal("Cannot expand path")
+		}
+
+		add(expanded)
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		matches, _ := filepath.Glob(filepath.Join(dir, prefix+"*"))
+		sort.Strings(matches)
+
+		for _, match := range matches {
+			add(match)
+		}
+	}
+
+	return ret
+}
+
+// Profiles runs the plugin and returns its profiles. Failing plugins are
+// logged and skipped, like the built in sources.
+func (p Plugin) Profiles(ctx context.Context) []iterm.Profile {
+	out, err := exec.CommandContext(ctx, p.Path).Output()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"plugin": p.Path,
+			"err":    err,
+		}).Warn("Plugin failed")
+		return nil
+	}
+
+	profiles, err := Parse(out)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"plugin": p.Path,
+			"err":    err,
+		}).Warn("Invalid plugin output")
+		return nil
+	}
+
+	return profiles
+}
+
+// Parse converts the plugin output to profiles.
+func Parse(data []byte) ([]iterm.Profile, error) {
+	var output Output
+
+	err := json.Unmarshal(data, &output)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse profiles")
+	}
+
+	return profilesOf(output.Profiles)
+}
+
+func profilesOf(specs []Spec) ([]iterm.Profile, error) {
+	var ret []iterm.Profile
+	for i, spec := range specs {
+		if spec.Name == "" {
+			return nil, errors.Errorf("profile %d has no name", i)
+		}
+
+		config := map[string]string{}
+		if spec.Command != "" {
+			config["Command"] = spec.Command
+		}
+
+		if spec.Badge != "" {
+			config["BadgeText"] = spec.Badge
+		}
+
+		if len(spec.Tags) > 0 {
+			config["Tags"] = strings.Join(spec.Tags, ",")
+		}
+
+		profile := iterm.NewProfile(spec.Name, config)
+		if spec.WorkingDirectory != "" {
+			profile.CustomDirectory = "Yes"
+			profile.WorkingDirectory = spec.WorkingDirectory
+		}
+
+		ret = append(ret, *profile)
+	}
+
+	return ret, nil
+}
+
+// Source is the name of the generation source of the plugin.
+func (p Plugin) Source() string {
+	return fmt.Sprintf("plugin-%s", p.Name)
+}
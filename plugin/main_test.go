@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var cases = []struct {
+		name string
+		in   string
+		exp  []string
+		err  bool
+	}{
+		{
+			name: "profiles",
+			in: `{"profiles": [
+				{"name": "cmdb-web01", "command": "ssh web01", "tags": ["cmdb", "env=prod"]},
+				{"name": "cmdb-repo", "workingDirectory": "/src", "badge": "repo"}
+			]}`,
+			exp: []string{"cmdb-web01", "cmdb-repo"},
+		},
+		{
+			name: "missing name",
+			in:   `{"profiles": [{"command": "ssh web01"}]}`,
+			err:  true,
+		},
+		{
+			name: "invalid json",
+			in:   `profiles`,
+			err:  true,
+		},
+	}
+
+	for _, test := range cases {
+		profiles, err := Parse([]byte(test.in))
+		if test.err {
+			assert.NotNil(t, err, test.name)
+			continue
+		}
+
+		var names []string
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.exp, names, test.name)
+	}
+
+	profiles, err := Parse([]byte(`{"profiles": [{"name": "web", "command": "ssh web", "tags": ["cmdb"], "badge": "WEB"}]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "ssh web", profiles[0].Command)
+	assert.Equal(t, "Yes", profiles[0].CustomCommand)
+	assert.Equal(t, []string{"cmdb"}, profiles[0].Tags)
+	assert.Equal(t, "WEB", profiles[0].BadgeText)
+}
+
+func TestDiscover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	for _, d := range []string{first, second} {
+		assert.Nil(t, os.Mkdir(d, 0755))
+	}
+
+	script := []byte("#!/bin/sh\necho '{\"profiles\": [{\"name\": \"cmdb-web01\", \"command\": \"ssh web01\"}]}'\n")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(first, "germ-source-cmdb"), script, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(second, "germ-source-cmdb"), script, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(second, "germ-source-notes"), script, 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(second, "bastions"), script, 0755))
+
+	plugins := Discover(Prefix, first+string(os.PathListSeparator)+second, []string{filepath.Join(second, "bastions")})
+	assert.Equal(t, []Plugin{
+		{Name: "bastions", Path: filepath.Join(second, "bastions")},
+		{Name: "cmdb", Path: filepath.Join(first, "germ-source-cmdb")},
+	}, plugins)
+
+	assert.Equal(t, "plugin-cmdb", plugins[1].Source())
+
+	profiles := plugins[1].Profiles(context.Background())
+	assert.Equal(t, 1, len(profiles))
+	assert.Equal(t, "ssh web01", profiles[0].Command)
+
+	assert.Nil(t, Plugin{Path: filepath.Join(dir, "missing")}.Profiles(context.Background()))
+}
@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/mhristof/germ/cache"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// GenerateArgs are the arguments of the Plugin.Generate call.
+type GenerateArgs struct {
+	// CacheKey is the key returned by the previous call, if any.
+	CacheKey string
+	Options  map[string]string
+}
+
+// GenerateReply is the result of the Plugin.Generate call.
+type GenerateReply struct {
+	Profiles []Spec
+	// CacheKey identifies the profiles, ie an ETag or a last modified time.
+	CacheKey string
+	// Unchanged reuses the profiles of the previous call, when the CacheKey
+	// of the arguments is still valid.
+	Unchanged bool
+}
+
+// Generator generates the profiles of a long running plugin.
+type Generator func(args GenerateArgs) (GenerateReply, error)
+
+// service is the RPC receiver of Serve.
+type service struct {
+	generate Generator
+}
+
+func (s *service) Generate(args GenerateArgs, reply *GenerateReply) error {
+	r, err := s.generate(args)
+	if err != nil {
+		return err
+	}
+
+	*reply = r
+
+	return nil
+}
+
+// Serve answers the Plugin.Generate JSON-RPC calls of germ on stdin and
+// stdout, until germ closes stdin. Plugins report their progress with
+// Progress.
+func Serve(generate Generator) error {
+	server := rpc.NewServer()
+
+	err := server.RegisterName("Plugin", &service{generate: generate})
+	if err != nil {
+		return err
+	}
+
+	server.ServeCodec(jsonrpc.NewServerCodec(stdio{os.Stdin, os.Stdout}))
+
+	return nil
+}
+
+// Progress reports the progress of a long running plugin to germ.
+func Progress(format string, args ...interface{}) {
+	fmt.Fprintln(os.Stderr, fmt.Sprintf(format, args...))
+}
+
+// stdio is the connection of a plugin process.
+type stdio struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (s stdio) Close() error {
+	err := s.WriteCloser.Close()
+	if err != nil {
+		return err
+	}
+
+	return s.ReadCloser.Close()
+}
+
+// DiscoverRPC returns the germ-rpc-* executables of the PATH and the
+// configured ones, with their options.
+func DiscoverRPC(path string, configured []config.RPCPlugin) []Plugin {
+	var paths []string
+	var options = map[string]map[string]string{}
+
+	for _, p := range configured {
+		expanded, err := homedir.Expand(p.Path)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"plugin": p.Path,
+				"err":    err,
+			}).Fatal("Cannot expand path")
+		}
+
+		paths = append(paths, expanded)
+		options[expanded] = p.Options
+	}
+
+	plugins := Discover(RPCPrefix, path, paths)
+	for i := range plugins {
+		plugins[i].Options = options[plugins[i].Path]
+	}
+
+	return plugins
+}
+
+// Generate starts the plugin and calls Plugin.Generate. The lines the plugin
+// prints to stderr are logged as its progress.
+func (p Plugin) Generate(ctx context.Context, args GenerateArgs) (*GenerateReply, error) {
+	cmd := exec.CommandContext(ctx, p.Path)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot start plugin")
+	}
+
+	progress := make(chan struct{})
+	go func() {
+		defer close(progress)
+
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			log.WithFields(log.Fields{
+				"plugin": p.Name,
+			}).Info(scanner.Text())
+		}
+	}()
+
+	client := rpc.NewClientWithCodec(jsonrpc.NewClientCodec(stdio{stdout, stdin}))
+
+	var reply GenerateReply
+	call := client.Go("Plugin.Generate", args, &reply, nil)
+
+	select {
+	case <-call.Done:
+		err = call.Error
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	client.Close()
+	<-progress
+	cmd.Wait()
+
+	if err != nil {
+		return nil, errors.Wrap(err, "plugin call failed")
+	}
+
+	return &reply, nil
+}
+
+// rpcCache is the reply of the previous call of a plugin.
+type rpcCache struct {
+	CacheKey string `json:"cacheKey"`
+	Profiles []Spec `json:"profiles"`
+}
+
+// RPCProfiles returns the profiles of a long running plugin. The previous
+// reply is cached, and reused when the plugin replies that it is unchanged.
+func (p Plugin) RPCProfiles(timeout time.Duration) []iterm.Profile {
+	name := fmt.Sprintf("%s.json", p.Source())
+
+	var previous rpcCache
+	if data, _, err := cache.Read(name); err == nil {
+		if err := json.Unmarshal(data, &previous); err != nil {
+			previous = rpcCache{}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	reply, err := p.Generate(ctx, GenerateArgs{
+		CacheKey: previous.CacheKey,
+		Options:  p.Options,
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"plugin": p.Path,
+			"err":    err,
+		}).Warn("Plugin failed")
+		return nil
+	}
+
+	current := rpcCache{CacheKey: reply.CacheKey, Profiles: reply.Profiles}
+	if reply.Unchanged && previous.CacheKey != "" {
+		current = previous
+	} else if data, err := json.Marshal(current); err == nil {
+		err = cache.Write(name, data)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"plugin": p.Path,
+				"err":    err,
+			}).Warn("Cannot cache plugin profiles")
+		}
+	}
+
+	profiles, err := profilesOf(current.Profiles)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"plugin": p.Path,
+			"err":    err,
+		}).Warn("Invalid plugin output")
+		return nil
+	}
+
+	return profiles
+}
@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain runs the test binary as an RPC plugin when GERM_TEST_PLUGIN is
+// set.
+func TestMain(m *testing.M) {
+	if os.Getenv("GERM_TEST_PLUGIN") == "" {
+		os.Exit(m.Run())
+	}
+
+	err := Serve(func(args GenerateArgs) (GenerateReply, error) {
+		Progress("listing %s", args.Options["env"])
+
+		if args.CacheKey == "v1" {
+			return GenerateReply{CacheKey: "v1", Unchanged: true}, nil
+		}
+
+		return GenerateReply{
+			CacheKey: "v1",
+			Profiles: []Spec{{Name: fmt.Sprintf("cmdb-%s", args.Options["env"]), Command: "ssh web"}},
+		}, nil
+	})
+	if err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func testPlugin(t *testing.T, dir string) Plugin {
+	path := filepath.Join(dir, "germ-rpc-cmdb")
+	script := fmt.Sprintf("#!/bin/sh\nGERM_TEST_PLUGIN=1 exec %s\n", os.Args[0])
+	assert.Nil(t, ioutil.WriteFile(path, []byte(script), 0755))
+
+	return Plugin{Name: "cmdb", Path: path, Options: map[string]string{"env": "prod"}}
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := testPlugin(t, dir)
+
+	reply, err := p.Generate(context.Background(), GenerateArgs{Options: p.Options})
+	assert.Nil(t, err)
+	assert.Equal(t, "v1", reply.CacheKey)
+	assert.Equal(t, []Spec{{Name: "cmdb-prod", Command: "ssh web"}}, reply.Profiles)
+
+	reply, err = p.Generate(context.Background(), GenerateArgs{CacheKey: "v1"})
+	assert.Nil(t, err)
+	assert.True(t, reply.Unchanged)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	time.Sleep(time.Millisecond)
+	_, err = p.Generate(ctx, GenerateArgs{})
+	assert.NotNil(t, err)
+}
+
+func TestRPCProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("XDG_CACHE_HOME", dir)
+	defer os.Unsetenv("XDG_CACHE_HOME")
+
+	p := testPlugin(t, dir)
+
+	for _, run := range []string{"first", "cached"} {
+		profiles := p.RPCProfiles(time.Minute)
+		assert.Equal(t, 1, len(profiles), run)
+		assert.Equal(t, "cmdb-prod", profiles[0].Name, run)
+	}
+
+	plugins := DiscoverRPC("", []config.RPCPlugin{{Path: p.Path, Options: map[string]string{"env": "dev"}}})
+	assert.Equal(t, []Plugin{{Name: "cmdb", Path: p.Path, Options: map[string]string{"env": "dev"}}}, plugins)
+}
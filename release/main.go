@@ -0,0 +1,181 @@
+package release
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Latest is the GitHub API URL of the latest germ release.
+var Latest = "https://api.github.com/repos/mhristof/germ/releases/latest"
+
+var client = &http.Client{
+	Timeout: 5 * time.Minute,
+}
+
+// Release is a GitHub release.
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file uploaded to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Get returns the release from the GitHub API URL.
+func Get(url string) (*Release, error) {
+	data, err := download(url)
+	if err != nil {
+		return nil, err
+	}
+
+	var release Release
+	err = json.Unmarshal(data, &release)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot parse release")
+	}
+
+	return &release, nil
+}
+
+// Asset returns the asset with the given name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+
+	return Asset{}, false
+}
+
+// Download returns the asset, after verifying it against the sha256 of the
+// <name>.sha256 asset of the release.
+func (r *Release) Download(name string) ([]byte, error) {
+	asset, found := r.Asset(name)
+	if !found {
+		return nil, errors.Errorf("release %s has no asset %s", r.TagName, name)
+	}
+
+	checksum, found := r.Asset(fmt.Sprintf("%s.sha256", name))
+	if !found {
+		return nil, errors.Errorf("release %s has no checksum for %s", r.TagName, name)
+	}
+
+	sum, err := download(checksum.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := download(asset.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(string(sum))
+	if len(fields) == 0 || fields[0] != fmt.Sprintf("%x", sha256.Sum256(data)) {
+		return nil, errors.Errorf("checksum mismatch for %s", name)
+	}
+
+	return data, nil
+}
+
+// Newer reports whether the latest version is newer than the current one.
+// Versions are v<major>.<minor>.<patch>, with an optional +<git ref>, and
+// unknown current versions, ie devel, are always outdated.
+func Newer(current, latest string) bool {
+	c, err := parse(current)
+	if err != nil {
+		return true
+	}
+
+	l, err := parse(latest)
+	if err != nil {
+		return false
+	}
+
+	for i := range c {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+
+	return false
+}
+
+func parse(version string) ([3]int, error) {
+	var ret [3]int
+
+	// Builds from the Makefile look like v1.2.3^0+abcdef or v1.2.3~2+abcdef.
+	if i := strings.IndexAny(version, "+~^"); i >= 0 {
+		version = version[:i]
+	}
+	version = strings.TrimPrefix(version, "v")
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return ret, errors.Errorf("invalid version %s", version)
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return ret, errors.Wrapf(err, "invalid version %s", version)
+		}
+		ret[i] = n
+	}
+
+	return ret, nil
+}
+
+// Replace atomically replaces the binary at path with data.
+func Replace(path string, data []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".germ")
+	if err != nil {
+		return errors.Wrap(err, "cannot create temporary file")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "cannot write temporary file")
+	}
+
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp.Name(), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func download(url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status %s from %s", resp.Status, url)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
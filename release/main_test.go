@@ -0,0 +1,104 @@
+package release
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewer(t *testing.T) {
+	var cases = []struct {
+		current string
+		latest  string
+		exp     bool
+	}{
+		{current: "v1.2.3+abcdef", latest: "v1.2.4", exp: true},
+		{current: "v1.2.3", latest: "v1.10.0", exp: true},
+		{current: "v1.2.3", latest: "v1.2.3", exp: false},
+		{current: "v1.2.3^0+abcdef", latest: "v1.2.3", exp: false},
+		{current: "v1.2.3~2+abcdef", latest: "v1.2.4", exp: true},
+		{current: "v2.0.0", latest: "v1.9.9", exp: false},
+		{current: "devel", latest: "v0.0.1", exp: true},
+		{current: "v1.0.0", latest: "nightly", exp: false},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, Newer(test.current, test.latest), fmt.Sprintf("%s -> %s", test.current, test.latest))
+	}
+}
+
+func TestDownload(t *testing.T) {
+	binary := []byte("new germ")
+	sum := fmt.Sprintf("%x  germ.darwin\n", sha256.Sum256(binary))
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": "v1.2.0", "assets": [
+				{"name": "germ.darwin", "browser_download_url": "%[1]s/germ.darwin"},
+				{"name": "germ.darwin.sha256", "browser_download_url": "%[1]s/germ.darwin.sha256"},
+				{"name": "germ.linux", "browser_download_url": "%[1]s/germ.darwin"},
+				{"name": "germ.linux.sha256", "browser_download_url": "%[1]s/bad.sha256"}
+			]}`, server.URL)
+		case "/germ.darwin":
+			w.Write(binary)
+		case "/germ.darwin.sha256":
+			fmt.Fprint(w, sum)
+		case "/bad.sha256":
+			fmt.Fprint(w, "0000")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	release, err := Get(server.URL + "/latest")
+	assert.Nil(t, err)
+	assert.Equal(t, "v1.2.0", release.TagName)
+
+	data, err := release.Download("germ.darwin")
+	assert.Nil(t, err)
+	assert.Equal(t, binary, data)
+
+	_, err = release.Download("germ.linux")
+	assert.NotNil(t, err, "checksum mismatch")
+
+	_, err = release.Download("germ.windows")
+	assert.NotNil(t, err, "missing asset")
+
+	_, err = Get(server.URL + "/missing")
+	assert.NotNil(t, err)
+}
+
+func TestReplace(t *testing.T) {
+	dir, err := ioutil.TempDir("", "release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "germ")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("old"), 0755))
+
+	assert.Nil(t, Replace(path, []byte("new")))
+
+	data, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "new", string(data))
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(files))
+}
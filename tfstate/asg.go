@@ -0,0 +1,71 @@
+package tfstate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+)
+
+// asgTag is the tag AWS adds to the instances of an autoscaling group.
+const asgTag = "aws:autoscaling:groupName"
+
+// Executable is the germ binary the autoscaling group profiles run.
+var Executable = "germ"
+
+// groups returns the regions of the autoscaling groups of the state, from
+// the aws_autoscaling_group resources and the tags of the instances.
+func (s *State) groups() map[string]string {
+	var ret = map[string]string{}
+
+	for _, resource := range s.Resources {
+		if resource.Mode != "managed" {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			switch resource.Type {
+			case "aws_autoscaling_group":
+				ret[instance.Attributes.Name] = instance.Region()
+			case "aws_instance":
+				if group, found := instance.Attributes.Tags[asgTag]; found {
+					ret[group] = instance.Region()
+				}
+			}
+		}
+	}
+
+	return ret
+}
+
+// asgProfiles returns a profile per autoscaling group, that starts an SSM
+// session to one of its healthy instances.
+func asgProfiles(groups map[string]string, cfg config.TFState) []iterm.Profile {
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var ret []iterm.Profile
+	for _, name := range names {
+		args := []string{Executable, "ssm", "pick-instance", "--connect", "--asg", name}
+		if region := groups[name]; region != "" {
+			args = append(args, "--region", region)
+		}
+
+		env := "/usr/bin/env"
+		if cfg.Profile != "" {
+			env = fmt.Sprintf("%s AWS_PROFILE=%s", env, cfg.Profile)
+		}
+
+		ret = append(ret, *iterm.NewProfile(fmt.Sprintf("tf-asg-%s", name), map[string]string{
+			"Command": fmt.Sprintf("%s %s", env, strings.Join(args, " ")),
+			"Tags":    fmt.Sprintf("tfstate,asg=%s", name),
+		}))
+	}
+
+	return ret
+}
@@ -0,0 +1,307 @@
+package tfstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// State is the part of a Terraform (v4) state germ cares about.
+type State struct {
+	Resources []Resource        `json:"resources"`
+	Outputs   map[string]Output `json:"outputs"`
+}
+
+type Resource struct {
+	Mode      string     `json:"mode"`
+	Type      string     `json:"type"`
+	Name      string     `json:"name"`
+	Instances []Instance `json:"instances"`
+}
+
+type Instance struct {
+	IndexKey   interface{}        `json:"index_key"`
+	Attributes InstanceAttributes `json:"attributes"`
+}
+
+type InstanceAttributes struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Arn       string            `json:"arn"`
+	PrivateIP string            `json:"private_ip"`
+	Tags      map[string]string `json:"tags"`
+}
+
+type Output struct {
+	Value interface{} `json:"value"`
+}
+
+// Profiles generates the profiles of the configured Terraform states.
+func Profiles(states []config.TFState) []iterm.Profile {
+	var ret []iterm.Profile
+
+	for _, s := range states {
+		data, err := read(s)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"path": s.Path,
+				"dir":  s.Dir,
+				"err":  err,
+			}).Warn("Cannot read terraform state")
+			continue
+		}
+
+		var state State
+		err = json.Unmarshal(data, &state)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"path": s.Path,
+				"dir":  s.Dir,
+				"err":  err,
+			}).Warn("Cannot parse terraform state")
+			continue
+		}
+
+		ret = append(ret, state.Profiles(s)...)
+	}
+
+	return ret
+}
+
+// read returns the state file, or the output of `terraform state pull` for
+// states in a remote backend.
+func read(s config.TFState) ([]byte, error) {
+	if s.Path != "" {
+		path, err := homedir.Expand(s.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return ioutil.ReadFile(path)
+	}
+
+	dir, err := homedir.Expand(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return statePull(dir)
+}
+
+// statePull returns the output of `terraform state pull` in the directory.
+// Tests, Fixtures and Record replace it.
+var statePull = func(dir string) ([]byte, error) {
+	out, err := exec.Command("terraform", fmt.Sprintf("-chdir=%s", dir), "state", "pull").Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "terraform state pull failed")
+	}
+
+	return out, nil
+}
+
+// Record passes every pulled state to save, with the name of its fixture.
+func Record(save func(name string, data []byte)) {
+	next := statePull
+	statePull = func(dir string) ([]byte, error) {
+		out, err := next(dir)
+		if err == nil {
+			save(filepath.Base(dir)+".json", out)
+		}
+
+		return out, err
+	}
+}
+
+// Fixtures replaces `terraform state pull` with the JSON files of the
+// directory, named after the base name of the terraform directory, ie
+// network.json for ~/src/infra/network.
+func Fixtures(dir string) {
+	statePull = func(tfDir string) ([]byte, error) {
+		return ioutil.ReadFile(filepath.Join(dir, filepath.Base(tfDir)+".json"))
+	}
+}
+
+// Profiles returns an SSM session profile per aws_instance, or per
+// autoscaling group with GroupASGs, and an ssh profile per bastion output.
+func (s *State) Profiles(cfg config.TFState) []iterm.Profile {
+	var ret []iterm.Profile
+
+	var states map[string]string
+	if cfg.Stopped != "" {
+		states = s.states(cfg)
+	}
+
+	var pings map[string]aws.Ping
+	if cfg.Ping != "" {
+		pings = s.pings(cfg)
+	}
+	now := time.Now()
+
+	for _, resource := range s.Resources {
+		if resource.Mode != "managed" || resource.Type != "aws_instance" {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			if _, found := instance.Attributes.Tags[asgTag]; found && cfg.GroupASGs {
+				continue
+			}
+
+			tags, keep := stateTags(cfg, states[instance.Attributes.ID])
+			if !keep {
+				log.WithFields(log.Fields{
+					"instance": instance.Attributes.ID,
+					"state":    states[instance.Attributes.ID],
+				}).Debug("Skipping instance that is not running")
+				continue
+			}
+
+			if pings != nil {
+				ping, found := pings[instance.Attributes.ID]
+				if !fresh(cfg.Ping, ping, found, now) {
+					log.WithFields(log.Fields{
+						"instance": instance.Attributes.ID,
+						"status":   ping.Status,
+						"last":     ping.Last,
+					}).Debug("Skipping instance with a stale SSM agent")
+					continue
+				}
+			}
+
+			profile := instance.Profile(resource, cfg)
+			profile.Tags = append(profile.Tags, tags...)
+			ret = append(ret, *profile)
+		}
+	}
+
+	if cfg.GroupASGs {
+		ret = append(ret, asgProfiles(s.groups(), cfg)...)
+	}
+
+	var outputs []string
+	for name := range s.Outputs {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+
+	pattern := cfg.BastionOutputs
+	if pattern == "" {
+		pattern = "*bastion*"
+	}
+
+	for _, name := range outputs {
+		host, ok := s.Outputs[name].Value.(string)
+		if !ok || !config.Match(pattern, name) {
+			continue
+		}
+
+		ret = append(ret, *bastionProfile(name, host, cfg))
+	}
+
+	return ret
+}
+
+// Name returns the Name tag of the instance, or its resource address.
+func (i *Instance) Name(resource Resource) string {
+	if name, found := i.Attributes.Tags["Name"]; found && name != "" {
+		return name
+	}
+
+	if i.IndexKey != nil {
+		return fmt.Sprintf("%s.%s[%v]", resource.Type, resource.Name, i.IndexKey)
+	}
+
+	return fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+}
+
+// Region returns the region from the ARN of the instance.
+func (i *Instance) Region() string {
+	parts := strings.Split(i.Attributes.Arn, ":")
+	if len(parts) < 4 {
+		return ""
+	}
+
+	return parts[3]
+}
+
+func (i *Instance) Profile(resource Resource, cfg config.TFState) *iterm.Profile {
+	command := fmt.Sprintf("aws ssm start-session --target %s", i.Attributes.ID)
+	if region := i.Region(); region != "" {
+		command = fmt.Sprintf("%s --region %s", command, region)
+	}
+
+	if cfg.SSHOverSSM {
+		command = i.sshCommand(cfg)
+	}
+
+	env := "/usr/bin/env"
+	if cfg.Profile != "" {
+		env = fmt.Sprintf("%s AWS_PROFILE=%s", env, cfg.Profile)
+	}
+
+	tags := []string{"tfstate", fmt.Sprintf("instance=%s", i.Attributes.ID)}
+	if cfg.SSHOverSSM {
+		tags = append(tags, "ssh-over-ssm")
+	}
+	if i.Attributes.PrivateIP != "" {
+		tags = append(tags, fmt.Sprintf("ip=%s", i.Attributes.PrivateIP))
+	}
+
+	return iterm.NewProfile(fmt.Sprintf("tf-%s", i.Name(resource)), map[string]string{
+		"Command": fmt.Sprintf("%s %s", env, command),
+		"Tags":    strings.Join(tags, ","),
+	})
+}
+
+// ProxyCommand returns the ssh ProxyCommand that tunnels ssh to the
+// instance over SSM.
+func ProxyCommand(region string) string {
+	command := "aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p"
+	if region != "" {
+		command = fmt.Sprintf("%s --region %s", command, region)
+	}
+
+	return command
+}
+
+// sshCommand returns the ssh command to the instance over SSM, so scp, port
+// forwarding and agent forwarding work.
+func (i *Instance) sshCommand(cfg config.TFState) string {
+	return fmt.Sprintf(`ssh -o ProxyCommand="%s" %s@%s`, ProxyCommand(i.Region()), sshUser(cfg), i.Attributes.ID)
+}
+
+// sshUser returns the user of the ssh sessions, defaults to the current user.
+func sshUser(cfg config.TFState) string {
+	if cfg.User != "" {
+		return cfg.User
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Fatal("Cannot find current user")
+	}
+
+	return current.Username
+}
+
+func bastionProfile(name, host string, cfg config.TFState) *iterm.Profile {
+	return iterm.NewProfile(fmt.Sprintf("tf-%s", name), map[string]string{
+		"Command": fmt.Sprintf("ssh %s@%s", sshUser(cfg), host),
+		"Tags":    fmt.Sprintf("tfstate,bastion=%s", host),
+	})
+}
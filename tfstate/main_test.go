@@ -0,0 +1,217 @@
+package tfstate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var state = heredoc.Doc(`
+	{
+	  "version": 4,
+	  "outputs": {
+	    "bastion_ip": {"value": "10.0.0.1"},
+	    "vpc_id": {"value": "vpc-1"}
+	  },
+	  "resources": [
+	    {
+	      "mode": "managed",
+	      "type": "aws_instance",
+	      "name": "web",
+	      "instances": [
+	        {
+	          "index_key": 0,
+	          "attributes": {
+	            "id": "i-0123",
+	            "arn": "arn:aws:ec2:eu-west-1:123456789012:instance/i-0123",
+	            "private_ip": "10.0.1.10",
+	            "tags": {"Name": "web-0"}
+	          }
+	        },
+	        {
+	          "index_key": 1,
+	          "attributes": {
+	            "id": "i-4567",
+	            "tags": {}
+	          }
+	        }
+	      ]
+	    },
+	    {
+	      "mode": "data",
+	      "type": "aws_instance",
+	      "name": "existing",
+	      "instances": [{"attributes": {"id": "i-data"}}]
+	    }
+	  ]
+	}
+`)
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfstate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "terraform.tfstate")
+	if err := ioutil.WriteFile(path, []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles := Profiles([]config.TFState{
+		{
+			Path:    path,
+			Profile: "prod",
+			User:    "ec2-user",
+		},
+		{
+			Path: filepath.Join(dir, "missing.tfstate"),
+		},
+	})
+
+	assert.Equal(t, 3, len(profiles))
+
+	assert.Equal(t, "tf-web-0", profiles[0].GUID)
+	assert.Equal(t, "/usr/bin/env AWS_PROFILE=prod aws ssm start-session --target i-0123 --region eu-west-1", profiles[0].Command)
+	assert.Equal(t, []string{"tfstate", "instance=i-0123", "ip=10.0.1.10"}, profiles[0].Tags)
+
+	assert.Equal(t, "tf-aws_instance.web[1]", profiles[1].GUID)
+	assert.Equal(t, "/usr/bin/env AWS_PROFILE=prod aws ssm start-session --target i-4567", profiles[1].Command)
+
+	assert.Equal(t, "tf-bastion_ip", profiles[2].GUID)
+	assert.Equal(t, "ssh ec2-user@10.0.0.1", profiles[2].Command)
+}
+
+func TestProfilesStopped(t *testing.T) {
+	defer func(f func(string, string, []string) (map[string]string, error)) { instanceStates = f }(instanceStates)
+
+	instanceStates = func(profile, region string, ids []string) (map[string]string, error) {
+		return map[string]string{"i-0123": "stopped"}, nil
+	}
+
+	var s State
+	assert.Nil(t, json.Unmarshal([]byte(state), &s))
+
+	var cases = []struct {
+		stopped string
+		exp     []string
+	}{
+		{stopped: "", exp: []string{"tf-web-0", "tf-aws_instance.web[1]", "tf-bastion_ip"}},
+		{stopped: StoppedSkip, exp: []string{"tf-bastion_ip"}},
+		{stopped: StoppedTag, exp: []string{"tf-web-0", "tf-aws_instance.web[1]", "tf-bastion_ip"}},
+	}
+
+	for _, test := range cases {
+		profiles := s.Profiles(config.TFState{Stopped: test.stopped, User: "ec2-user"})
+
+		var names []string
+		for _, profile := range profiles {
+			names = append(names, profile.Name)
+		}
+		assert.Equal(t, test.exp, names, test.stopped)
+
+		if test.stopped == StoppedTag {
+			assert.True(t, profiles[0].HasTag("state=stopped"))
+			assert.True(t, profiles[1].HasTag("state=terminated"))
+		}
+	}
+}
+
+func TestFresh(t *testing.T) {
+	now := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	var cases = []struct {
+		name  string
+		rule  string
+		ping  aws.Ping
+		found bool
+		exp   bool
+	}{
+		{name: "online", rule: "online", ping: aws.Ping{Status: "Online"}, found: true, exp: true},
+		{name: "connection lost", rule: "online", ping: aws.Ping{Status: "ConnectionLost"}, found: true},
+		{name: "not registered", rule: "24h"},
+		{name: "recent ping", rule: "24h", ping: aws.Ping{Last: now.Add(-time.Hour)}, found: true, exp: true},
+		{name: "old ping", rule: "24h", ping: aws.Ping{Last: now.Add(-48 * time.Hour)}, found: true},
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.exp, fresh(test.rule, test.ping, test.found, now), test.name)
+	}
+}
+
+func TestProfilesGroupASGs(t *testing.T) {
+	var s State
+	assert.Nil(t, json.Unmarshal([]byte(heredoc.Doc(`
+		{
+		  "resources": [
+		    {
+		      "mode": "managed",
+		      "type": "aws_autoscaling_group",
+		      "name": "web",
+		      "instances": [{"attributes": {"name": "web-asg", "arn": "arn:aws:autoscaling:eu-west-1:123456789012:autoScalingGroup:1:autoScalingGroupName/web-asg"}}]
+		    },
+		    {
+		      "mode": "managed",
+		      "type": "aws_instance",
+		      "name": "worker",
+		      "instances": [
+		        {"attributes": {"id": "i-1", "tags": {"aws:autoscaling:groupName": "workers"}}},
+		        {"attributes": {"id": "i-2", "tags": {"aws:autoscaling:groupName": "workers"}}},
+		        {"attributes": {"id": "i-3", "tags": {"Name": "standalone"}}}
+		      ]
+		    }
+		  ]
+		}
+	`)), &s))
+
+	assert.Equal(t, 3, len(s.Profiles(config.TFState{})))
+
+	profiles := s.Profiles(config.TFState{GroupASGs: true, Profile: "prod"})
+
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	assert.Equal(t, []string{"tf-standalone", "tf-asg-web-asg", "tf-asg-workers"}, names)
+	assert.Equal(t, "/usr/bin/env AWS_PROFILE=prod germ ssm pick-instance --connect --asg web-asg --region eu-west-1", profiles[1].Command)
+}
+
+func TestProfilesSSHOverSSM(t *testing.T) {
+	var s State
+	assert.Nil(t, json.Unmarshal([]byte(state), &s))
+
+	profiles := s.Profiles(config.TFState{SSHOverSSM: true, Profile: "prod", User: "ec2-user"})
+
+	assert.Equal(t, `/usr/bin/env AWS_PROFILE=prod ssh -o ProxyCommand="aws ssm start-session --target %h --document-name AWS-StartSSHSession --parameters portNumber=%p --region eu-west-1" ec2-user@i-0123`, profiles[0].Command)
+	assert.True(t, profiles[0].HasTag("ssh-over-ssm"))
+}
+
+func TestFixtures(t *testing.T) {
+	defer func(pull func(string) ([]byte, error)) { statePull = pull }(statePull)
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "network.json"), []byte(state), 0644))
+
+	Fixtures(dir)
+
+	out, err := read(config.TFState{Dir: "/src/infra/network"})
+	assert.Nil(t, err)
+	assert.Equal(t, state, string(out))
+
+	_, err = read(config.TFState{Dir: "/src/infra/missing"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,140 @@
+package tfstate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mhristof/germ/aws"
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/log"
+)
+
+const (
+	// StoppedSkip drops the instances that are not running.
+	StoppedSkip = "skip"
+	// StoppedTag keeps the instances that are not running, tagged with
+	// their state.
+	StoppedTag = "tag"
+)
+
+// instanceStates returns the EC2 states of the instances. Tests replace it.
+var instanceStates = aws.InstanceStates
+
+// regions returns the ids of the aws_instance resources by region, and the
+// sorted regions.
+func (s *State) regions() (map[string][]string, []string) {
+	var regions = map[string][]string{}
+
+	for _, resource := range s.Resources {
+		if resource.Mode != "managed" || resource.Type != "aws_instance" {
+			continue
+		}
+
+		for _, instance := range resource.Instances {
+			region := instance.Region()
+			regions[region] = append(regions[region], instance.Attributes.ID)
+		}
+	}
+
+	var names []string
+	for region := range regions {
+		names = append(names, region)
+	}
+	sort.Strings(names)
+
+	return regions, names
+}
+
+// states returns the EC2 state of the aws_instance resources, terminated for
+// the ones that no longer exist, or nil if the states cannot be read.
+func (s *State) states(cfg config.TFState) map[string]string {
+	regions, names := s.regions()
+
+	var ret = map[string]string{}
+	for _, region := range names {
+		states, err := instanceStates(cfg.Profile, region, regions[region])
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile": cfg.Profile,
+				"region":  region,
+				"err":     err,
+			}).Warn("Cannot read the instance states")
+			return nil
+		}
+
+		for _, id := range regions[region] {
+			state, found := states[id]
+			if !found {
+				state = "terminated"
+			}
+			ret[id] = state
+		}
+	}
+
+	return ret
+}
+
+// stateTags returns the tags of an instance in the state, and false if the
+// instance should be skipped.
+func stateTags(cfg config.TFState, state string) ([]string, bool) {
+	if state == "" || state == "running" || state == "pending" {
+		return nil, true
+	}
+
+	if cfg.Stopped == StoppedTag {
+		return []string{"stopped", fmt.Sprintf("state=%s", state)}, true
+	}
+
+	return nil, false
+}
+
+// pingStatuses returns the SSM agent status of the aws_instance resources,
+// or nil if it cannot be read. Tests replace it.
+var pingStatuses = aws.PingStatuses
+
+func (s *State) pings(cfg config.TFState) map[string]aws.Ping {
+	regions, names := s.regions()
+
+	var ret = map[string]aws.Ping{}
+	for _, region := range names {
+		pings, err := pingStatuses(cfg.Profile, region, regions[region])
+		if err != nil {
+			log.WithFields(log.Fields{
+				"profile": cfg.Profile,
+				"region":  region,
+				"err":     err,
+			}).Warn("Cannot read the SSM ping statuses")
+			return nil
+		}
+
+		for id, ping := range pings {
+			ret[id] = ping
+		}
+	}
+
+	return ret
+}
+
+// fresh reports whether the SSM agent of the instance is online, for the
+// online rule, or pinged within the duration of the rule.
+func fresh(rule string, ping aws.Ping, found bool, now time.Time) bool {
+	if !found {
+		return false
+	}
+
+	if rule == "online" {
+		return ping.Status == "Online"
+	}
+
+	age, err := time.ParseDuration(rule)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"ping": rule,
+			"err":  err,
+		}).Warn("Invalid ping rule, use online or a duration")
+		return true
+	}
+
+	return now.Sub(ping.Last) <= age
+}
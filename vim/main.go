@@ -0,0 +1,94 @@
+package vim
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mhristof/germ/config"
+	"github.com/mhristof/germ/iterm"
+	"github.com/mhristof/germ/log"
+	"github.com/mitchellh/go-homedir"
+)
+
+// Profiles generates a profile per project found in the configured
+// directories, that starts the editor in the project.
+func Profiles(projects *config.Projects) []iterm.Profile {
+	var ret []iterm.Profile
+
+	if projects == nil {
+		return ret
+	}
+
+	editor := projects.Editor
+	if editor == "" {
+		editor = "vim"
+	}
+
+	session := projects.Session
+	if session == "" {
+		session = "Session.vim"
+	}
+
+	for _, dir := range projects.Dirs {
+		for _, project := range Projects(dir) {
+			ret = append(ret, *Profile(project, editor, session))
+		}
+	}
+
+	return ret
+}
+
+// Projects returns the directories inside dir, skipping the hidden ones.
+func Projects(dir string) []string {
+	var ret []string
+
+	dir, err := homedir.Expand(dir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"dir": dir,
+			"err": err,
+		}).Fatal("Cannot expand path")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"dir": dir,
+			"err": err,
+		}).Warn("Cannot read projects directory")
+		return ret
+	}
+
+	for _, file := range files {
+		if !file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		ret = append(ret, filepath.Join(dir, file.Name()))
+	}
+
+	return ret
+}
+
+// Profile creates the editor profile of the project.
+func Profile(project, editor, session string) *iterm.Profile {
+	name := filepath.Base(project)
+
+	command := fmt.Sprintf("%s .", editor)
+	if _, err := os.Stat(filepath.Join(project, session)); err == nil {
+		command = fmt.Sprintf("%s -S %s", editor, session)
+	}
+
+	profile := iterm.NewProfile(fmt.Sprintf("vim-%s", name), map[string]string{
+		"Command": fmt.Sprintf("/bin/bash -lc '%s'", command),
+		"Tags":    fmt.Sprintf("vim,project=%s", name),
+	})
+	profile.CustomDirectory = "Yes"
+	profile.WorkingDirectory = project
+	profile.CustomWindowTitle = name
+
+	return profile
+}
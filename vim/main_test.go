@@ -0,0 +1,44 @@
+package vim
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mhristof/germ/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, project := range []string{"germ", "other", ".hidden"} {
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, project), 0755))
+	}
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "file"), []byte{}, 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "germ", "Session.vim"), []byte{}, 0644))
+
+	profiles := Profiles(&config.Projects{
+		Dirs:   []string{dir},
+		Editor: "nvim",
+	})
+
+	assert.Equal(t, 2, len(profiles))
+
+	assert.Equal(t, "vim-germ", profiles[0].Name)
+	assert.Equal(t, "/bin/bash -lc 'nvim -S Session.vim'", profiles[0].Command)
+	assert.Equal(t, "Yes", profiles[0].CustomDirectory)
+	assert.Equal(t, filepath.Join(dir, "germ"), profiles[0].WorkingDirectory)
+	assert.Equal(t, "germ", profiles[0].CustomWindowTitle)
+	assert.Equal(t, []string{"vim", "project=germ"}, profiles[0].Tags)
+
+	assert.Equal(t, "vim-other", profiles[1].Name)
+	assert.Equal(t, "/bin/bash -lc 'nvim .'", profiles[1].Command)
+
+	assert.Nil(t, Profiles(nil))
+}